@@ -0,0 +1,16 @@
+package achievements
+
+import (
+	"berry_bet/internal/achievements"
+	"berry_bet/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAchievementRoutes(router *gin.Engine) {
+	me := router.Group("/api/users/me/achievements")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("", achievements.GetMyAchievementsHandler)
+	}
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"berry_bet/internal/achievements"
+	"berry_bet/internal/admin"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/auth"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/kyc"
+	"berry_bet/internal/referrals"
+	"berry_bet/internal/reports"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/risk"
+	"berry_bet/internal/withdrawals"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAdminRoutes(router *gin.Engine) {
+	group := router.Group("/api/admin")
+	group.Use(auth.JWTAuthMiddleware(), auth.RequireRole("admin"))
+	{
+		group.GET("/users", admin.SearchUsersHandler)
+		group.GET("/users/:id", admin.GetUserProfileHandler)
+		group.POST("/users/:id/balance_adjustments", admin.AdjustBalanceHandler)
+		group.POST("/users/:id/freeze", admin.FreezeUserHandler)
+		group.POST("/users/:id/unfreeze", admin.UnfreezeUserHandler)
+		group.POST("/users/:id/unlock", admin.UnlockUserHandler)
+		group.GET("/bet_limits", admin.GetBetLimitsHandler)
+		group.PUT("/bet_limits", admin.UpdateBetLimitsHandler)
+		group.POST("/games/:id/start", admin.StartGameHandler)
+		group.POST("/games/:id/end", admin.EndGameHandler)
+		group.GET("/audit_log", audit.ListAuditLogHandler)
+		group.GET("/audit_log/verify", audit.VerifyAuditLogHandler)
+		group.GET("/self_exclusions", responsible_gaming.ListExclusionsHandler)
+		group.GET("/risk/flagged", risk.ListFlaggedPlayersHandler)
+		group.GET("/risk/users/:id", risk.GetPlayerRiskHandler)
+		group.POST("/risk/users/:id/score", risk.RescorePlayerHandler)
+		group.POST("/risk/reviews/:id/resolve", risk.ResolveReviewHandler)
+		group.GET("/kyc/submissions", kyc.ListSubmissionsHandler)
+		group.GET("/kyc/submissions/:id", kyc.GetSubmissionHandler)
+		group.POST("/kyc/submissions/:id/approve", kyc.ApproveSubmissionHandler)
+		group.POST("/kyc/submissions/:id/reject", kyc.RejectSubmissionHandler)
+		group.GET("/kyc/documents/:id", kyc.GetDocumentFileHandler)
+		group.GET("/withdrawals", withdrawals.ListWithdrawalsHandler)
+		group.POST("/withdrawals/:id/approve", withdrawals.ApproveWithdrawalHandler)
+		group.POST("/withdrawals/:id/reject", withdrawals.RejectWithdrawalHandler)
+		group.GET("/bonus/campaigns", bonus.ListCampaignsHandler)
+		group.POST("/bonus/campaigns", bonus.SaveCampaignHandler)
+		group.PUT("/bonus/campaigns/:id", bonus.SaveCampaignHandler)
+		group.GET("/bonus/game_weights", bonus.GetGameWeightsHandler)
+		group.PUT("/bonus/game_weights/:game", bonus.SetGameWeightHandler)
+		group.GET("/referrals", referrals.ListReferralsHandler)
+		group.GET("/achievements", achievements.ListAchievementsHandler)
+		group.POST("/achievements", achievements.SaveAchievementHandler)
+		group.PUT("/achievements/:id", achievements.SaveAchievementHandler)
+		group.GET("/reports/house", reports.GetHouseReportHandler)
+		group.POST("/reports/house/rebuild", reports.RebuildReportsHandler)
+		group.GET("/reports/theoretical_rtp", reports.GetTheoreticalRTPsHandler)
+		group.PUT("/reports/theoretical_rtp/:game", reports.SetTheoreticalRTPHandler)
+	}
+}
@@ -2,11 +2,22 @@ package auth
 
 import (
 	"berry_bet/internal/auth"
+	"berry_bet/internal/ratelimit"
+	"berry_bet/internal/token"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterAuthRoutes(router *gin.Engine) {
-	router.POST("/login", auth.LoginHandler)
-	router.POST("/register", auth.RegisterHandler)
+	router.POST("/login", ratelimit.Middleware("login", ratelimit.ByIP), auth.LoginHandler)
+	router.POST("/login/2fa", ratelimit.Middleware("login", ratelimit.ByIP), auth.LoginTwoFactorHandler)
+	router.POST("/register", ratelimit.Middleware("register", ratelimit.ByIP), auth.RegisterHandler)
+	router.POST("/token/refresh", ratelimit.Middleware("refresh", ratelimit.ByIP), auth.RefreshTokenHandler)
+	router.POST("/logout", auth.LogoutHandler)
+	router.GET("/.well-known/jwks.json", token.JWKSHandler)
+	router.POST("/logout/all", auth.JWTAuthMiddleware(), auth.LogoutAllHandler)
+	router.POST("/email/verification/request", auth.JWTAuthMiddleware(), auth.RequestEmailVerificationHandler)
+	router.POST("/email/verification/confirm", auth.ConfirmEmailVerificationHandler)
+	router.POST("/password/reset/request", ratelimit.Middleware("password_reset", ratelimit.ByIP), auth.RequestPasswordResetHandler)
+	router.POST("/password/reset/confirm", auth.ConfirmPasswordResetHandler)
 }
@@ -11,10 +11,10 @@ func RegisterBetRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware())
 	{
-		v1.GET("/bets", bets.GetBetsHandler)
-		v1.GET("/bets/:id", bets.GetBetByIDHandler)
-		v1.POST("/bets", bets.AddBetHandler)
-		v1.PUT("/bets/:id", bets.UpdateBetHandler)
-		v1.DELETE("/bets/:id", bets.DeleteBetHandler)
+		v1.GET("/bets", auth.RequirePermission("bets.read"), bets.GetBetsHandler)
+		v1.GET("/bets/:id", auth.RequirePermission("bets.read"), bets.GetBetByIDHandler)
+		v1.POST("/bets", auth.RequirePermission("bets.write"), bets.AddBetHandler)
+		v1.PUT("/bets/:id", auth.RequirePermission("bets.write"), bets.UpdateBetHandler)
+		v1.DELETE("/bets/:id", auth.RequirePermission("bets.write"), bets.DeleteBetHandler)
 	}
 }
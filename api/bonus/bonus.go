@@ -0,0 +1,18 @@
+package bonus
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/bonus"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterBonusRoutes(router *gin.Engine) {
+	group := router.Group("/api/bonuses")
+	group.Use(auth.JWTAuthMiddleware())
+	{
+		group.GET("/me", bonus.GetMyBonusesHandler)
+		group.GET("/campaigns", bonus.GetCampaignsHandler)
+		group.POST("/claim", bonus.ClaimBonusHandler)
+	}
+}
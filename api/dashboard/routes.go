@@ -24,7 +24,9 @@ func RegisterDashboardRoutes(router *gin.Engine, db *sql.DB) {
 	api.GET("/weekly", handler.GetWeeklyData)
 	api.GET("/activity", handler.GetRecentActivity)
 	api.GET("/games", handler.GetGameStats)
+	api.GET("/analytics", handler.GetAnalytics)
 
-	// Rota para registrar apostas
-	api.POST("/record-bet", handler.RecordBet)
+	// As apostas são registradas pelo servidor na liquidação de cada jogo;
+	// o lançamento manual fica restrito a administradores
+	api.POST("/record-bet", auth.RequireRole("admin"), handler.RecordBet)
 }
@@ -4,6 +4,7 @@ import (
 	"berry_bet/internal/auth"
 	"berry_bet/internal/games"
 	"berry_bet/internal/games/roleta"
+	"berry_bet/internal/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,12 +13,12 @@ func RegisterGameRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware())
 	{
-		v1.GET("/games", games.GetGamesHandler)
-		v1.GET("/games/:id", games.GetGameByIDHandler)
-		v1.POST("/games", games.AddGameHandler)
-		v1.PUT("/games/:id", games.UpdateGameHandler)
-		v1.DELETE("/games/:id", games.DeleteGameHandler)
+		v1.GET("/games", auth.RequirePermission("games.read"), games.GetGamesHandler)
+		v1.GET("/games/:id", auth.RequirePermission("games.read"), games.GetGameByIDHandler)
+		v1.POST("/games", auth.RequirePermission("games.write"), games.AddGameHandler)
+		v1.PUT("/games/:id", auth.RequirePermission("games.write"), games.UpdateGameHandler)
+		v1.DELETE("/games/:id", auth.RequirePermission("games.write"), games.DeleteGameHandler)
 		// Adiciona rota da roleta
-		v1.POST("/roleta/bet", roleta.RoletaBetHandler)
+		v1.POST("/roleta/bet", ratelimit.Middleware("bet", ratelimit.ByUser), roleta.RoletaBetHandler)
 	}
 }
@@ -0,0 +1,18 @@
+package kyc
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/kyc"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterKYCRoutes(router *gin.Engine) {
+	me := router.Group("/api/users/me/kyc")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("", kyc.GetMyKYCHandler)
+		me.POST("/documents", kyc.UploadDocumentHandler)
+		me.POST("/submit", kyc.SubmitKYCHandler)
+	}
+}
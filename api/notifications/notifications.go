@@ -0,0 +1,17 @@
+package notifications
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/notifications"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterNotificationRoutes(router *gin.Engine) {
+	me := router.Group("/api/notifications")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("/me", notifications.GetMeNotificationsHandler)
+		me.POST("/me/:id/read", notifications.MarkNotificationReadHandler)
+	}
+}
@@ -11,10 +11,10 @@ func RegisterOutcomeRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware())
 	{
-		v1.GET("/outcomes", outcomes.GetOutcomesHandler)
-		v1.GET("/outcomes/:id", outcomes.GetOutcomeByIDHandler)
-		v1.POST("/outcomes", outcomes.AddOutcomeHandler)
-		v1.PUT("/outcomes/:id", outcomes.UpdateOutcomeHandler)
-		v1.DELETE("/outcomes/:id", outcomes.DeleteOutcomeHandler)
+		v1.GET("/outcomes", auth.RequirePermission("outcomes.read"), outcomes.GetOutcomesHandler)
+		v1.GET("/outcomes/:id", auth.RequirePermission("outcomes.read"), outcomes.GetOutcomeByIDHandler)
+		v1.POST("/outcomes", auth.RequirePermission("outcomes.write"), outcomes.AddOutcomeHandler)
+		v1.PUT("/outcomes/:id", auth.RequirePermission("outcomes.write"), outcomes.UpdateOutcomeHandler)
+		v1.DELETE("/outcomes/:id", auth.RequirePermission("outcomes.write"), outcomes.DeleteOutcomeHandler)
 	}
 }
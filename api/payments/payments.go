@@ -0,0 +1,22 @@
+package payments
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/payments"
+	"berry_bet/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPaymentRoutes(router *gin.Engine) {
+	router.POST("/api/payments/webhooks/:provider", payments.WebhookHandler)
+
+	me := router.Group("/api/payments/intents")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.POST("", ratelimit.Middleware("payment_intent", ratelimit.ByUser), payments.CreateIntentHandler)
+		me.GET("", payments.GetMyIntentsHandler)
+		me.GET("/:id", payments.GetMyIntentHandler)
+		me.POST("/:id/simulate", payments.SimulateIntentHandler)
+	}
+}
@@ -1,9 +1,12 @@
 package ranking
 
 import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/ranking"
+
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRankingRoutes(router *gin.Engine) {
-	router.GET("/api/ranking", GetRankingHandler)
+	router.GET("/api/ranking", auth.OptionalJWTAuthMiddleware(), ranking.GetRankingHandler)
 }
@@ -0,0 +1,16 @@
+package referrals
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/referrals"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReferralRoutes(router *gin.Engine) {
+	me := router.Group("/api/users/me/referrals")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("", referrals.GetMyReferralsHandler)
+	}
+}
@@ -0,0 +1,31 @@
+package responsible_gaming
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/responsible_gaming"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterResponsibleGamingRoutes(router *gin.Engine) {
+	me := router.Group("/api/users/me/limits")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("", responsible_gaming.GetMyLimitsHandler)
+		me.PUT("", responsible_gaming.SetMyLimitHandler)
+	}
+	exclusion := router.Group("/api/users/me/exclusion")
+	exclusion.Use(auth.JWTAuthMiddleware())
+	{
+		exclusion.GET("", responsible_gaming.GetMyExclusionHandler)
+		exclusion.POST("", responsible_gaming.ExcludeMeHandler)
+	}
+	me = router.Group("/api/users/me")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("/reality_check", responsible_gaming.GetRealityCheckHandler)
+		me.PUT("/reality_check", responsible_gaming.SetRealityCheckHandler)
+		me.POST("/reality_check/ack", responsible_gaming.AcknowledgeRealityCheckHandler)
+		me.GET("/play_sessions", responsible_gaming.GetMyPlaySessionsHandler)
+	}
+}
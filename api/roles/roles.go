@@ -0,0 +1,19 @@
+package roles
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/roles"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoleRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	v1.Use(auth.JWTAuthMiddleware(), auth.RequirePermission("roles.manage"))
+	{
+		v1.GET("/roles", roles.GetRolesHandler)
+		v1.GET("/users/:id/roles", roles.GetUserRolesHandler)
+		v1.POST("/users/:id/roles", roles.AssignRoleHandler)
+		v1.DELETE("/users/:id/roles/:role", roles.RemoveRoleHandler)
+	}
+}
@@ -1,16 +1,28 @@
 package api
 
 import (
+	"berry_bet/api/achievements"
+	"berry_bet/api/admin"
 	"berry_bet/api/auth"
 	"berry_bet/api/bets"
+	"berry_bet/api/bonus"
+	"berry_bet/api/dashboard"
 	"berry_bet/api/games"
+	"berry_bet/api/kyc"
+	"berry_bet/api/notifications"
 	"berry_bet/api/outcomes"
+	"berry_bet/api/payments"
 	"berry_bet/api/ranking"
+	"berry_bet/api/referrals"
+	"berry_bet/api/responsible_gaming"
+	"berry_bet/api/roles"
 	"berry_bet/api/sessions"
+	"berry_bet/api/tournaments"
 	"berry_bet/api/transactions"
+	"berry_bet/api/twofactor"
 	"berry_bet/api/user_stats"
 	"berry_bet/api/users"
-	
+	"berry_bet/config"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,4 +38,16 @@ func RegisterRoutes(router *gin.Engine) {
 	outcomes.RegisterOutcomeRoutes(router)
 	ranking.RegisterRankingRoutes(router)
 	games.RegisterRoletaRoutes(router)
+	tournaments.RegisterTournamentRoutes(router)
+	roles.RegisterRoleRoutes(router)
+	admin.RegisterAdminRoutes(router)
+	notifications.RegisterNotificationRoutes(router)
+	twofactor.RegisterTwoFactorRoutes(router)
+	responsible_gaming.RegisterResponsibleGamingRoutes(router)
+	kyc.RegisterKYCRoutes(router)
+	payments.RegisterPaymentRoutes(router)
+	bonus.RegisterBonusRoutes(router)
+	referrals.RegisterReferralRoutes(router)
+	achievements.RegisterAchievementRoutes(router)
+	dashboard.RegisterDashboardRoutes(router, config.DB)
 }
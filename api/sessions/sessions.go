@@ -11,11 +11,11 @@ func RegisterSessionRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware()) 
 	{
-		v1.GET("/sessions", sessions.GetSessionsHandler)
-		v1.GET("/sessions/:id", sessions.GetSessionByIDHandler)
-		v1.POST("/sessions", sessions.AddSessionHandler)
-		v1.PUT("/sessions/:id", sessions.UpdateSessionHandler)
-		v1.DELETE("/sessions/:id", sessions.DeleteSessionHandler)
+		v1.GET("/sessions", auth.RequirePermission("sessions.read"), sessions.GetSessionsHandler)
+		v1.GET("/sessions/:id", auth.RequirePermission("sessions.read"), sessions.GetSessionByIDHandler)
+		v1.POST("/sessions", auth.RequirePermission("sessions.write"), sessions.AddSessionHandler)
+		v1.PUT("/sessions/:id", auth.RequirePermission("sessions.write"), sessions.UpdateSessionHandler)
+		v1.DELETE("/sessions/:id", auth.RequirePermission("sessions.write"), sessions.DeleteSessionHandler)
 		v1.OPTIONS("/sessions", sessions.OptionsHandler)
 	}
 }
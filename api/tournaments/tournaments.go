@@ -0,0 +1,29 @@
+package tournaments
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/tournaments"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTournamentRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	v1.Use(auth.JWTAuthMiddleware())
+	{
+		v1.GET("/tournaments", auth.RequirePermission("tournaments.manage"), tournaments.GetTournamentsHandler)
+		v1.GET("/tournaments/:id", auth.RequirePermission("tournaments.manage"), tournaments.GetTournamentByIDHandler)
+		v1.POST("/tournaments", auth.RequirePermission("tournaments.manage"), tournaments.AddTournamentHandler)
+		v1.PUT("/tournaments/:id", auth.RequirePermission("tournaments.manage"), tournaments.UpdateTournamentHandler)
+		v1.DELETE("/tournaments/:id", auth.RequirePermission("tournaments.manage"), tournaments.DeleteTournamentHandler)
+	}
+
+	me := router.Group("/api/tournaments")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("", tournaments.GetOpenTournamentsHandler)
+		me.GET("/:id", tournaments.GetTournamentByIDHandler)
+		me.GET("/:id/standings", tournaments.GetStandingsHandler)
+		me.POST("/:id/join", tournaments.JoinTournamentHandler)
+	}
+}
@@ -2,7 +2,10 @@ package transactions
 
 import (
 	"berry_bet/internal/auth"
+	"berry_bet/internal/kyc"
 	"berry_bet/internal/transactions"
+	"berry_bet/internal/twofactor"
+	"berry_bet/internal/withdrawals"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,11 +14,11 @@ func RegisterTransactionRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware())
 	{
-		v1.GET("/transactions", transactions.GetTransactionsHandler)
-		v1.GET("/transactions/:id", transactions.GetTransactionByIDHandler)
-		v1.POST("/transactions", transactions.AddTransactionHandler)
-		v1.PUT("/transactions/:id", transactions.UpdateTransactionHandler)
-		v1.DELETE("/transactions/:id", transactions.DeleteTransactionHandler)
+		v1.GET("/transactions", auth.RequirePermission("transactions.read"), transactions.GetTransactionsHandler)
+		v1.GET("/transactions/:id", auth.RequirePermission("transactions.read"), transactions.GetTransactionByIDHandler)
+		v1.POST("/transactions", auth.RequirePermission("transactions.write"), transactions.AddTransactionHandler)
+		v1.PUT("/transactions/:id", auth.RequirePermission("transactions.write"), transactions.UpdateTransactionHandler)
+		v1.DELETE("/transactions/:id", auth.RequirePermission("transactions.write"), transactions.DeleteTransactionHandler)
 		v1.OPTIONS("/transactions", transactions.OptionsHandler)
 	}
 
@@ -24,5 +27,8 @@ func RegisterTransactionRoutes(router *gin.Engine) {
 	userRoutes.Use(auth.JWTAuthMiddleware())
 	{
 		userRoutes.GET("/me", transactions.GetMeTransactionsHandler)
+		userRoutes.POST("/me/withdraw", auth.RequireVerifiedEmail(), kyc.RequireVerified(), twofactor.RequireFreshCode(), withdrawals.RequestWithdrawalHandler)
+		userRoutes.GET("/me/withdrawals", withdrawals.GetMyWithdrawalsHandler)
+		userRoutes.GET("/me/withdrawals/:id", withdrawals.GetMyWithdrawalHandler)
 	}
 }
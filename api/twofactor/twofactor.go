@@ -0,0 +1,20 @@
+package twofactor
+
+import (
+	"berry_bet/internal/auth"
+	"berry_bet/internal/twofactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTwoFactorRoutes(router *gin.Engine) {
+	me := router.Group("/api/users/me/2fa")
+	me.Use(auth.JWTAuthMiddleware())
+	{
+		me.GET("", twofactor.GetStatusHandler)
+		me.POST("/enroll", twofactor.EnrollHandler)
+		me.POST("/confirm", twofactor.ConfirmEnrollmentHandler)
+		me.POST("/disable", twofactor.DisableHandler)
+		me.POST("/recovery_codes", twofactor.RegenerateRecoveryCodesHandler)
+	}
+}
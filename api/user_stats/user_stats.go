@@ -11,12 +11,12 @@ func RegisterUserStatsRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware())
 	{
-		v1.GET("/user_stats", user_stats.GetUserStatsHandler)
-		v1.GET("/user_stats/:id", user_stats.GetUserStatsByIDHandler)
-		v1.GET("/user_stats/:id/balance", user_stats.GetUserBalanceHandler)
-		v1.POST("/user_stats", user_stats.AddUserStatsHandler)
-		v1.PUT("/user_stats/:id", user_stats.UpdateUserStatsHandler)
-		v1.DELETE("/user_stats/:id", user_stats.DeleteUserStatsHandler)
+		v1.GET("/user_stats", auth.RequirePermission("user_stats.read"), user_stats.GetUserStatsHandler)
+		v1.GET("/user_stats/:id", auth.RequirePermission("user_stats.read"), user_stats.GetUserStatsByIDHandler)
+		v1.GET("/user_stats/:id/balance", auth.RequirePermission("user_stats.read"), user_stats.GetUserBalanceHandler)
+		v1.POST("/user_stats", auth.RequirePermission("user_stats.write"), user_stats.AddUserStatsHandler)
+		v1.PUT("/user_stats/:id", auth.RequirePermission("user_stats.write"), user_stats.UpdateUserStatsHandler)
+		v1.DELETE("/user_stats/:id", auth.RequirePermission("user_stats.write"), user_stats.DeleteUserStatsHandler)
 	}
 
 	me := router.Group("/api/user_stats")
@@ -2,6 +2,7 @@ package users
 
 import (
 	"berry_bet/internal/auth"
+	"berry_bet/internal/twofactor"
 	"berry_bet/internal/users"
 
 	"github.com/gin-gonic/gin"
@@ -11,12 +12,12 @@ func RegisterUserRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	v1.Use(auth.JWTAuthMiddleware())
 	{
-		v1.GET("/users", users.GetUsersHandler)
-		v1.GET("/users/:id", users.GetUserByIDHandler)
-		v1.POST("/users", users.AddUserHandler)
-		v1.PUT("/users/:id", users.UpdateUserHandler)
-		v1.DELETE("/users/:id", users.DeleteUserHandler)
-		v1.GET("/users/:id/balance", users.GetUserBalanceHandler)
+		v1.GET("/users", auth.RequirePermission("users.read"), users.GetUsersHandler)
+		v1.GET("/users/:id", auth.RequirePermission("users.read"), users.GetUserByIDHandler)
+		v1.POST("/users", auth.RequirePermission("users.write"), users.AddUserHandler)
+		v1.PUT("/users/:id", auth.RequirePermission("users.write"), users.UpdateUserHandler)
+		v1.DELETE("/users/:id", auth.RequirePermission("users.write"), users.DeleteUserHandler)
+		v1.GET("/users/:id/balance", auth.RequirePermission("users.read"), users.GetUserBalanceHandler)
 	}
 
 	me := router.Group("/api/users")
@@ -26,6 +27,6 @@ func RegisterUserRoutes(router *gin.Engine) {
 		me.PUT("/me", users.UpdateMeHandler)
 		me.GET("/me/balance", users.GetMeBalanceHandler)
 		me.POST("/avatar", users.UploadAvatarHandler)
-		me.POST("/change_password", users.ChangePasswordHandler)
+		me.POST("/change_password", twofactor.RequireFreshCode(), users.ChangePasswordHandler)
 	}
 }
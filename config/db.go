@@ -2,14 +2,55 @@ package config
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 var DB *sql.DB
 
+// migrations lista os arquivos de migração na ordem em que são aplicados.
+var migrations = []string{
+	"./migrations/001_create_users.sql",
+	"./migrations/002_create_games.sql",
+	"./migrations/003_create_bets.sql",
+	"./migrations/004_create_transactions.sql",
+	"./migrations/005_create_outcomes.sql",
+	"./migrations/006_create_sessions.sql",
+	"./migrations/007_create_user_stats.sql",
+	"./migrations/008_create_bet_limits.sql",
+	"./migrations/009_create_bet_history.sql",
+	"./migrations/010_create_tournaments.sql",
+	"./migrations/011_add_session_rotation.sql",
+	"./migrations/012_create_roles.sql",
+	"./migrations/013_create_admin.sql",
+	"./migrations/014_audit_log_chain.sql",
+	"./migrations/015_create_login_attempts.sql",
+	"./migrations/016_create_notifications.sql",
+	"./migrations/017_create_rate_limits.sql",
+	"./migrations/018_create_two_factor.sql",
+	"./migrations/019_email_verification.sql",
+	"./migrations/020_create_player_limits.sql",
+	"./migrations/021_create_self_exclusions.sql",
+	"./migrations/022_create_play_sessions.sql",
+	"./migrations/023_create_risk_scores.sql",
+	"./migrations/024_create_kyc.sql",
+	"./migrations/025_create_payment_intents.sql",
+	"./migrations/026_create_withdrawals.sql",
+	"./migrations/027_create_bonuses.sql",
+	"./migrations/028_create_referrals.sql",
+	"./migrations/029_create_achievements.sql",
+	"./migrations/030_add_user_timezone.sql",
+	"./migrations/031_create_house_reports.sql",
+	"./migrations/032_create_audit_chain_lock.sql",
+	"./migrations/033_add_totp_code_attempts.sql",
+	"./migrations/034_add_payment_refunds.sql",
+	"./migrations/035_restore_bonus_campaign_kind_check.sql",
+}
+
 func SetupDatabase() {
 	db, err := sql.Open("sqlite3", "./data/berry_bet.db")
 	if err != nil {
@@ -18,31 +59,60 @@ func SetupDatabase() {
 
 	DB = db
 
-	migrations := []string{
-		"./migrations/001_create_users.sql",
-		"./migrations/002_create_games.sql",
-		"./migrations/003_create_bets.sql",
-		"./migrations/004_create_transactions.sql",
-		"./migrations/005_create_outcomes.sql",
-		"./migrations/006_create_sessions.sql",
-		"./migrations/007_create_user_stats.sql",
-		"./migrations/008_create_bet_limits.sql",
+	if err := Migrate(db, "."); err != nil {
+		log.Fatal(err)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		log.Fatal("Erro ao conectar ao banco de dados:", err)
+	}
+	log.Println("Migrações concluídas e banco de dados conectado com sucesso.")
+}
+
+// Migrate aplica em db as migrações ainda não registradas em
+// schema_migrations. dir é a raiz do projeto, onde fica a pasta migrations.
+func Migrate(db *sql.DB, dir string) error {
+	// schema_migrations registra as migrações já aplicadas, para que migrações
+	// não idempotentes (ALTER TABLE) rodem uma única vez.
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("Erro ao criar a tabela schema_migrations: %v", err)
 	}
 
 	for _, migrationFile := range migrations {
-		migration, err := os.ReadFile(migrationFile)
+		version := filepath.Base(migrationFile)
+		var applied int
+		err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", version).Scan(&applied)
 		if err != nil {
-			log.Fatalf("Erro ao ler o arquivo de migração %s: %v", migrationFile, err)
+			return fmt.Errorf("Erro ao consultar schema_migrations: %v", err)
 		}
-		_, err = db.Exec(string(migration))
-		if err != nil {
-			log.Fatalf("Erro ao executar a migração %s: %v", migrationFile, err)
+		if applied > 0 {
+			continue
 		}
-	}
 
-	err = db.Ping()
-	if err != nil {
-		log.Fatal("Erro ao conectar ao banco de dados:", err)
+		migration, err := os.ReadFile(filepath.Join(dir, migrationFile))
+		if err != nil {
+			return fmt.Errorf("Erro ao ler o arquivo de migração %s: %v", migrationFile, err)
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("Erro ao iniciar a migração %s: %v", migrationFile, err)
+		}
+		if _, err = tx.Exec(string(migration)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Erro ao executar a migração %s: %v", migrationFile, err)
+		}
+		if _, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Erro ao registrar a migração %s: %v", migrationFile, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("Erro ao concluir a migração %s: %v", migrationFile, err)
+		}
 	}
-	log.Println("Migrações concluídas e banco de dados conectado com sucesso.")
+	return nil
 }
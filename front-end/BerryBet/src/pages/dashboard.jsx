@@ -37,21 +37,18 @@ function Dashboard() {
     if (!valorDeposito || !selecionado || !user) return;
     const token = localStorage.getItem('token');
     try {
-      const res = await fetch('http://localhost:8080/api/v1/transactions', {
+      const res = await fetch('http://localhost:8080/api/payments/intents', {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
           Authorization: `Bearer ${token}`
         },
-        body: JSON.stringify({
-          user_id: user.id,
-          type: 'deposit',
-          amount: Number(valorDeposito),
-          description: `Depósito via ${nomesPagamento[selecionado] || selecionado}`
-        })
+        body: JSON.stringify({ amount: Number(valorDeposito) })
       });
       if (!res.ok) throw new Error('Erro ao depositar');
-      alert('Depósito enviado!');
+      const intent = (await res.json()).data;
+      // O saldo só é creditado quando o provedor confirma o pagamento
+      alert(`PIX copia e cola:\n\n${intent.qr_code}\n\nDepósito criado! O saldo será creditado quando o pagamento for confirmado.`);
       // Atualiza o saldo do usuário após depósito
       const userRes = await fetch('http://localhost:8080/api/users/me', {
         headers: { Authorization: `Bearer ${token}` },
@@ -10,7 +10,8 @@ function Register() {
     password: '123456', 
     cpf: '', 
     phone: '', 
-    date_birth: '' 
+    date_birth: '',
+    referral_code: new URLSearchParams(window.location.search).get('ref') || ''
   });
   const [error, setError] = useState('');
   const [success, setSuccess] = useState('');
@@ -275,6 +276,24 @@ function Register() {
                 }} 
               />
             </div>
+            {/* Código de convite (opcional) */}
+            <div style={{ marginBottom: '0.8rem', width: '100%' }}>
+              <label htmlFor="referral_code" style={{ color: '#fff', display: 'block', marginBottom: '0.2rem', fontSize: '0.9rem' }}>Código de convite (opcional):</label>
+              <input 
+                name="referral_code" 
+                id="referral_code"
+                value={form.referral_code} 
+                onChange={handleChange} 
+                style={{ 
+                  width: '100%', 
+                  padding: '0.6rem',
+                  borderRadius: '8px',
+                  border: '1px solid #ccc',
+                  fontSize: '0.9rem',
+                  textTransform: 'uppercase'
+                }} 
+              />
+            </div>
             {/* Botões em linha */}
             <div style={{ display: 'flex', gap: '0.5rem', marginBottom: '0.8rem' }}>
               <button 
@@ -312,7 +331,8 @@ function Register() {
                   password: '123456',
                   cpf: gerarCPF(),
                   phone: `(11) 9${Math.floor(1000 + Math.random() * 9000)}-${Math.floor(1000 + Math.random() * 9000)}`,
-                  date_birth: '1990-01-01'
+                  date_birth: '1990-01-01',
+                  referral_code: form.referral_code
                 })}
                 style={{ 
                   flex: 1,
@@ -0,0 +1,153 @@
+package account_tokens
+
+import (
+	"berry_bet/config"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	PurposeEmailVerification = "email_verification"
+	PurposePasswordReset     = "password_reset"
+)
+
+// TTLs é a validade de cada tipo de token.
+var TTLs = map[string]time.Duration{
+	PurposeEmailVerification: 48 * time.Hour,
+	PurposePasswordReset:     time.Hour,
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid or already used token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrNoSigningKey = errors.New("ACCOUNT_TOKEN_SECRET (or JWT_SECRET) is not configured")
+)
+
+// Token é um token válido já consumido.
+type Token struct {
+	UserID int64
+	Email  string
+}
+
+// signingKey usa ACCOUNT_TOKEN_SECRET, ou JWT_SECRET quando ela não está definida.
+func signingKey() ([]byte, error) {
+	key := os.Getenv("ACCOUNT_TOKEN_SECRET")
+	if key == "" {
+		key = os.Getenv("JWT_SECRET")
+	}
+	if key == "" {
+		return nil, ErrNoSigningKey
+	}
+	return []byte(key), nil
+}
+
+// CheckKey confirma na inicialização que há um segredo para assinar os tokens.
+func CheckKey() error {
+	_, err := signingKey()
+	return err
+}
+
+// sign devolve a assinatura do identificador, vinculada à finalidade do token.
+func sign(key []byte, purpose, id string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose + ":" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue cria um token de uso único para o usuário, invalidando os tokens
+// anteriores da mesma finalidade. O token tem o formato "<id>.<assinatura>".
+func Issue(userID int64, purpose, email string) (string, error) {
+	ttl, ok := TTLs[purpose]
+	if !ok {
+		return "", fmt.Errorf("unknown token purpose %q", purpose)
+	}
+	key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`
+		UPDATE account_tokens SET used_at = datetime('now')
+		WHERE user_id = ? AND purpose = ? AND used_at IS NULL`, userID, purpose)
+	if err != nil {
+		return "", err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO account_tokens (user_id, purpose, token_hash, email, expires_at, created_at)
+		VALUES (?, ?, ?, ?, datetime('now', ?), datetime('now'))`,
+		userID, purpose, hashID(id), email, fmt.Sprintf("+%d seconds", int(ttl.Seconds())))
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return id + "." + sign(key, purpose, id), nil
+}
+
+// Consume confere a assinatura, a validade e o uso único do token e o marca
+// como usado.
+func Consume(token, purpose string) (Token, error) {
+	key, err := signingKey()
+	if err != nil {
+		return Token{}, err
+	}
+	id, signature, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(key, purpose, id))) {
+		return Token{}, ErrInvalidToken
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return Token{}, err
+	}
+	defer tx.Rollback()
+
+	var t Token
+	var expired, used bool
+	err = tx.QueryRow(`
+		SELECT user_id, email, expires_at <= datetime('now'), used_at IS NOT NULL
+		FROM account_tokens WHERE token_hash = ? AND purpose = ?`, hashID(id), purpose).
+		Scan(&t.UserID, &t.Email, &expired, &used)
+	if err == sql.ErrNoRows || used {
+		return Token{}, ErrInvalidToken
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	if expired {
+		return Token{}, ErrTokenExpired
+	}
+	res, err := tx.Exec(`UPDATE account_tokens SET used_at = datetime('now') WHERE token_hash = ? AND used_at IS NULL`, hashID(id))
+	if err != nil {
+		return Token{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return Token{}, ErrInvalidToken
+	}
+	return t, tx.Commit()
+}
@@ -0,0 +1,42 @@
+package achievements
+
+type AchievementRequest struct {
+	Code             string  `json:"code" binding:"required"`
+	Name             string  `json:"name" binding:"required"`
+	Description      string  `json:"description" binding:"required"`
+	Rule             string  `json:"rule" binding:"required"`
+	GameType         *string `json:"game_type"`
+	Threshold        float64 `json:"threshold"`
+	Card             *string `json:"card"`
+	RewardCampaignID *int64  `json:"reward_campaign_id"`
+	Active           *bool   `json:"active"`
+}
+
+func (r AchievementRequest) toAchievement(id int64) Achievement {
+	a := Achievement{
+		ID:               id,
+		Code:             r.Code,
+		Name:             r.Name,
+		Description:      r.Description,
+		Rule:             r.Rule,
+		GameType:         r.GameType,
+		Threshold:        r.Threshold,
+		Card:             r.Card,
+		RewardCampaignID: r.RewardCampaignID,
+		Active:           true,
+	}
+	if r.Active != nil {
+		a.Active = *r.Active
+	}
+	if a.GameType != nil && *a.GameType == "" {
+		a.GameType = nil
+	}
+	return a
+}
+
+// MyAchievementsResponse é a vitrine de conquistas do perfil.
+type MyAchievementsResponse struct {
+	Unlocked int     `json:"unlocked"`
+	Total    int     `json:"total"`
+	Badges   []Badge `json:"badges"`
+}
@@ -0,0 +1,110 @@
+package achievements
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondAchievementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrAchievementNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Achievement not found.", nil)
+	case errors.Is(err, ErrInvalidAchievement):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ACHIEVEMENT", "Invalid achievement rule or threshold.", nil)
+	case errors.Is(err, ErrInvalidReward):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_REWARD", "Reward must be a free_spins or achievement bonus campaign.", nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to process achievement.", err.Error())
+	}
+}
+
+// GetMyAchievementsHandler returns the caller's badges with unlock dates and
+// progress towards the ones still locked.
+func GetMyAchievementsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	badges, err := GetBadges(userID)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	resp := MyAchievementsResponse{Total: len(badges), Badges: badges}
+	for _, b := range badges {
+		if b.Unlocked {
+			resp.Unlocked++
+		}
+	}
+	utils.RespondSuccess(c, resp, "Achievements found")
+}
+
+// ListAchievementsHandler lists every achievement definition.
+func ListAchievementsHandler(c *gin.Context) {
+	list, err := GetAchievements(false)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, list, "Achievements found")
+}
+
+// SaveAchievementHandler creates an achievement (POST) or replaces one (PUT /:id).
+func SaveAchievementHandler(c *gin.Context) {
+	var id int64
+	if c.Param("id") != "" {
+		var err error
+		if id, err = strconv.ParseInt(c.Param("id"), 10, 64); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+			return
+		}
+	}
+	var req AchievementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid input.", err.Error())
+		return
+	}
+	a := req.toAchievement(id)
+	a.Code = strings.ToUpper(strings.TrimSpace(a.Code))
+	if err := Validate(a); err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	var before any
+	if id != 0 {
+		previous, err := GetAchievement(id)
+		if err != nil {
+			respondAchievementError(c, err)
+			return
+		}
+		before = previous
+	}
+	id, err := SaveAchievement(a)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			utils.RespondError(c, http.StatusConflict, "DUPLICATE_CODE", "An achievement with this code already exists.", nil)
+			return
+		}
+		respondAchievementError(c, err)
+		return
+	}
+	saved, err := GetAchievement(id)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "achievement.saved",
+		TargetType: "achievement",
+		TargetID:   id,
+		Before:     before,
+		After:      saved,
+	})
+	utils.RespondSuccess(c, saved, "Achievement saved")
+}
@@ -0,0 +1,180 @@
+package achievements
+
+import (
+	"berry_bet/config"
+	"database/sql"
+)
+
+// Achievement é a definição declarativa de uma conquista.
+type Achievement struct {
+	ID               int64   `json:"id"`
+	Code             string  `json:"code"`
+	Name             string  `json:"name"`
+	Description      string  `json:"description"`
+	Rule             string  `json:"rule"`
+	GameType         *string `json:"game_type"`
+	Threshold        float64 `json:"threshold"`
+	Card             *string `json:"card"`
+	RewardCampaignID *int64  `json:"reward_campaign_id"`
+	Active           bool    `json:"active"`
+}
+
+// Badge é uma conquista vista pelo jogador, com o progresso atual.
+type Badge struct {
+	Achievement
+	Progress   float64 `json:"progress"` // de 0 a 1
+	Unlocked   bool    `json:"unlocked"`
+	UnlockedAt *string `json:"unlocked_at"`
+}
+
+const achievementColumns = `id, code, name, description, rule, game_type, threshold, card, reward_campaign_id, active`
+
+func scanAchievement(row interface{ Scan(dest ...any) error }) (Achievement, error) {
+	var a Achievement
+	err := row.Scan(&a.ID, &a.Code, &a.Name, &a.Description, &a.Rule, &a.GameType, &a.Threshold, &a.Card,
+		&a.RewardCampaignID, &a.Active)
+	return a, err
+}
+
+func listAchievements(where string, args ...any) ([]Achievement, error) {
+	rows, err := config.DB.Query(`SELECT `+achievementColumns+` FROM achievements `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Achievement{}
+	for rows.Next() {
+		a, err := scanAchievement(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// GetAchievements lista as conquistas; onlyActive filtra as ativas.
+func GetAchievements(onlyActive bool) ([]Achievement, error) {
+	if onlyActive {
+		return listAchievements(`WHERE active = 1`)
+	}
+	return listAchievements(``)
+}
+
+// GetAchievement devolve a conquista pelo id.
+func GetAchievement(id int64) (Achievement, error) {
+	a, err := scanAchievement(config.DB.QueryRow(`SELECT `+achievementColumns+` FROM achievements WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return a, ErrAchievementNotFound
+	}
+	return a, err
+}
+
+// SaveAchievement cria (ID zero) ou atualiza a conquista.
+func SaveAchievement(a Achievement) (int64, error) {
+	if a.ID == 0 {
+		res, err := config.DB.Exec(`
+			INSERT INTO achievements (code, name, description, rule, game_type, threshold, card, reward_campaign_id,
+				active, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))`,
+			a.Code, a.Name, a.Description, a.Rule, a.GameType, a.Threshold, a.Card, a.RewardCampaignID, a.Active)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+	res, err := config.DB.Exec(`
+		UPDATE achievements SET code = ?, name = ?, description = ?, rule = ?, game_type = ?, threshold = ?, card = ?,
+			reward_campaign_id = ?, active = ?, updated_at = datetime('now')
+		WHERE id = ?`,
+		a.Code, a.Name, a.Description, a.Rule, a.GameType, a.Threshold, a.Card, a.RewardCampaignID, a.Active, a.ID)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, ErrAchievementNotFound
+	}
+	return a.ID, nil
+}
+
+// unlockedAt devolve quando o jogador desbloqueou cada conquista.
+func unlockedAt(userID int64) (map[int64]string, error) {
+	rows, err := config.DB.Query(`SELECT achievement_id, CAST(unlocked_at AS TEXT) FROM user_achievements WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	unlocked := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var at string
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, err
+		}
+		unlocked[id] = at
+	}
+	return unlocked, rows.Err()
+}
+
+func insertUnlockTx(tx *sql.Tx, userID, achievementID int64) (bool, error) {
+	res, err := tx.Exec(`
+		INSERT OR IGNORE INTO user_achievements (user_id, achievement_id, unlocked_at)
+		VALUES (?, ?, datetime('now'))`, userID, achievementID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// measure calcula o valor que a regra compara com o threshold, a partir de
+// game_stats e bet_history. Regras com game_type olham apenas aquele jogo.
+func measure(userID int64, a Achievement) (float64, error) {
+	var v float64
+	var err error
+	switch a.Rule {
+	case RuleTotalBets:
+		err = config.DB.QueryRow(`SELECT COALESCE(SUM(total_bets), 0) FROM game_stats
+			WHERE user_id = ? AND (? IS NULL OR game_type = ?)`, userID, a.GameType, a.GameType).Scan(&v)
+	case RuleTotalWins:
+		err = config.DB.QueryRow(`SELECT COALESCE(SUM(total_wins), 0) FROM game_stats
+			WHERE user_id = ? AND (? IS NULL OR game_type = ?)`, userID, a.GameType, a.GameType).Scan(&v)
+	case RuleWinStreak:
+		err = config.DB.QueryRow(`SELECT COALESCE(MAX(best_win_streak), 0) FROM game_stats
+			WHERE user_id = ? AND (? IS NULL OR game_type = ?)`, userID, a.GameType, a.GameType).Scan(&v)
+	case RuleBiggestWin:
+		err = config.DB.QueryRow(`SELECT COALESCE(MAX(biggest_win), 0) FROM game_stats
+			WHERE user_id = ? AND (? IS NULL OR game_type = ?)`, userID, a.GameType, a.GameType).Scan(&v)
+	case RuleCard:
+		err = config.DB.QueryRow(`SELECT COUNT(*) FROM bet_history
+			WHERE user_id = ? AND (? IS NULL OR game_type = ?)
+				AND CASE WHEN json_valid(details) THEN json_extract(details, '$.card') END = ?`,
+			userID, a.GameType, a.GameType, a.Card).Scan(&v)
+	case RuleConsecutiveDays:
+		var days int
+		days, err = longestDayRun(userID, a.GameType)
+		v = float64(days)
+	}
+	return v, err
+}
+
+// longestDayRun devolve a maior sequência de dias seguidos com apostas.
+func longestDayRun(userID int64, gameType *string) (int, error) {
+	rows, err := config.DB.Query(`
+		SELECT DISTINCT date(created_at) AS day FROM bet_history
+		WHERE user_id = ? AND (? IS NULL OR game_type = ?)
+		ORDER BY day`, userID, gameType, gameType)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var days []string
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(&d); err != nil {
+			return 0, err
+		}
+		days = append(days, d)
+	}
+	return longestRun(days), rows.Err()
+}
@@ -0,0 +1,224 @@
+package achievements
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/responsible_gaming"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// Regras disponíveis para as conquistas.
+const (
+	RuleWinStreak       = "win_streak"       // vitórias seguidas no mesmo jogo
+	RuleConsecutiveDays = "consecutive_days" // dias seguidos com apostas
+	RuleCard            = "card"             // cartinha sorteada ao menos threshold vezes
+	RuleTotalBets       = "total_bets"
+	RuleTotalWins       = "total_wins"
+	RuleBiggestWin      = "biggest_win" // maior lucro em uma única aposta
+)
+
+var (
+	ErrAchievementNotFound = errors.New("achievement not found")
+	ErrInvalidAchievement  = errors.New("invalid achievement")
+	ErrInvalidReward       = errors.New("reward campaign must be a free_spins or achievement campaign")
+)
+
+// Validate confere a definição da conquista.
+func Validate(a Achievement) error {
+	if a.Code == "" || a.Name == "" || a.Description == "" || a.Threshold <= 0 {
+		return ErrInvalidAchievement
+	}
+	switch a.Rule {
+	case RuleCard:
+		if a.Card == nil || *a.Card == "" {
+			return ErrInvalidAchievement
+		}
+	case RuleWinStreak, RuleConsecutiveDays, RuleTotalBets, RuleTotalWins, RuleBiggestWin:
+	default:
+		return ErrInvalidAchievement
+	}
+	if a.RewardCampaignID != nil {
+		c, err := bonus.GetCampaign(*a.RewardCampaignID)
+		if errors.Is(err, bonus.ErrCampaignNotFound) {
+			return ErrInvalidReward
+		}
+		if err != nil {
+			return err
+		}
+		if c.Kind != bonus.KindFreeSpins && c.Kind != bonus.KindAchievement {
+			return ErrInvalidReward
+		}
+	}
+	return nil
+}
+
+// longestRun conta a maior sequência de datas (YYYY-MM-DD, ordenadas) consecutivas.
+func longestRun(days []string) int {
+	best, run := 0, 0
+	var prev time.Time
+	for _, d := range days {
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			continue
+		}
+		if run > 0 && t.Sub(prev) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		prev = t
+		if run > best {
+			best = run
+		}
+	}
+	return best
+}
+
+// GetBadges lista as conquistas ativas com o progresso do jogador, além das
+// inativas que ele já desbloqueou.
+func GetBadges(userID int64) ([]Badge, error) {
+	list, err := GetAchievements(false)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := unlockedAt(userID)
+	if err != nil {
+		return nil, err
+	}
+	badges := []Badge{}
+	for _, a := range list {
+		b := Badge{Achievement: a}
+		if at, ok := unlocked[a.ID]; ok {
+			b.Unlocked, b.UnlockedAt, b.Progress = true, &at, 1
+		} else if !a.Active {
+			continue
+		} else {
+			v, err := measure(userID, a)
+			if err != nil {
+				return nil, err
+			}
+			b.Progress = math.Min(v/a.Threshold, 1)
+		}
+		badges = append(badges, b)
+	}
+	return badges, nil
+}
+
+// Evaluate confere as conquistas ativas que o jogador ainda não tem e
+// desbloqueia as que foram atingidas, concedendo o prêmio, se houver.
+// Devolve as conquistas desbloqueadas agora.
+func Evaluate(userID int64) ([]Achievement, error) {
+	list, err := GetAchievements(true)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := unlockedAt(userID)
+	if err != nil {
+		return nil, err
+	}
+	var now []Achievement
+	for _, a := range list {
+		if _, ok := unlocked[a.ID]; ok {
+			continue
+		}
+		v, err := measure(userID, a)
+		if err != nil {
+			return now, err
+		}
+		if v < a.Threshold {
+			continue
+		}
+		ok, reward, err := unlock(userID, a)
+		if err != nil {
+			return now, err
+		}
+		if ok {
+			now = append(now, a)
+			notifyUnlocked(userID, a, reward)
+		}
+	}
+	return now, nil
+}
+
+// TrackBet chama Evaluate depois de uma aposta registrada e apenas loga
+// erros, para não recusar uma aposta já liquidada.
+func TrackBet(userID int64) {
+	if _, err := Evaluate(userID); err != nil {
+		log.Printf("Erro ao avaliar conquistas do usuário %d: %v", userID, err)
+	}
+}
+
+// unlock grava a conquista e o prêmio numa única transação. Contas em pausa
+// ou autoexcluídas desbloqueiam a conquista, mas sem prêmio.
+func unlock(userID int64, a Achievement) (bool, *bonus.UserBonus, error) {
+	var campaign *bonus.Campaign
+	if a.RewardCampaignID != nil {
+		ex, err := responsible_gaming.GetActiveExclusion(userID)
+		if err != nil {
+			return false, nil, err
+		}
+		if ex == nil {
+			c, err := bonus.GetCampaign(*a.RewardCampaignID)
+			if err != nil {
+				return false, nil, err
+			}
+			campaign = &c
+		}
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return false, nil, err
+	}
+	defer tx.Rollback()
+	ok, err := insertUnlockTx(tx, userID, a.ID)
+	if err != nil || !ok {
+		return false, nil, err
+	}
+	var reward *bonus.UserBonus
+	if campaign != nil {
+		if reward, err = bonus.GrantRewardTx(tx, *campaign, userID, "achievement:"+a.Code); err != nil {
+			return false, nil, err
+		}
+		if reward != nil {
+			_, err = tx.Exec(`UPDATE user_achievements SET user_bonus_id = ? WHERE user_id = ? AND achievement_id = ?`,
+				reward.ID, userID, a.ID)
+			if err != nil {
+				return false, nil, err
+			}
+		}
+	}
+	details := map[string]any{"achievement": a.Code}
+	if reward != nil {
+		details["bonus_id"] = reward.ID
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      audit.System,
+		Action:     "achievement.unlocked",
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    details,
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return true, reward, tx.Commit()
+}
+
+func notifyUnlocked(userID int64, a Achievement, reward *bonus.UserBonus) {
+	msg := fmt.Sprintf("Você desbloqueou a conquista %q: %s", a.Name, a.Description)
+	switch {
+	case reward == nil:
+	case reward.FreeSpinsRemaining > 0:
+		msg += fmt.Sprintf(" Prêmio: %d giros grátis.", reward.FreeSpinsRemaining)
+	default:
+		msg += fmt.Sprintf(" Prêmio: R$ %.2f de bônus.", reward.GrantedAmount)
+	}
+	notifications.Notify(userID, "achievement", "Conquista desbloqueada", msg)
+}
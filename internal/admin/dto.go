@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"berry_bet/internal/login_attempts"
+	"berry_bet/internal/transactions"
+	"berry_bet/internal/user_stats"
+	"berry_bet/internal/users"
+)
+
+type BalanceAdjustmentRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+type AccountStatusRequest struct {
+	Reason string `json:"reason"`
+}
+
+type BetLimitsRequest struct {
+	MinAmount float64 `json:"min_amount"`
+	MaxAmount float64 `json:"max_amount"`
+}
+
+type UserListResponse struct {
+	Users []UserSummary `json:"users"`
+	Total int           `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+}
+
+type UserProfileResponse struct {
+	User           users.UserResponse                 `json:"user"`
+	Account        AccountStatus                      `json:"account"`
+	Roles          []string                           `json:"roles"`
+	Stats          user_stats.UserStats               `json:"stats"`
+	ActiveSessions int                                `json:"active_sessions"`
+	LoginLock      login_attempts.AccountLock         `json:"login_lock"`
+	Ledger         []transactions.TransactionResponse `json:"ledger"`
+	LedgerPage     int                                `json:"ledger_page"`
+	LedgerLimit    int                                `json:"ledger_limit"`
+}
@@ -0,0 +1,201 @@
+package admin
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bets"
+	"berry_bet/internal/games"
+	"berry_bet/internal/utils"
+	"berry_bet/internal/wallet"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+func pagination(c *gin.Context, pageParam, limitParam string, defaultLimit int) (int, int) {
+	page, limit := 1, defaultLimit
+	if p, err := strconv.Atoi(c.Query(pageParam)); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query(limitParam)); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	return page, limit
+}
+
+func respondAdminError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+	case errors.Is(err, ErrReasonRequired), errors.Is(err, ErrSelfAction), errors.Is(err, bets.ErrInvalidBetLimits):
+		utils.RespondError(c, http.StatusBadRequest, "BUSINESS_RULE", err.Error(), nil)
+	case errors.Is(err, ErrStatusUnchanged), errors.Is(err, ErrNotLocked), errors.Is(err, games.ErrInvalidGameStatus):
+		utils.RespondError(c, http.StatusConflict, "INVALID_STATUS", err.Error(), nil)
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		utils.RespondError(c, http.StatusBadRequest, "INSUFFICIENT_FUNDS", "Adjustment would make the balance negative.", nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", fallback, err.Error())
+	}
+}
+
+// SearchUsersHandler lists users filtered by free text (q), status and role.
+func SearchUsersHandler(c *gin.Context) {
+	page, limit := pagination(c, "page", "limit", 20)
+	filter := UserFilter{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Status: c.Query("status"),
+		Role:   c.Query("role"),
+		Page:   page,
+		Limit:  limit,
+	}
+	list, total, err := SearchUsers(filter)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to search users.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, UserListResponse{Users: list, Total: total, Page: page, Limit: limit}, "Users found")
+}
+
+// GetUserProfileHandler returns a user's full profile with stats and a page of the ledger.
+func GetUserProfileHandler(c *gin.Context) {
+	userID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	page, limit := pagination(c, "ledger_page", "ledger_limit", 50)
+	profile, err := GetUserProfile(userID, page, limit)
+	if err != nil {
+		respondAdminError(c, err, "Failed to fetch user profile.")
+		return
+	}
+	utils.RespondSuccess(c, profile, "User profile found")
+}
+
+// AdjustBalanceHandler applies a manual balance adjustment with a mandatory reason.
+func AdjustBalanceHandler(c *gin.Context) {
+	userID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req BalanceAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	if req.Amount == 0 {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Amount must not be zero.", nil)
+		return
+	}
+	balance, err := AdjustBalance(audit.ActorFromContext(c), userID, req.Amount, req.Reason)
+	if err != nil {
+		respondAdminError(c, err, "Failed to adjust balance.")
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"user_id": userID, "balance": balance}, "Balance adjusted successfully.")
+}
+
+// FreezeUserHandler freezes an account and ends all of its sessions.
+func FreezeUserHandler(c *gin.Context) {
+	changeStatusHandler(c, true)
+}
+
+// UnfreezeUserHandler reactivates a frozen account.
+func UnfreezeUserHandler(c *gin.Context) {
+	changeStatusHandler(c, false)
+}
+
+func changeStatusHandler(c *gin.Context, freeze bool) {
+	userID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req AccountStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	var err error
+	message := "Account unfrozen successfully."
+	if freeze {
+		err = FreezeUser(audit.ActorFromContext(c), userID, req.Reason)
+		message = "Account frozen successfully."
+	} else {
+		err = UnfreezeUser(audit.ActorFromContext(c), userID, req.Reason)
+	}
+	if err != nil {
+		respondAdminError(c, err, "Failed to update account status.")
+		return
+	}
+	utils.RespondSuccess(c, nil, message)
+}
+
+// UnlockUserHandler lifts a login lockout caused by repeated failed attempts.
+func UnlockUserHandler(c *gin.Context) {
+	userID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := UnlockUser(audit.ActorFromContext(c), userID); err != nil {
+		respondAdminError(c, err, "Failed to unlock account.")
+		return
+	}
+	utils.RespondSuccess(c, nil, "Account unlocked successfully.")
+}
+
+// GetBetLimitsHandler returns the bet limits currently in force.
+func GetBetLimitsHandler(c *gin.Context) {
+	limits, err := bets.GetBetLimits()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch bet limits.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, limits, "Bet limits found")
+}
+
+// UpdateBetLimitsHandler replaces the bet limits; the previous values are kept as history.
+func UpdateBetLimitsHandler(c *gin.Context) {
+	var req BetLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	limits := bets.BetLimits{MinAmount: req.MinAmount, MaxAmount: req.MaxAmount}
+	if err := UpdateBetLimits(audit.ActorFromContext(c), limits); err != nil {
+		respondAdminError(c, err, "Failed to update bet limits.")
+		return
+	}
+	utils.RespondSuccess(c, limits, "Bet limits updated successfully.")
+}
+
+// StartGameHandler moves a scheduled game to active.
+func StartGameHandler(c *gin.Context) {
+	gameStateHandler(c, "start", "Game started successfully.")
+}
+
+// EndGameHandler moves an active game to finished.
+func EndGameHandler(c *gin.Context) {
+	gameStateHandler(c, "end", "Game ended successfully.")
+}
+
+func gameStateHandler(c *gin.Context, action, message string) {
+	gameID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if err := SetGameState(audit.ActorFromContext(c), gameID, action); err != nil {
+		respondAdminError(c, err, "Failed to update game.")
+		return
+	}
+	utils.RespondSuccess(c, nil, message)
+}
@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrStatusUnchanged = errors.New("account is already in the requested status")
+	ErrReasonRequired  = errors.New("reason is required (at least 5 characters)")
+	ErrSelfAction      = errors.New("admins cannot apply this action to their own account")
+	ErrNotLocked       = errors.New("account is not locked")
+)
+
+// UserFilter reúne os filtros da busca de usuários do back-office.
+type UserFilter struct {
+	Query  string
+	Status string
+	Role   string
+	Page   int
+	Limit  int
+}
+
+// UserSummary é a linha da listagem de usuários do back-office.
+type UserSummary struct {
+	ID          int64    `json:"id"`
+	Username    string   `json:"username"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	CPF         string   `json:"cpf"`
+	Phone       string   `json:"phone"`
+	Status      string   `json:"status"`
+	Balance     float64  `json:"balance"`
+	TotalBets   int64    `json:"total_bets"`
+	TotalProfit float64  `json:"total_profit"`
+	Roles       []string `json:"roles"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// AccountStatus descreve o estado de congelamento de uma conta.
+type AccountStatus struct {
+	Status       string `json:"status"`
+	FrozenAt     string `json:"frozen_at,omitempty"`
+	FrozenReason string `json:"frozen_reason,omitempty"`
+}
+
+// SearchUsers busca usuários por username, nome, e-mail, CPF ou telefone, com
+// filtros opcionais de status e papel. Retorna a página e o total de resultados.
+func SearchUsers(f UserFilter) ([]UserSummary, int, error) {
+	var where []string
+	var args []any
+	if f.Query != "" {
+		like := "%" + f.Query + "%"
+		where = append(where, "(u.username LIKE ? OR u.name LIKE ? OR u.email LIKE ? OR u.cpf LIKE ? OR u.phone LIKE ?)")
+		args = append(args, like, like, like, like, like)
+	}
+	if f.Status != "" {
+		where = append(where, "u.status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Role != "" {
+		where = append(where, `EXISTS (SELECT 1 FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = u.id AND r.name = ?)`)
+		args = append(args, f.Role)
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM users u"+whereSQL, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT u.id, u.username, u.name, u.email, u.cpf, COALESCE(u.phone, ''), u.status,
+			COALESCE(us.balance, 0), COALESCE(us.total_bets, 0), COALESCE(us.total_profit, 0),
+			COALESCE((SELECT GROUP_CONCAT(r.name) FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = u.id), ''),
+			u.created_at
+		FROM users u
+		LEFT JOIN user_stats us ON us.user_id = u.id`+whereSQL+`
+		ORDER BY u.id DESC
+		LIMIT ? OFFSET ?`, append(args, f.Limit, (f.Page-1)*f.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := make([]UserSummary, 0)
+	for rows.Next() {
+		var u UserSummary
+		var roleList string
+		err := rows.Scan(&u.ID, &u.Username, &u.Name, &u.Email, &u.CPF, &u.Phone, &u.Status,
+			&u.Balance, &u.TotalBets, &u.TotalProfit, &roleList, &u.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		u.Roles = []string{}
+		if roleList != "" {
+			u.Roles = strings.Split(roleList, ",")
+		}
+		list = append(list, u)
+	}
+	return list, total, rows.Err()
+}
+
+// GetAccountStatus retorna o status de congelamento da conta.
+func GetAccountStatus(userID int64) (AccountStatus, error) {
+	var s AccountStatus
+	var frozenAt, reason sql.NullString
+	err := config.DB.QueryRow(`SELECT status, frozen_at, frozen_reason FROM users WHERE id = ?`, userID).
+		Scan(&s.Status, &frozenAt, &reason)
+	if err == sql.ErrNoRows {
+		return s, ErrUserNotFound
+	}
+	s.FrozenAt = frozenAt.String
+	s.FrozenReason = reason.String
+	return s, err
+}
+
+// CountActiveSessions retorna quantas famílias de sessão do usuário ainda estão ativas.
+func CountActiveSessions(userID int64) (int, error) {
+	var count int
+	err := config.DB.QueryRow(`
+		SELECT COUNT(DISTINCT family_id) FROM sessions
+		WHERE user_id = ? AND family_id IS NOT NULL AND revoked_at IS NULL AND expires_at > datetime('now')`,
+		userID).Scan(&count)
+	return count, err
+}
+
+// setUserStatusTx altera o status da conta dentro da transação informada.
+func setUserStatusTx(tx *sql.Tx, userID int64, status, reason string) error {
+	var current string
+	err := tx.QueryRow(`SELECT status FROM users WHERE id = ?`, userID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if current == status {
+		return ErrStatusUnchanged
+	}
+	if status == "frozen" {
+		_, err = tx.Exec(`UPDATE users SET status = 'frozen', frozen_at = datetime('now'), frozen_reason = ?, updated_at = datetime('now') WHERE id = ?`, reason, userID)
+	} else {
+		_, err = tx.Exec(`UPDATE users SET status = 'active', frozen_at = NULL, frozen_reason = NULL, updated_at = datetime('now') WHERE id = ?`, userID)
+	}
+	return err
+}
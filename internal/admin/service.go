@@ -0,0 +1,257 @@
+package admin
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bets"
+	"berry_bet/internal/games"
+	"berry_bet/internal/login_attempts"
+	"berry_bet/internal/roles"
+	"berry_bet/internal/sessions"
+	"berry_bet/internal/transactions"
+	"berry_bet/internal/user_stats"
+	"berry_bet/internal/users"
+	"berry_bet/internal/wallet"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+)
+
+func validateReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) < 5 {
+		return "", ErrReasonRequired
+	}
+	return reason, nil
+}
+
+// GetUserProfile monta o perfil completo do usuário: dados cadastrais, status,
+// papéis, estatísticas, sessões ativas e uma página do ledger.
+func GetUserProfile(userID int64, ledgerPage, ledgerLimit int) (*UserProfileResponse, error) {
+	user, err := users.GetUserByID(strconv.FormatInt(userID, 10))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	status, err := GetAccountStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+	userRoles, err := roles.GetUserRoles(userID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := user_stats.GetUserStatsByID(strconv.FormatInt(userID, 10))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	activeSessions, err := CountActiveSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	loginLock, err := login_attempts.GetAccountLock(userID)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := transactions.GetTransactionsByUserID(userID, ledgerPage, ledgerLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &UserProfileResponse{
+		User:           users.ToUserResponseWithBalance(&user, stats.Balance),
+		Account:        status,
+		Roles:          userRoles,
+		Stats:          stats,
+		ActiveSessions: activeSessions,
+		LoginLock:      loginLock,
+		Ledger:         make([]transactions.TransactionResponse, 0, len(ledger)),
+		LedgerPage:     ledgerPage,
+		LedgerLimit:    ledgerLimit,
+	}
+	for _, t := range ledger {
+		profile.Ledger = append(profile.Ledger, transactions.ToTransactionResponse(&t))
+	}
+	return profile, nil
+}
+
+// AdjustBalance aplica um ajuste manual (positivo credita, negativo debita) no
+// saldo do usuário e registra a ação na auditoria. Retorna o novo saldo.
+func AdjustBalance(actor audit.Actor, userID int64, amount float64, reason string) (float64, error) {
+	reason, err := validateReason(reason)
+	if err != nil {
+		return 0, err
+	}
+	if amount == 0 {
+		return 0, errors.New("amount must not be zero")
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM users WHERE id = ?`, userID).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if exists == 0 {
+		return 0, ErrUserNotFound
+	}
+
+	var before float64
+	if err := tx.QueryRow(`SELECT balance FROM user_stats WHERE user_id = ?`, userID).Scan(&before); err != nil {
+		return 0, err
+	}
+
+	description := "Ajuste manual: " + reason
+	var balance float64
+	if amount > 0 {
+		balance, err = wallet.Credit(tx, userID, amount, "adjustment_credit", description)
+	} else {
+		balance, err = wallet.Debit(tx, userID, -amount, "adjustment_debit", description)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     "balance.adjust",
+		TargetType: "user",
+		TargetID:   userID,
+		Before:     map[string]any{"balance": before},
+		After:      map[string]any{"balance": balance},
+		Details:    map[string]any{"amount": amount, "reason": reason},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return balance, tx.Commit()
+}
+
+// FreezeUser congela a conta e encerra todas as sessões do usuário.
+func FreezeUser(actor audit.Actor, userID int64, reason string) error {
+	reason, err := validateReason(reason)
+	if err != nil {
+		return err
+	}
+	if actor.UserID == userID {
+		return ErrSelfAction
+	}
+	if err := changeStatus(actor, userID, "frozen", reason); err != nil {
+		return err
+	}
+	if _, err := sessions.RevokeUserSessions(userID, "", "account_frozen"); err != nil {
+		log.Printf("Erro ao revogar sessões do usuário %d: %v", userID, err)
+	}
+	return nil
+}
+
+// UnfreezeUser reativa uma conta congelada.
+func UnfreezeUser(actor audit.Actor, userID int64, reason string) error {
+	reason, err := validateReason(reason)
+	if err != nil {
+		return err
+	}
+	return changeStatus(actor, userID, "active", reason)
+}
+
+func changeStatus(actor audit.Actor, userID int64, status, reason string) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setUserStatusTx(tx, userID, status, reason); err != nil {
+		return err
+	}
+	action, before := "user.unfreeze", "frozen"
+	if status == "frozen" {
+		action, before = "user.freeze", "active"
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   userID,
+		Before:     map[string]any{"status": before},
+		After:      map[string]any{"status": status},
+		Details:    map[string]any{"reason": reason},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnlockUser remove o bloqueio de login causado por tentativas com senha incorreta.
+func UnlockUser(actor audit.Actor, userID int64) error {
+	if _, err := GetAccountStatus(userID); err != nil {
+		return err
+	}
+	before, err := login_attempts.GetAccountLock(userID)
+	if err != nil {
+		return err
+	}
+	wasLocked, err := login_attempts.Unlock(userID)
+	if err != nil {
+		return err
+	}
+	if !wasLocked {
+		return ErrNotLocked
+	}
+	return audit.Record(audit.Entry{
+		Actor:      actor,
+		Action:     "user.unlock",
+		TargetType: "user",
+		TargetID:   userID,
+		Before:     before,
+	})
+}
+
+// UpdateBetLimits grava novos limites de aposta e audita os valores anterior e novo.
+func UpdateBetLimits(actor audit.Actor, limits bets.BetLimits) error {
+	previous, err := bets.GetBetLimits()
+	if err != nil {
+		return err
+	}
+	if err := bets.SetBetLimits(limits); err != nil {
+		return err
+	}
+	return audit.Record(audit.Entry{
+		Actor:      actor,
+		Action:     "bet_limits.update",
+		TargetType: "bet_limits",
+		Before:     previous,
+		After:      limits,
+	})
+}
+
+// SetGameState inicia ("start") ou encerra ("end") um jogo e audita a ação.
+func SetGameState(actor audit.Actor, gameID int64, action string) error {
+	var err error
+	switch action {
+	case "start":
+		err = games.StartGame(gameID)
+	case "end":
+		err = games.EndGame(gameID)
+	default:
+		return errors.New("invalid game action")
+	}
+	if err != nil {
+		return err
+	}
+	return audit.Record(audit.Entry{
+		Actor:      actor,
+		Action:     "game." + action,
+		TargetType: "game",
+		TargetID:   gameID,
+	})
+}
@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"berry_bet/config"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mattn/go-sqlite3"
+)
+
+const dbTimeLayout = "2006-01-02 15:04:05"
+
+// Actor identifica quem executou a ação e de onde veio a requisição.
+// UserID zero representa o próprio sistema (jobs, liquidação automática).
+type Actor struct {
+	UserID    int64
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+// Entry descreve uma ação registrada na trilha de auditoria.
+type Entry struct {
+	Actor      Actor
+	Action     string
+	TargetType string
+	TargetID   int64
+	Before     any
+	After      any
+	Details    any
+}
+
+// ActorFromContext monta o Actor a partir da requisição (usuário autenticado,
+// IP, user agent e request ID).
+func ActorFromContext(c *gin.Context) Actor {
+	a := Actor{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetString("requestID"),
+	}
+	if id, ok := c.Get("userID"); ok {
+		if userID, ok := id.(int64); ok {
+			a.UserID = userID
+		}
+	}
+	return a
+}
+
+// System é o Actor usado em ações disparadas pelo próprio servidor.
+var System = Actor{}
+
+// recordAttempts é quantas vezes Record tenta gravar antes de desistir.
+const recordAttempts = 5
+
+// Record grava a entrada na trilha de auditoria, em transação própria. Falhas
+// temporárias (banco ocupado por outra escrita) são tentadas de novo.
+func Record(e Entry) error {
+	var err error
+	for attempt := 0; attempt < recordAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt*attempt) * 20 * time.Millisecond)
+		}
+		if err = recordOnce(e); err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func recordOnce(e Entry) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := write(tx, e); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// retryable indica se a escrita falhou por concorrência: banco ocupado ou
+// outra entrada encadeada ao mesmo hash (índice único de prev_hash).
+func retryable(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// RecordRequest grava a entrada usando os dados da requisição como Actor (o
+// UserID já definido em e.Actor tem precedência). A gravação é tentada de novo
+// em caso de concorrência; se ainda assim falhar, o erro é logado, pois a ação
+// auditada já foi concluída.
+func RecordRequest(c *gin.Context, e Entry) {
+	actor := ActorFromContext(c)
+	if e.Actor.UserID != 0 {
+		actor.UserID = e.Actor.UserID
+	}
+	e.Actor = actor
+	if err := Record(e); err != nil {
+		log.Printf("Erro ao registrar auditoria (%s): %v", e.Action, err)
+	}
+}
+
+// RecordTx grava a entrada dentro da transação SQL informada, para que a ação e
+// seu registro sejam confirmados (ou desfeitos) juntos. A transação fica com o
+// lock de escrita do banco até o commit ou rollback; um erro aqui deve desfazer
+// a ação.
+func RecordTx(tx *sql.Tx, e Entry) error {
+	return write(tx, e)
+}
+
+// chainRecord é o conteúdo coberto pelo hash de cada entrada.
+type chainRecord struct {
+	CreatedAt  string `json:"created_at"`
+	ActorID    int64  `json:"actor_id"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Details    string `json:"details"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	RequestID  string `json:"request_id"`
+}
+
+func computeHash(prevHash string, r chainRecord) (string, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(prevHash+"\n"), b...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func toJSON(v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func nullable(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id > 0}
+}
+
+func write(tx *sql.Tx, e Entry) error {
+	r := chainRecord{
+		CreatedAt:  time.Now().UTC().Format(dbTimeLayout),
+		ActorID:    e.Actor.UserID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		IPAddress:  e.Actor.IPAddress,
+		UserAgent:  e.Actor.UserAgent,
+		RequestID:  e.Actor.RequestID,
+	}
+	var err error
+	if r.Before, err = toJSON(e.Before); err != nil {
+		return err
+	}
+	if r.After, err = toJSON(e.After); err != nil {
+		return err
+	}
+	if r.Details, err = toJSON(e.Details); err != nil {
+		return err
+	}
+
+	// Grava antes de ler o último hash: isso toma o lock de escrita do SQLite,
+	// que só é liberado no commit ou rollback. Até lá nenhuma outra escrita,
+	// deste ou de outro processo, encadeia no mesmo hash.
+	if _, err := tx.Exec(`UPDATE audit_chain_lock SET updated_at = datetime('now') WHERE id = 1`); err != nil {
+		return err
+	}
+
+	var prevHash string
+	err = tx.QueryRow(`SELECT hash FROM audit_log WHERE hash IS NOT NULL ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	hash, err := computeHash(prevHash, r)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, before_json, after_json, details,
+			ip_address, user_agent, request_id, prev_hash, hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullableID(r.ActorID), r.Action, r.TargetType, nullableID(r.TargetID),
+		nullable(r.Before), nullable(r.After), nullable(r.Details),
+		nullable(r.IPAddress), nullable(r.UserAgent), nullable(r.RequestID),
+		prevHash, hash, r.CreatedAt)
+	return err
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/testutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordChainsEntries(t *testing.T) {
+	testutil.DB(t)
+	for i := 0; i < 3; i++ {
+		if err := Record(Entry{Actor: System, Action: "test.record", TargetType: "test", TargetID: int64(i + 1)}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	res, err := Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Checked != 3 {
+		t.Fatalf("Verify = %+v, want a valid chain of 3 entries", res)
+	}
+}
+
+// Entradas gravadas dentro da transação de outra ação só são confirmadas
+// depois; escritas concorrentes não podem se encadear ao mesmo hash.
+func TestConcurrentWritesKeepChain(t *testing.T) {
+	testutil.DB(t)
+	const standalone, inTx = 20, 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, standalone+inTx)
+	for i := 0; i < standalone; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- Record(Entry{Actor: System, Action: "test.record", TargetType: "test", TargetID: int64(i + 1)})
+		}(i)
+	}
+	for i := 0; i < inTx; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tx, err := config.DB.Begin()
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer tx.Rollback()
+			if err := RecordTx(tx, Entry{Actor: System, Action: "test.record_tx", TargetType: "test", TargetID: int64(i + 1)}); err != nil {
+				errs <- err
+				return
+			}
+			time.Sleep(5 * time.Millisecond) // o chamador ainda trabalha antes do commit
+			errs <- tx.Commit()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	res, err := Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Checked != standalone+inTx {
+		t.Fatalf("Verify = %+v, want a valid chain of %d entries", res, standalone+inTx)
+	}
+}
+
+func TestRolledBackEntryLeavesChainIntact(t *testing.T) {
+	testutil.DB(t)
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordTx(tx, Entry{Actor: System, Action: "test.rolled_back", TargetType: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if err := Record(Entry{Actor: System, Action: "test.record", TargetType: "test"}); err != nil {
+		t.Fatalf("Record after rollback: %v", err)
+	}
+	res, err := Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Checked != 1 {
+		t.Fatalf("Verify = %+v, want a valid chain of 1 entry", res)
+	}
+}
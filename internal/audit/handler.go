@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"berry_bet/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAuditLogHandler returns audit entries filtered by actor, action, target,
+// request ID and period (from/to, "YYYY-MM-DD" or "YYYY-MM-DD HH:MM:SS" UTC).
+func ListAuditLogHandler(c *gin.Context) {
+	f := Filter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		RequestID:  c.Query("request_id"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+		Page:       1,
+		Limit:      50,
+	}
+	f.ActorID, _ = strconv.ParseInt(c.Query("actor_id"), 10, 64)
+	f.TargetID, _ = strconv.ParseInt(c.Query("target_id"), 10, 64)
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		f.Page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		f.Limit = l
+	}
+
+	entries, total, err := List(f)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch audit log.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"entries": entries, "total": total, "page": f.Page, "limit": f.Limit}, "Audit log found")
+}
+
+// VerifyAuditLogHandler recomputes the hash chain and reports the first broken entry, if any.
+func VerifyAuditLogHandler(c *gin.Context) {
+	res, err := Verify()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to verify audit log.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, res, "Audit log verified")
+}
@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"encoding/json"
+	"strings"
+)
+
+// Filter reúne os filtros da consulta à trilha de auditoria.
+type Filter struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+	RequestID  string
+	From       string
+	To         string
+	Page       int
+	Limit      int
+}
+
+// LogEntry é uma linha da trilha de auditoria como armazenada.
+type LogEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    int64           `json:"actor_id,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int64           `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	PrevHash   string          `json:"prev_hash,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// VerifyResult descreve o resultado da verificação da cadeia de hashes.
+type VerifyResult struct {
+	Valid         bool   `json:"valid"`
+	Checked       int    `json:"checked"`
+	LegacyEntries int    `json:"legacy_entries"`
+	BrokenAtID    int64  `json:"broken_at_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// created_at é lido como texto para que o hash seja recalculado sobre o valor
+// exatamente como foi gravado.
+const logColumns = `id, COALESCE(actor_id, 0), action, target_type, COALESCE(target_id, 0),
+	COALESCE(before_json, ''), COALESCE(after_json, ''), COALESCE(details, ''),
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(request_id, ''),
+	prev_hash, hash, CAST(created_at AS TEXT)`
+
+type rawEntry struct {
+	LogEntry
+	record   chainRecord
+	prevHash sql.NullString
+	hash     sql.NullString
+}
+
+func scanEntry(rows interface{ Scan(dest ...any) error }) (rawEntry, error) {
+	var e rawEntry
+	r := &e.record
+	err := rows.Scan(&e.ID, &r.ActorID, &r.Action, &r.TargetType, &r.TargetID,
+		&r.Before, &r.After, &r.Details, &r.IPAddress, &r.UserAgent, &r.RequestID,
+		&e.prevHash, &e.hash, &r.CreatedAt)
+	if err != nil {
+		return e, err
+	}
+	e.ActorID, e.Action, e.TargetType, e.TargetID = r.ActorID, r.Action, r.TargetType, r.TargetID
+	e.IPAddress, e.UserAgent, e.RequestID, e.CreatedAt = r.IPAddress, r.UserAgent, r.RequestID, r.CreatedAt
+	e.Before = rawJSON(r.Before)
+	e.After = rawJSON(r.After)
+	e.Details = rawJSON(r.Details)
+	e.PrevHash = e.prevHash.String
+	e.Hash = e.hash.String
+	return e, nil
+}
+
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
+
+// List retorna uma página da trilha de auditoria (mais recentes primeiro) e o total.
+func List(f Filter) ([]LogEntry, int, error) {
+	var where []string
+	var args []any
+	if f.ActorID > 0 {
+		where = append(where, "actor_id = ?")
+		args = append(args, f.ActorID)
+	}
+	if f.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, f.Action)
+	}
+	if f.TargetType != "" {
+		where = append(where, "target_type = ?")
+		args = append(args, f.TargetType)
+	}
+	if f.TargetID > 0 {
+		where = append(where, "target_id = ?")
+		args = append(args, f.TargetID)
+	}
+	if f.RequestID != "" {
+		where = append(where, "request_id = ?")
+		args = append(args, f.RequestID)
+	}
+	if f.From != "" {
+		where = append(where, "created_at >= ?")
+		args = append(args, f.From)
+	}
+	if f.To != "" {
+		where = append(where, "created_at < ?")
+		args = append(args, f.To)
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM audit_log"+whereSQL, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := config.DB.Query("SELECT "+logColumns+" FROM audit_log"+whereSQL+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, f.Limit, (f.Page-1)*f.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := make([]LogEntry, 0)
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, e.LogEntry)
+	}
+	return list, total, rows.Err()
+}
+
+// Verify percorre a trilha em ordem e recalcula cada hash. Entradas anteriores
+// ao encadeamento (sem hash) são contadas como legadas, desde que não apareçam
+// depois do início da cadeia.
+func Verify() (VerifyResult, error) {
+	rows, err := config.DB.Query("SELECT " + logColumns + " FROM audit_log ORDER BY id ASC")
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	defer rows.Close()
+
+	res := VerifyResult{Valid: true}
+	prevHash := ""
+	chained := false
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return VerifyResult{}, err
+		}
+		if !e.hash.Valid {
+			if chained {
+				return broken(res, e.ID, "entry without hash after the chain started"), nil
+			}
+			res.LegacyEntries++
+			continue
+		}
+		chained = true
+		res.Checked++
+		if e.prevHash.String != prevHash {
+			return broken(res, e.ID, "prev_hash does not match the previous entry"), nil
+		}
+		expected, err := computeHash(prevHash, e.record)
+		if err != nil {
+			return VerifyResult{}, err
+		}
+		if expected != e.hash.String {
+			return broken(res, e.ID, "hash does not match the entry content"), nil
+		}
+		prevHash = e.hash.String
+	}
+	return res, rows.Err()
+}
+
+func broken(res VerifyResult, id int64, reason string) VerifyResult {
+	res.Valid = false
+	res.BrokenAtID = id
+	res.Reason = reason
+	return res
+}
@@ -1,8 +1,19 @@
 package auth
 
 import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/login_attempts"
+	"berry_bet/internal/referrals"
+	"berry_bet/internal/roles"
+	"berry_bet/internal/sessions"
+	"berry_bet/internal/token"
+	"berry_bet/internal/twofactor"
+	"berry_bet/internal/users"
 	"berry_bet/internal/utils"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,28 +28,249 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
+	ipKey := login_attempts.IPKey(c.ClientIP())
+	if !allowLogin(c, ipKey) {
+		return
+	}
+
 	user, err := GetUserByUsernameOrEmail(creds.Username)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
 		return
 	}
+	var userID int64
+	if user != nil {
+		userID = user.ID
+	}
+	accountKey := login_attempts.AccountKey(userID, creds.Username)
+	if !allowLogin(c, accountKey) {
+		audit.RecordRequest(c, audit.Entry{Action: "auth.login_blocked", TargetType: "user", TargetID: userID, Details: gin.H{"identifier": creds.Username}})
+		return
+	}
+
 	if user == nil {
+		audit.RecordRequest(c, audit.Entry{Action: "auth.login_failed", TargetType: "user", Details: gin.H{"identifier": creds.Username, "reason": "unknown_user"}})
+		registerLoginFailure(c, 0, ipKey, accountKey)
 		utils.RespondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid username or password.", nil)
 		return
 	}
 
 	err = utils.CheckPassword(user.PasswordHash, creds.Password)
 	if err != nil {
+		audit.RecordRequest(c, audit.Entry{Action: "auth.login_failed", TargetType: "user", TargetID: user.ID, Details: gin.H{"reason": "wrong_password"}})
+		registerLoginFailure(c, user.ID, ipKey, accountKey)
 		utils.RespondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid username or password.", nil)
 		return
 	}
+	if !checkActive(c, user.ID) {
+		audit.RecordRequest(c, audit.Entry{Action: "auth.login_failed", TargetType: "user", TargetID: user.ID, Details: gin.H{"reason": "account_frozen"}})
+		return
+	}
+
+	enabled, err := twofactor.IsEnabled(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch two-factor status.", err.Error())
+		return
+	}
+	if enabled {
+		challenge, err := twofactor.CreateChallenge(user.ID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "SESSION_ERROR", "Could not create login challenge.", err.Error())
+			return
+		}
+		utils.RespondSuccess(c, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresIn:         int(twofactor.ChallengeTTL.Seconds()),
+		}, "Two-factor code required")
+		return
+	}
+	completeLogin(c, user.ID, user.Username, accountKey, "password")
+}
+
+// LoginTwoFactorHandler exchanges a login challenge plus a TOTP (or recovery) code for a session.
+func LoginTwoFactorHandler(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "challenge_token and code are required.", nil)
+		return
+	}
+	ipKey := login_attempts.IPKey(c.ClientIP())
+	if !allowLogin(c, ipKey) {
+		return
+	}
+	userID, err := twofactor.ChallengeUser(req.ChallengeToken)
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+	accountKey := login_attempts.AccountKey(userID, "")
+	if !allowLogin(c, accountKey) {
+		return
+	}
+
+	_, err = twofactor.ResolveChallenge(req.ChallengeToken, req.Code)
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		audit.RecordRequest(c, audit.Entry{Action: "auth.login_failed", TargetType: "user", TargetID: userID, Details: gin.H{"reason": "invalid_totp"}})
+		registerLoginFailure(c, userID, ipKey, accountKey)
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOTP", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		respondChallengeError(c, err)
+		return
+	}
+
+	user, err := users.GetUserByID(strconv.FormatInt(userID, 10))
+	if err != nil {
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "User not found.", nil)
+		return
+	}
+	if !checkActive(c, user.ID) {
+		return
+	}
+	completeLogin(c, user.ID, user.Username, accountKey, "totp")
+}
+
+func respondChallengeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, twofactor.ErrChallengeExpired):
+		utils.RespondError(c, http.StatusUnauthorized, "CHALLENGE_EXPIRED", err.Error(), nil)
+	case errors.Is(err, twofactor.ErrInvalidChallenge), errors.Is(err, twofactor.ErrNotEnabled):
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_CHALLENGE", twofactor.ErrInvalidChallenge.Error(), nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Could not verify login challenge.", err.Error())
+	}
+}
 
-	token, err := GenerateJWT(user.ID, user.Username)
+// completeLogin clears the failed-attempt counter, opens the session and returns the tokens.
+func completeLogin(c *gin.Context, userID int64, username string, accountKey login_attempts.Key, method string) {
+	if err := login_attempts.Reset(accountKey); err != nil {
+		log.Printf("Erro ao zerar tentativas de login do usuário %d: %v", userID, err)
+	}
+	session, refreshToken, err := sessions.CreateRefreshSession(userID, deviceInfo(c))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "SESSION_ERROR", "Could not create session.", err.Error())
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Actor:      audit.Actor{UserID: userID},
+		Action:     "auth.login",
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    gin.H{"session_id": session.FamilyID, "method": method},
+	})
+	respondTokens(c, userID, username, session.FamilyID, refreshToken, "Login successful")
+}
+
+// RefreshTokenHandler exchanges a refresh token for a new access/refresh token pair.
+func RefreshTokenHandler(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "refresh_token is required.", nil)
+		return
+	}
+
+	session, refreshToken, err := sessions.RotateRefreshToken(req.RefreshToken, deviceInfo(c))
+	switch {
+	case errors.Is(err, sessions.ErrRefreshTokenReused):
+		utils.RespondError(c, http.StatusUnauthorized, "TOKEN_REUSED", err.Error(), nil)
+		return
+	case errors.Is(err, sessions.ErrRefreshTokenExpired):
+		utils.RespondError(c, http.StatusUnauthorized, "TOKEN_EXPIRED", err.Error(), nil)
+		return
+	case errors.Is(err, sessions.ErrInvalidRefreshToken), errors.Is(err, sessions.ErrSessionRevoked):
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", err.Error(), nil)
+		return
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "SESSION_ERROR", "Could not refresh session.", err.Error())
+		return
+	}
+
+	user, err := users.GetUserByID(strconv.FormatInt(session.UserID, 10))
+	if err != nil {
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "User not found.", nil)
+		return
+	}
+	if !checkActive(c, user.ID) {
+		return
+	}
+	respondTokens(c, user.ID, user.Username, session.FamilyID, refreshToken, "Token refreshed")
+}
+
+// LogoutHandler revokes the session family of the given refresh token.
+func LogoutHandler(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "refresh_token is required.", nil)
+		return
+	}
+	err := sessions.RevokeRefreshToken(req.RefreshToken)
+	if errors.Is(err, sessions.ErrInvalidRefreshToken) {
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "SESSION_ERROR", "Could not revoke session.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, nil, "Logged out successfully.")
+}
+
+// LogoutAllHandler revokes every session of the authenticated user, including the current one.
+func LogoutAllHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	revoked, err := sessions.RevokeUserSessions(userID, "", "logout_all")
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "SESSION_ERROR", "Could not revoke sessions.", err.Error())
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{Action: "auth.logout_all", TargetType: "user", TargetID: userID, Details: gin.H{"revoked_sessions": revoked}})
+	utils.RespondSuccess(c, gin.H{"revoked_sessions": revoked}, "Logged out from all devices.")
+}
+
+func respondTokens(c *gin.Context, userID int64, username, sessionID, refreshToken, message string) {
+	userRoles, err := roles.GetUserRoles(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Could not load user roles.", err.Error())
+		return
+	}
+	accessToken, err := token.GenerateJWT(userID, username, sessionID, userRoles)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "JWT_ERROR", "Could not generate token.", err.Error())
 		return
 	}
-	utils.RespondSuccess(c, gin.H{"token": token}, "Login successful")
+	utils.RespondSuccess(c, TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(token.AccessTokenTTL.Seconds()),
+		TokenType:    "Bearer",
+	}, message)
+}
+
+// checkActive rejects frozen accounts, writing the error response itself.
+func checkActive(c *gin.Context, userID int64) bool {
+	status, err := users.GetUserStatus(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return false
+	}
+	if status == "frozen" {
+		utils.RespondError(c, http.StatusForbidden, "ACCOUNT_FROZEN", "This account is frozen. Please contact support.", nil)
+		return false
+	}
+	return true
+}
+
+func deviceInfo(c *gin.Context) sessions.DeviceInfo {
+	return sessions.DeviceInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		DeviceID:  c.GetHeader(sessions.DeviceIDHeader),
+	}
 }
 
 func RegisterHandler(c *gin.Context) {
@@ -50,13 +282,15 @@ func RegisterHandler(c *gin.Context) {
 		CPF       string `json:"cpf"`
 		Phone     string `json:"phone"`
 		DateBirth string `json:"date_birth"`
+		// ReferralCode é o código de convite de quem indicou o jogador (opcional).
+		ReferralCode string `json:"referral_code"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid request.", err.Error())
 		return
 	}
-	if req.Username == "" || req.Name == "" || req.Email == "" || req.Password == "" || req.CPF == "" {
-		utils.RespondError(c, http.StatusBadRequest, "MISSING_FIELDS", "Username, name, email, password and cpf are required.", nil)
+	if req.Username == "" || req.Name == "" || req.Email == "" || req.Password == "" || req.CPF == "" || req.DateBirth == "" {
+		utils.RespondError(c, http.StatusBadRequest, "MISSING_FIELDS", "Username, name, email, password, cpf and date_birth are required.", nil)
 		return
 	}
 	if !utils.IsValidEmail(req.Email) {
@@ -71,12 +305,44 @@ func RegisterHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_PHONE", "Invalid phone number.", nil)
 		return
 	}
+	if !utils.IsValidDate(req.DateBirth) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_DATE_BIRTH", "date_birth must be a valid YYYY-MM-DD date.", nil)
+		return
+	}
+	if !utils.IsAdult(req.DateBirth) {
+		utils.RespondError(c, http.StatusForbidden, "UNDERAGE", "You must be at least 18 years old to register.", nil)
+		return
+	}
+	if req.ReferralCode != "" {
+		if _, err := referrals.LookupCode(req.ReferralCode); errors.Is(err, referrals.ErrInvalidCode) {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_REFERRAL_CODE", "Invalid referral code.", nil)
+			return
+		} else if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to check referral code.", err.Error())
+			return
+		}
+	}
 	err := CreateUser(req.Username, req.Name, req.Email, req.Password, req.CPF, req.Phone, req.DateBirth)
 	if err != nil {
 		utils.RespondError(c, http.StatusBadRequest, "REGISTER_FAIL", "Could not register user.", err.Error())
 		return
 	}
-	utils.RespondSuccess(c, nil, "User registered successfully.")
+	// Falhas no envio do e-mail não impedem o cadastro; o usuário pode pedir um novo link.
+	if user, err := GetUserByUsernameOrEmail(req.Username); err != nil || user == nil {
+		log.Printf("Erro ao buscar usuário %q recém-cadastrado: %v", req.Username, err)
+	} else {
+		if err := sendVerificationEmail(user.ID, user.Name, user.Email); err != nil {
+			log.Printf("Erro ao enviar e-mail de verificação ao usuário %d: %v", user.ID, err)
+		}
+		if req.ReferralCode != "" {
+			actor := audit.ActorFromContext(c)
+			actor.UserID = user.ID
+			if _, err := referrals.Attach(actor, req.ReferralCode, c.GetHeader(sessions.DeviceIDHeader)); err != nil {
+				log.Printf("Erro ao registrar a indicação do usuário %d: %v", user.ID, err)
+			}
+		}
+	}
+	utils.RespondSuccess(c, nil, "User registered successfully. Check your email to verify your account.")
 }
 
 func JWTAuthMiddleware() gin.HandlerFunc {
@@ -88,14 +354,51 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 		tokenStr := bearer[7:]
-		claims, err := ParseJWT(tokenStr)
+		claims, err := token.ParseJWT(tokenStr)
 		if err != nil {
 			utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid token.", err.Error())
 			c.Abort()
 			return
 		}
+		if claims.ID == "" {
+			utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "Token is not bound to a session.", nil)
+			c.Abort()
+			return
+		}
+		active, err := sessions.IsSessionActive(claims.ID, claims.UserID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "SESSION_ERROR", "Could not validate session.", err.Error())
+			c.Abort()
+			return
+		}
+		if !active {
+			utils.RespondError(c, http.StatusUnauthorized, "SESSION_REVOKED", "Session has been revoked.", nil)
+			c.Abort()
+			return
+		}
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("sessionID", claims.ID)
+		c.Set("roles", claims.Roles)
+		c.Next()
+	}
+}
+
+// OptionalJWTAuthMiddleware identifies the user when a valid token is sent,
+// but lets anonymous requests through.
+func OptionalJWTAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer := c.GetHeader("Authorization")
+		if len(bearer) > 7 {
+			if claims, err := token.ParseJWT(bearer[7:]); err == nil && claims.ID != "" {
+				if active, err := sessions.IsSessionActive(claims.ID, claims.UserID); err == nil && active {
+					c.Set("userID", claims.UserID)
+					c.Set("username", claims.Username)
+					c.Set("sessionID", claims.ID)
+					c.Set("roles", claims.Roles)
+				}
+			}
+		}
 		c.Next()
 	}
 }
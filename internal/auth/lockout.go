@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/login_attempts"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/utils"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowLogin rejects the attempt when the account or the client IP is locked or
+// still inside its backoff delay, writing the error response itself.
+func allowLogin(c *gin.Context, keys ...login_attempts.Key) bool {
+	decision, err := login_attempts.Check(keys...)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to check login attempts.", err.Error())
+		return false
+	}
+	if decision.Allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	details := gin.H{"retry_after": retryAfter}
+	switch {
+	case decision.Reason == login_attempts.ReasonThrottled:
+		utils.RespondError(c, http.StatusTooManyRequests, "LOGIN_THROTTLED", "Too many failed attempts. Please wait before trying again.", details)
+	case decision.Scope == login_attempts.ScopeIP:
+		utils.RespondError(c, http.StatusTooManyRequests, "IP_LOCKED", "Too many failed attempts from this address. Try again later.", details)
+	default:
+		utils.RespondError(c, http.StatusLocked, "ACCOUNT_LOCKED", "This account is temporarily locked after too many failed attempts.", details)
+	}
+	return false
+}
+
+// registerLoginFailure counts a failed attempt against every key and notifies
+// the account owner when the account gets locked.
+func registerLoginFailure(c *gin.Context, userID int64, keys ...login_attempts.Key) {
+	for _, k := range keys {
+		lockedUntil, err := login_attempts.RegisterFailure(k)
+		if err != nil {
+			log.Printf("Erro ao registrar falha de login (%s): %v", k.Scope, err)
+			continue
+		}
+		if lockedUntil.IsZero() {
+			continue
+		}
+		targetType := "ip"
+		if k.Scope == login_attempts.ScopeAccount {
+			targetType = "user"
+		}
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "auth." + k.Scope + "_locked",
+			TargetType: targetType,
+			TargetID:   k.UserID,
+			Details:    gin.H{"key": k.Value, "locked_until": lockedUntil.Format(time.RFC3339)},
+		})
+		if k.Scope == login_attempts.ScopeAccount && userID > 0 {
+			notifications.Notify(userID, "account_locked", "Conta bloqueada temporariamente",
+				fmt.Sprintf("Detectamos várias tentativas de login com senha incorreta. Por segurança, sua conta ficará bloqueada até %s (UTC). Se não foi você, troque sua senha assim que possível.",
+					lockedUntil.Format("02/01/2006 15:04")))
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"berry_bet/internal/roles"
+	"berry_bet/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request only if the user has one of the given roles.
+// It must run after JWTAuthMiddleware.
+func RequireRole(allowed ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, role := range c.GetStringSlice("roles") {
+			for _, a := range allowed {
+				if role == a {
+					c.Next()
+					return
+				}
+			}
+		}
+		utils.RespondError(c, http.StatusForbidden, "FORBIDDEN", "You do not have access to this resource.", nil)
+		c.Abort()
+	}
+}
+
+// RequirePermission allows the request only if one of the user's roles grants
+// the permission. It must run after JWTAuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, err := roles.HasPermission(c.GetStringSlice("roles"), permission)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Could not check permissions.", err.Error())
+			c.Abort()
+			return
+		}
+		if !ok {
+			utils.RespondError(c, http.StatusForbidden, "FORBIDDEN", "You do not have access to this resource.", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
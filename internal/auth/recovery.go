@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"berry_bet/internal/account_tokens"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/mailer"
+	"berry_bet/internal/sessions"
+	"berry_bet/internal/users"
+	"berry_bet/internal/utils"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// appLink monta o link do front-end (APP_BASE_URL) que recebe o token.
+func appLink(path, token string) string {
+	base := os.Getenv("APP_BASE_URL")
+	if base == "" {
+		base = "http://localhost:5173"
+	}
+	return strings.TrimRight(base, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func sendVerificationEmail(userID int64, name, email string) error {
+	token, err := account_tokens.Issue(userID, account_tokens.PurposeEmailVerification, email)
+	if err != nil {
+		return err
+	}
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirme seu e-mail no Berry Bet",
+		Body: fmt.Sprintf("Olá, %s!\n\nPara confirmar seu e-mail, acesse o link abaixo (válido por 48 horas):\n\n%s\n\nSe você não criou uma conta no Berry Bet, ignore esta mensagem.",
+			name, appLink("/verify-email", token)),
+	})
+}
+
+func sendPasswordResetEmail(userID int64, name, email string) error {
+	token, err := account_tokens.Issue(userID, account_tokens.PurposePasswordReset, email)
+	if err != nil {
+		return err
+	}
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Redefinição de senha do Berry Bet",
+		Body: fmt.Sprintf("Olá, %s!\n\nRecebemos um pedido para redefinir sua senha. Acesse o link abaixo (válido por 1 hora):\n\n%s\n\nSe não foi você, ignore esta mensagem; sua senha continua a mesma.",
+			name, appLink("/reset-password", token)),
+	})
+}
+
+func respondAccountTokenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, account_tokens.ErrTokenExpired):
+		utils.RespondError(c, http.StatusBadRequest, "TOKEN_EXPIRED", err.Error(), nil)
+	case errors.Is(err, account_tokens.ErrInvalidToken):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", err.Error(), nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "TOKEN_ERROR", "Could not validate token.", err.Error())
+	}
+}
+
+// RequestEmailVerificationHandler (re)sends the verification email to the authenticated user.
+func RequestEmailVerificationHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	user, err := users.GetUserByID(strconv.FormatInt(userID, 10))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
+	verified, err := users.IsEmailVerified(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
+	if verified {
+		utils.RespondError(c, http.StatusConflict, "ALREADY_VERIFIED", "Email is already verified.", nil)
+		return
+	}
+	if err := sendVerificationEmail(user.ID, user.Name, user.Email); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "MAIL_ERROR", "Could not send verification email.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, nil, "Verification email sent.")
+}
+
+// ConfirmEmailVerificationHandler marks the email as verified using the token sent by email.
+func ConfirmEmailVerificationHandler(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "token is required.", nil)
+		return
+	}
+	t, err := account_tokens.Consume(req.Token, account_tokens.PurposeEmailVerification)
+	if err != nil {
+		respondAccountTokenError(c, err)
+		return
+	}
+	ok, err := users.MarkEmailVerified(t.UserID, t.Email)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Could not verify email.", err.Error())
+		return
+	}
+	if !ok {
+		// O e-mail mudou depois do envio do token.
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", account_tokens.ErrInvalidToken.Error(), nil)
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{Actor: audit.Actor{UserID: t.UserID}, Action: "user.email_verified", TargetType: "user", TargetID: t.UserID, Details: gin.H{"email": t.Email}})
+	utils.RespondSuccess(c, nil, "Email verified successfully.")
+}
+
+// RequestPasswordResetHandler emails a reset link. It always answers the same
+// way so it cannot be used to discover registered emails.
+func RequestPasswordResetHandler(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !utils.IsValidEmail(req.Email) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_EMAIL", "A valid email is required.", nil)
+		return
+	}
+	user, err := GetUserByUsernameOrEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
+	if user != nil && strings.EqualFold(user.Email, strings.TrimSpace(req.Email)) {
+		if err := sendPasswordResetEmail(user.ID, user.Name, user.Email); err != nil {
+			log.Printf("Erro ao enviar e-mail de redefinição de senha ao usuário %d: %v", user.ID, err)
+		} else {
+			audit.RecordRequest(c, audit.Entry{Action: "auth.password_reset_requested", TargetType: "user", TargetID: user.ID})
+		}
+	}
+	utils.RespondSuccess(c, nil, "If the email is registered, a reset link has been sent.")
+}
+
+// ConfirmPasswordResetHandler sets a new password using the reset token and ends every session.
+func ConfirmPasswordResetHandler(c *gin.Context) {
+	var req PasswordResetConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "token and new_password are required.", nil)
+		return
+	}
+	if len(req.NewPassword) < 6 {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_PASSWORD", "A nova senha deve ter pelo menos 6 caracteres.", nil)
+		return
+	}
+	t, err := account_tokens.Consume(req.Token, account_tokens.PurposePasswordReset)
+	if err != nil {
+		respondAccountTokenError(c, err)
+		return
+	}
+	user, err := users.GetUserByID(strconv.FormatInt(t.UserID, 10))
+	if err != nil || !strings.EqualFold(user.Email, t.Email) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_TOKEN", account_tokens.ErrInvalidToken.Error(), nil)
+		return
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "HASH_ERROR", "Failed to hash password.", err.Error())
+		return
+	}
+	if err := users.UpdateUserPassword(user.ID, string(hashed)); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "UPDATE_FAIL", "Could not update password.", err.Error())
+		return
+	}
+	// Quem recebeu o link comprovou o acesso ao e-mail.
+	if _, err := users.MarkEmailVerified(user.ID, t.Email); err != nil {
+		log.Printf("Erro ao marcar e-mail do usuário %d como verificado: %v", user.ID, err)
+	}
+	if _, err := sessions.RevokeUserSessions(user.ID, "", "password_reset"); err != nil {
+		log.Printf("Erro ao revogar sessões do usuário %d: %v", user.ID, err)
+	}
+	audit.RecordRequest(c, audit.Entry{Actor: audit.Actor{UserID: user.ID}, Action: "user.password_reset", TargetType: "user", TargetID: user.ID})
+	utils.RespondSuccess(c, nil, "Password reset successfully. Please log in again.")
+}
+
+// RequireVerifiedEmail blocks users whose email has not been verified. Must run after JWTAuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := utils.CurrentUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		verified, err := users.IsEmailVerified(userID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+			c.Abort()
+			return
+		}
+		if !verified {
+			utils.RespondError(c, http.StatusForbidden, "EMAIL_NOT_VERIFIED", "Verify your email before doing this.", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,6 +1,9 @@
 package bets
 
 import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/responsible_gaming"
 	"berry_bet/internal/utils"
 	"net/http"
 	"strconv"
@@ -61,12 +64,21 @@ func AddBetHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "BUSINESS_RULE", err.Error(), nil)
 		return
 	}
+	if !responsible_gaming.CheckBet(c, bet.UserID, bet.Amount) {
+		return
+	}
 	success, err := AddBet(bet)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to register bet.", err.Error())
 		return
 	}
 	if success {
+		bonus.TrackWager(bet.UserID, "sports", bet.Amount)
+		// Apostas em jogos ficam pendentes; o resultado entra na sessão como zero.
+		if rc := responsible_gaming.TrackPlay(bet.UserID, bet.Amount, 0); rc != nil {
+			utils.RespondSuccess(c, gin.H{"reality_check": rc}, "Bet registered successfully")
+			return
+		}
 		utils.RespondSuccess(c, nil, "Bet registered successfully")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "INSERT_FAIL", "Could not register bet.", nil)
@@ -99,12 +111,35 @@ func UpdateBetHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "BUSINESS_RULE", err.Error(), nil)
 		return
 	}
+	previous, err := GetBetByID(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch bet.", err.Error())
+		return
+	}
+	if previous.ID == 0 {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Bet not found.", nil)
+		return
+	}
 	success, err := UpdateBet(bet, int64(betId))
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update bet.", err.Error())
 		return
 	}
 	if success {
+		action := "bet.update"
+		if previous.BetStatus != bet.BetStatus {
+			action = "bet.status_change"
+		}
+		audit.RecordRequest(c, audit.Entry{
+			Action:     action,
+			TargetType: "bet",
+			TargetID:   bet.ID,
+			Before:     ToBetResponse(&previous),
+			After:      ToBetResponse(&bet),
+		})
+		if previous.BetStatus == "pending" {
+			recordSettled(bet)
+		}
 		utils.RespondSuccess(c, nil, "Bet updated successfully")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "UPDATE_FAIL", "Could not update bet.", nil)
@@ -118,12 +153,27 @@ func DeleteBetHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
 		return
 	}
+	previous, err := GetBetByID(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch bet.", err.Error())
+		return
+	}
+	if previous.ID == 0 {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Bet not found.", nil)
+		return
+	}
 	success, err := DeleteBet(betId)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DELETE_FAIL", "Could not delete bet.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "bet.delete",
+			TargetType: "bet",
+			TargetID:   int64(betId),
+			Before:     ToBetResponse(&previous),
+		})
 		utils.RespondSuccess(c, nil, "Bet deleted successfully.")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "DELETE_FAIL", "Could not delete bet.", nil)
@@ -3,17 +3,21 @@ package bets
 import (
 	"berry_bet/config"
 	"database/sql"
+	"errors"
 	"log"
 )
 
+// ErrInvalidBetLimits indica limites fora da regra (mínimo positivo e não maior que o máximo).
+var ErrInvalidBetLimits = errors.New("min_amount must be positive and not greater than max_amount")
+
 type BetLimits struct {
-	MinAmount float64
-	MaxAmount float64
+	MinAmount float64 `json:"min_amount"`
+	MaxAmount float64 `json:"max_amount"`
 }
 
 func GetBetLimits() (BetLimits, error) {
 	var limits BetLimits
-	row := config.DB.QueryRow("SELECT min_amount, max_amount FROM bet_limits ORDER BY updated_at DESC LIMIT 1")
+	row := config.DB.QueryRow("SELECT min_amount, max_amount FROM bet_limits ORDER BY updated_at DESC, id DESC LIMIT 1")
 	err := row.Scan(&limits.MinAmount, &limits.MaxAmount)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -26,3 +30,13 @@ func GetBetLimits() (BetLimits, error) {
 	}
 	return limits, nil
 }
+
+// SetBetLimits grava novos limites de aposta. Cada alteração gera uma nova linha,
+// preservando o histórico; a linha mais recente é a vigente.
+func SetBetLimits(limits BetLimits) error {
+	if limits.MinAmount <= 0 || limits.MaxAmount < limits.MinAmount {
+		return ErrInvalidBetLimits
+	}
+	_, err := config.DB.Exec("INSERT INTO bet_limits (min_amount, max_amount, updated_at) VALUES (?, ?, datetime('now'))", limits.MinAmount, limits.MaxAmount)
+	return err
+}
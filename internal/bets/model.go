@@ -2,6 +2,7 @@ package bets
 
 import (
 	"berry_bet/config"
+	"berry_bet/internal/audit"
 	"database/sql"
 	"errors"
 
@@ -278,6 +279,14 @@ func UpdateBetStatus(betID int64, status string, profitLoss float64) error {
 		}
 	}()
 
+	var previous string
+	bet := Bet{ID: betID, BetStatus: status, ProfitLoss: profitLoss}
+	err = tx.QueryRow(`SELECT bet_status, user_id, amount, game_id FROM bets WHERE id = ?`, betID).
+		Scan(&previous, &bet.UserID, &bet.Amount, &bet.GameID)
+	if err != nil {
+		return err
+	}
+
 	_, err = tx.Exec(`
 		UPDATE bets 
 		SET bet_status = ?, profit_loss = ? 
@@ -287,7 +296,25 @@ func UpdateBetStatus(betID int64, status string, profitLoss float64) error {
 		return err
 	}
 
-	return tx.Commit()
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      audit.System,
+		Action:     "bet.status_change",
+		TargetType: "bet",
+		TargetID:   betID,
+		Before:     map[string]any{"bet_status": previous},
+		After:      map[string]any{"bet_status": status, "profit_loss": profitLoss},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	if previous == "pending" {
+		recordSettled(bet)
+	}
+	return nil
 }
 
 // ResolveBetsForGame resolve todas as apostas pendentes para um jogo
@@ -316,6 +343,7 @@ func ResolveBetsForGame(gameID int64, winningOutcome string) error {
 	}
 	defer rows.Close()
 
+	var settled []Bet
 	for rows.Next() {
 		var betID, userID, riggingLevel int64
 		var amount, odds float64
@@ -345,7 +373,30 @@ func ResolveBetsForGame(gameID int64, winningOutcome string) error {
 		if err != nil {
 			return err
 		}
+		status := "lost"
+		if isWin {
+			status = "won"
+		}
+		err = audit.RecordTx(tx, audit.Entry{
+			Actor:      audit.System,
+			Action:     "bet.status_change",
+			TargetType: "bet",
+			TargetID:   betID,
+			Before:     map[string]any{"bet_status": "pending"},
+			After:      map[string]any{"bet_status": status, "profit_loss": profitLoss},
+			Details:    map[string]any{"game_id": gameID, "winning_outcome": winningOutcome},
+		})
+		if err != nil {
+			return err
+		}
+		settled = append(settled, Bet{ID: betID, UserID: userID, Amount: amount, BetStatus: status, ProfitLoss: profitLoss, GameID: gameID})
 	}
 
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	for _, b := range settled {
+		recordSettled(b)
+	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package bets
 
 import (
+	"berry_bet/internal/dashboard"
 	"berry_bet/internal/users"
 	"errors"
 )
@@ -34,3 +35,13 @@ func ValidateBet(bet Bet) error {
 
 	return nil
 }
+
+// recordSettled registra no dashboard do apostador uma aposta que acabou de
+// sair de "pending" para "won" ou "lost"
+func recordSettled(bet Bet) {
+	req, ok := dashboard.SportsBetRequest(bet.ID, bet.GameID, bet.Amount, bet.ProfitLoss, bet.BetStatus)
+	if !ok {
+		return
+	}
+	dashboard.TrackBet(bet.UserID, req)
+}
@@ -0,0 +1,247 @@
+package bonus
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/testutil"
+	"berry_bet/internal/wallet"
+	"errors"
+	"testing"
+)
+
+// deposit credita um depósito e concede o bônus de primeiro depósito na mesma
+// transação, como faz o webhook de pagamentos.
+func deposit(t *testing.T, userID int64, amount float64) *UserBonus {
+	t.Helper()
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := wallet.Credit(tx, userID, amount, "deposit", "Depósito de teste"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := GrantDepositMatchTx(tx, userID, amount)
+	if err != nil {
+		t.Fatalf("GrantDepositMatchTx: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func bonusByID(t *testing.T, id int64) UserBonus {
+	t.Helper()
+	b, err := getBonus(config.DB, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDepositMatchOnlyOnFirstDeposit(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	// BOASVINDAS: 100% até R$ 500, depósito mínimo R$ 20, rollover de 20x
+	b := deposit(t, user, 800)
+	if b == nil {
+		t.Fatal("first deposit did not grant the welcome bonus")
+	}
+	if b.GrantedAmount != 500 || b.Balance != 500 || b.WageringRequired != 10000 {
+		t.Fatalf("bonus = %+v, want 500 capped with 10000 wagering", b)
+	}
+	if b := deposit(t, user, 100); b != nil {
+		t.Fatalf("second deposit granted %+v", b)
+	}
+
+	other := testutil.User(t, "bob", 0)
+	if b := deposit(t, other, 10); b != nil {
+		t.Fatalf("deposit under the minimum granted %+v", b)
+	}
+	// O bônus não entra no saldo real antes do rollover
+	if got := testutil.Balance(t, user); got != 900 {
+		t.Fatalf("balance = %v, want 900", got)
+	}
+}
+
+func TestRolloverConvertsBonus(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	b := deposit(t, user, 50) // R$ 50 de bônus, R$ 1000 de rollover
+
+	// Esportes contam pela metade
+	if err := RecordWager(user, "sports", 100); err != nil {
+		t.Fatal(err)
+	}
+	if got := bonusByID(t, b.ID).Wagered; got != 50 {
+		t.Fatalf("wagered after sports = %v, want 50", got)
+	}
+	// Jogos sem peso não contam
+	if err := RecordWager(user, "desconhecido", 100); err != nil {
+		t.Fatal(err)
+	}
+	if got := bonusByID(t, b.ID).Wagered; got != 50 {
+		t.Fatalf("wagered after an unweighted game = %v, want 50", got)
+	}
+
+	if err := RecordWager(user, "roleta", 900); err != nil {
+		t.Fatal(err)
+	}
+	if got := bonusByID(t, b.ID); got.Status != StatusActive || got.Wagered != 950 {
+		t.Fatalf("bonus = %s with %v wagered, want active with 950", got.Status, got.Wagered)
+	}
+	if got := testutil.Balance(t, user); got != 50 {
+		t.Fatalf("balance before the rollover = %v, want 50", got)
+	}
+
+	// A aposta que completa o rollover converte o bônus em saldo real
+	if err := RecordWager(user, "roleta", 200); err != nil {
+		t.Fatal(err)
+	}
+	done := bonusByID(t, b.ID)
+	if done.Status != StatusCompleted || done.Balance != 0 || done.Wagered != 1000 || done.FinishedAt == nil {
+		t.Fatalf("bonus = %+v, want completed with the rollover capped at 1000", done)
+	}
+	if got := testutil.Balance(t, user); got != 100 {
+		t.Fatalf("balance after conversion = %v, want 100", got)
+	}
+	if active, err := HasActive(user); err != nil || active {
+		t.Fatalf("HasActive = %v, %v; want false", active, err)
+	}
+
+	// Apostas posteriores não convertem de novo
+	if err := RecordWager(user, "roleta", 1000); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.Balance(t, user); got != 100 {
+		t.Fatalf("balance after another wager = %v, want 100", got)
+	}
+}
+
+func TestFreeSpinsWinningsNeedRollover(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	// GIROS10: 10 giros de R$ 1, ganhos com rollover de 10x
+	b, err := Claim(user, "GIROS10")
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if _, err := Claim(user, "GIROS10"); !errors.Is(err, ErrAlreadyClaimed) {
+		t.Fatalf("second Claim error = %v, want ErrAlreadyClaimed", err)
+	}
+	if _, err := Claim(user, "BOASVINDAS"); !errors.Is(err, ErrNotClaimable) {
+		t.Fatalf("Claim deposit match error = %v, want ErrNotClaimable", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		spin, err := UseFreeSpin(user)
+		if err != nil {
+			t.Fatalf("spin %d: %v", i+1, err)
+		}
+		if spin.BonusID != b.ID || spin.Value != 1 {
+			t.Fatalf("spin = %+v", spin)
+		}
+		win := 0.0
+		if i == 0 {
+			win = 3
+		}
+		if err := SettleFreeSpin(spin, win); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := UseFreeSpin(user); !errors.Is(err, ErrNoFreeSpins) {
+		t.Fatalf("UseFreeSpin error = %v, want ErrNoFreeSpins", err)
+	}
+
+	got := bonusByID(t, b.ID)
+	if got.Status != StatusActive || got.Balance != 3 || got.WageringRequired != 30 {
+		t.Fatalf("bonus = %+v, want R$ 3 waiting for R$ 30 of rollover", got)
+	}
+	if err := RecordWager(user, "roleta", 30); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.Balance(t, user); got != 3 {
+		t.Fatalf("balance = %v, want 3", got)
+	}
+}
+
+func TestForfeitAndExpire(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	b := deposit(t, user, 40)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lost, err := ForfeitActiveTx(tx, audit.Actor{UserID: user}, user, "withdrawal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if lost != 40 || bonusByID(t, b.ID).Status != StatusForfeited {
+		t.Fatalf("forfeited %v, status %s", lost, bonusByID(t, b.ID).Status)
+	}
+	if err := RecordWager(user, "roleta", 1000); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.Balance(t, user); got != 40 {
+		t.Fatalf("balance = %v, want only the deposit", got)
+	}
+
+	spins, err := Claim(user, "GIROS10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.DB.Exec(`UPDATE user_bonuses SET expires_at = datetime('now', '-1 minute') WHERE id = ?`, spins.ID); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := GetSummary(user); s.FreeSpins != 0 {
+		t.Fatalf("expired spins still listed: %+v", s)
+	}
+	if n, err := ExpireBonuses(); err != nil || n != 1 {
+		t.Fatalf("ExpireBonuses = %d, %v; want 1", n, err)
+	}
+	if got := bonusByID(t, spins.ID); got.Status != StatusExpired || got.FreeSpinsRemaining != 0 {
+		t.Fatalf("bonus = %+v, want expired", got)
+	}
+}
+
+func TestValidateCampaign(t *testing.T) {
+	valid := Campaign{Code: "X", Name: "X", Kind: KindCashback, CashbackPercent: 10, ValidDays: 7}
+	if err := ValidateCampaign(valid); err != nil {
+		t.Fatalf("ValidateCampaign: %v", err)
+	}
+	broken := []func(c *Campaign){
+		func(c *Campaign) { c.Code = "" },
+		func(c *Campaign) { c.ValidDays = 0 },
+		func(c *Campaign) { c.WageringMultiplier = -1 },
+		func(c *Campaign) { c.CashbackPercent = 101 },
+		func(c *Campaign) { c.Kind = "jackpot" },
+		func(c *Campaign) { c.Kind = KindDepositMatch },
+		func(c *Campaign) { c.Kind = KindFreeSpins; c.FreeSpins = 5 },
+	}
+	for i, breakIt := range broken {
+		c := valid
+		breakIt(&c)
+		if err := ValidateCampaign(c); !errors.Is(err, ErrInvalidCampaign) {
+			t.Errorf("case %d: error = %v, want ErrInvalidCampaign", i, err)
+		}
+	}
+}
+
+func TestCampaignKindCheckedByDatabase(t *testing.T) {
+	testutil.DB(t)
+	if _, err := config.DB.Exec(`INSERT INTO bonus_campaigns (code, name, kind) VALUES ('JACKPOT', 'Jackpot', 'jackpot')`); err == nil {
+		t.Fatal("bonus_campaigns accepted an unknown kind")
+	}
+	var n int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM bonus_campaigns WHERE code IN ('BOASVINDAS', 'INDIQUE', 'CONQUISTA10')`).Scan(&n); err != nil || n != 3 {
+		t.Fatalf("%d seeded campaigns after the rebuild (%v), want 3", n, err)
+	}
+}
@@ -0,0 +1,54 @@
+package bonus
+
+type ClaimRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type CampaignRequest struct {
+	Code               string  `json:"code" binding:"required"`
+	Name               string  `json:"name" binding:"required"`
+	Kind               string  `json:"kind" binding:"required"`
+	Active             *bool   `json:"active"`
+	MatchPercent       float64 `json:"match_percent"`
+	MaxBonus           float64 `json:"max_bonus"`
+	MinDeposit         float64 `json:"min_deposit"`
+	MinBets            int     `json:"min_bets"`
+	FreeSpins          int     `json:"free_spins"`
+	SpinValue          float64 `json:"spin_value"`
+	CashbackPercent    float64 `json:"cashback_percent"`
+	WageringMultiplier float64 `json:"wagering_multiplier"`
+	ValidDays          int     `json:"valid_days"`
+}
+
+func (r CampaignRequest) toCampaign(id int64) Campaign {
+	c := Campaign{
+		ID:                 id,
+		Code:               r.Code,
+		Name:               r.Name,
+		Kind:               r.Kind,
+		Active:             true,
+		MatchPercent:       r.MatchPercent,
+		MaxBonus:           r.MaxBonus,
+		MinDeposit:         r.MinDeposit,
+		MinBets:            r.MinBets,
+		FreeSpins:          r.FreeSpins,
+		SpinValue:          r.SpinValue,
+		CashbackPercent:    r.CashbackPercent,
+		WageringMultiplier: r.WageringMultiplier,
+		ValidDays:          r.ValidDays,
+	}
+	if r.Active != nil {
+		c.Active = *r.Active
+	}
+	return c
+}
+
+type GameWeightRequest struct {
+	Weight *float64 `json:"weight" binding:"required"`
+}
+
+// MyBonusesResponse é o saldo de bônus do jogador com o andamento de cada bônus.
+type MyBonusesResponse struct {
+	Summary
+	Bonuses []UserBonus `json:"bonuses"`
+}
@@ -0,0 +1,172 @@
+package bonus
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondBonusError(c *gin.Context, err error) {
+	switch {
+	case responsible_gaming.RespondPlayError(c, err):
+	case errors.Is(err, ErrCampaignNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Bonus campaign not found.", nil)
+	case errors.Is(err, ErrNotClaimable):
+		utils.RespondError(c, http.StatusBadRequest, "NOT_CLAIMABLE", "This campaign cannot be claimed.", nil)
+	case errors.Is(err, ErrAlreadyClaimed):
+		utils.RespondError(c, http.StatusConflict, "ALREADY_CLAIMED", "You have already claimed this campaign.", nil)
+	case errors.Is(err, ErrInvalidCampaign):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_CAMPAIGN", "Invalid campaign settings for its kind.", nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to process bonus.", err.Error())
+	}
+}
+
+// GetMyBonusesHandler returns the caller's bonus balance, free spins and the
+// wagering progress of each bonus.
+func GetMyBonusesHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	summary, err := GetSummary(userID)
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	list, err := GetUserBonuses(userID)
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, MyBonusesResponse{Summary: summary, Bonuses: list}, "Bonuses found")
+}
+
+// GetCampaignsHandler lists the active bonus campaigns.
+func GetCampaignsHandler(c *gin.Context) {
+	list, err := GetCampaigns(true)
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, list, "Campaigns found")
+}
+
+// ClaimBonusHandler claims a free-spins campaign by code.
+func ClaimBonusHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	var req ClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid input.", err.Error())
+		return
+	}
+	b, err := Claim(userID, strings.ToUpper(strings.TrimSpace(req.Code)))
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, b, "Bonus claimed")
+}
+
+// ListCampaignsHandler lists every bonus campaign, including inactive ones.
+func ListCampaignsHandler(c *gin.Context) {
+	list, err := GetCampaigns(false)
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, list, "Campaigns found")
+}
+
+// SaveCampaignHandler creates a campaign (POST) or replaces one (PUT /:id).
+func SaveCampaignHandler(c *gin.Context) {
+	var id int64
+	if c.Param("id") != "" {
+		var err error
+		if id, err = strconv.ParseInt(c.Param("id"), 10, 64); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+			return
+		}
+	}
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid input.", err.Error())
+		return
+	}
+	campaign := req.toCampaign(id)
+	campaign.Code = strings.ToUpper(strings.TrimSpace(campaign.Code))
+	if err := ValidateCampaign(campaign); err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	var before any
+	if id != 0 {
+		previous, err := GetCampaign(id)
+		if err != nil {
+			respondBonusError(c, err)
+			return
+		}
+		before = previous
+	}
+	id, err := SaveCampaign(campaign)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			utils.RespondError(c, http.StatusConflict, "DUPLICATE_CODE", "A campaign with this code already exists.", nil)
+			return
+		}
+		respondBonusError(c, err)
+		return
+	}
+	saved, err := GetCampaign(id)
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "bonus.campaign_saved",
+		TargetType: "bonus_campaign",
+		TargetID:   id,
+		Before:     before,
+		After:      saved,
+	})
+	utils.RespondSuccess(c, saved, "Campaign saved")
+}
+
+// GetGameWeightsHandler lists how much each game contributes to wagering.
+func GetGameWeightsHandler(c *gin.Context) {
+	list, err := GetGameWeights()
+	if err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, list, "Game weights found")
+}
+
+// SetGameWeightHandler sets a game's wagering contribution, from 0 to 1.
+func SetGameWeightHandler(c *gin.Context) {
+	var req GameWeightRequest
+	if err := c.ShouldBindJSON(&req); err != nil || *req.Weight < 0 || *req.Weight > 1 {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_WEIGHT", "Weight must be between 0 and 1.", nil)
+		return
+	}
+	game := c.Param("game")
+	if err := SetGameWeight(game, *req.Weight); err != nil {
+		respondBonusError(c, err)
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "bonus.game_weight_change",
+		TargetType: "game",
+		Details:    map[string]any{"game": game, "weight": *req.Weight},
+	})
+	utils.RespondSuccess(c, GameWeight{Game: game, Weight: *req.Weight}, "Game weight saved")
+}
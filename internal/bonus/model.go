@@ -0,0 +1,249 @@
+package bonus
+
+import (
+	"berry_bet/config"
+	"database/sql"
+)
+
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Campaign é uma campanha de bônus.
+type Campaign struct {
+	ID                 int64   `json:"id"`
+	Code               string  `json:"code"`
+	Name               string  `json:"name"`
+	Kind               string  `json:"kind"`
+	Active             bool    `json:"active"`
+	MatchPercent       float64 `json:"match_percent"`
+	MaxBonus           float64 `json:"max_bonus"`
+	MinDeposit         float64 `json:"min_deposit"`
+	MinBets            int     `json:"min_bets"`
+	FreeSpins          int     `json:"free_spins"`
+	SpinValue          float64 `json:"spin_value"`
+	CashbackPercent    float64 `json:"cashback_percent"`
+	WageringMultiplier float64 `json:"wagering_multiplier"`
+	ValidDays          int     `json:"valid_days"`
+}
+
+// UserBonus é um bônus concedido ao jogador.
+type UserBonus struct {
+	ID                 int64   `json:"id"`
+	UserID             int64   `json:"user_id"`
+	CampaignID         int64   `json:"campaign_id"`
+	CampaignCode       string  `json:"campaign_code"`
+	Kind               string  `json:"kind"`
+	Status             string  `json:"status"`
+	GrantedAmount      float64 `json:"granted_amount"`
+	Balance            float64 `json:"balance"`
+	WageringRequired   float64 `json:"wagering_required"`
+	Wagered            float64 `json:"wagered"`
+	FreeSpinsRemaining int     `json:"free_spins_remaining"`
+	SpinValue          float64 `json:"spin_value"`
+	ExpiresAt          string  `json:"expires_at"`
+	CreatedAt          string  `json:"created_at"`
+	FinishedAt         *string `json:"finished_at"`
+
+	multiplier float64
+	ref        string
+}
+
+const campaignColumns = `id, code, name, kind, active, match_percent, max_bonus, min_deposit, min_bets, free_spins,
+	spin_value, cashback_percent, wagering_multiplier, valid_days`
+
+func scanCampaign(row interface{ Scan(dest ...any) error }) (Campaign, error) {
+	var c Campaign
+	err := row.Scan(&c.ID, &c.Code, &c.Name, &c.Kind, &c.Active, &c.MatchPercent, &c.MaxBonus, &c.MinDeposit,
+		&c.MinBets, &c.FreeSpins, &c.SpinValue, &c.CashbackPercent, &c.WageringMultiplier, &c.ValidDays)
+	return c, err
+}
+
+func listCampaigns(db dbtx, where string, args ...any) ([]Campaign, error) {
+	rows, err := db.Query(`SELECT `+campaignColumns+` FROM bonus_campaigns `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// GetCampaigns lista as campanhas; onlyActive filtra as ativas.
+func GetCampaigns(onlyActive bool) ([]Campaign, error) {
+	if onlyActive {
+		return listCampaigns(config.DB, `WHERE active = 1`)
+	}
+	return listCampaigns(config.DB, ``)
+}
+
+// GetCampaign devolve a campanha pelo id.
+func GetCampaign(id int64) (Campaign, error) {
+	c, err := scanCampaign(config.DB.QueryRow(`SELECT `+campaignColumns+` FROM bonus_campaigns WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return c, ErrCampaignNotFound
+	}
+	return c, err
+}
+
+func getCampaignByCode(code string) (Campaign, error) {
+	c, err := scanCampaign(config.DB.QueryRow(`SELECT `+campaignColumns+` FROM bonus_campaigns WHERE code = ?`, code))
+	if err == sql.ErrNoRows {
+		return c, ErrCampaignNotFound
+	}
+	return c, err
+}
+
+// SaveCampaign cria (ID zero) ou atualiza a campanha.
+func SaveCampaign(c Campaign) (int64, error) {
+	if c.ID == 0 {
+		res, err := config.DB.Exec(`
+			INSERT INTO bonus_campaigns (code, name, kind, active, match_percent, max_bonus, min_deposit, min_bets, free_spins,
+				spin_value, cashback_percent, wagering_multiplier, valid_days, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))`,
+			c.Code, c.Name, c.Kind, c.Active, c.MatchPercent, c.MaxBonus, c.MinDeposit, c.MinBets, c.FreeSpins,
+			c.SpinValue, c.CashbackPercent, c.WageringMultiplier, c.ValidDays)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+	res, err := config.DB.Exec(`
+		UPDATE bonus_campaigns SET code = ?, name = ?, kind = ?, active = ?, match_percent = ?, max_bonus = ?,
+			min_deposit = ?, min_bets = ?, free_spins = ?, spin_value = ?, cashback_percent = ?, wagering_multiplier = ?,
+			valid_days = ?, updated_at = datetime('now')
+		WHERE id = ?`,
+		c.Code, c.Name, c.Kind, c.Active, c.MatchPercent, c.MaxBonus, c.MinDeposit, c.MinBets, c.FreeSpins,
+		c.SpinValue, c.CashbackPercent, c.WageringMultiplier, c.ValidDays, c.ID)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, ErrCampaignNotFound
+	}
+	return c.ID, nil
+}
+
+const bonusColumns = `b.id, b.user_id, b.campaign_id, c.code, c.kind, b.status, b.granted_amount, b.balance,
+	b.wagering_required, b.wagered, b.free_spins_remaining, b.spin_value, CAST(b.expires_at AS TEXT),
+	CAST(b.created_at AS TEXT), CAST(b.finished_at AS TEXT), c.wagering_multiplier`
+
+const bonusFrom = ` FROM user_bonuses b JOIN bonus_campaigns c ON c.id = b.campaign_id `
+
+func scanBonus(row interface{ Scan(dest ...any) error }) (UserBonus, error) {
+	var b UserBonus
+	var finished sql.NullString
+	err := row.Scan(&b.ID, &b.UserID, &b.CampaignID, &b.CampaignCode, &b.Kind, &b.Status, &b.GrantedAmount, &b.Balance,
+		&b.WageringRequired, &b.Wagered, &b.FreeSpinsRemaining, &b.SpinValue, &b.ExpiresAt, &b.CreatedAt, &finished,
+		&b.multiplier)
+	if finished.Valid {
+		b.FinishedAt = &finished.String
+	}
+	return b, err
+}
+
+func listBonuses(db dbtx, where string, args ...any) ([]UserBonus, error) {
+	rows, err := db.Query(`SELECT `+bonusColumns+bonusFrom+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []UserBonus{}
+	for rows.Next() {
+		b, err := scanBonus(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// GetUserBonuses lista os bônus do jogador, os mais recentes primeiro.
+func GetUserBonuses(userID int64) ([]UserBonus, error) {
+	return listBonuses(config.DB, `WHERE b.user_id = ? ORDER BY b.created_at DESC, b.id DESC`, userID)
+}
+
+// activeBonuses devolve os bônus ativos e válidos, os mais antigos primeiro
+// (ordem em que recebem as contribuições do rollover).
+func activeBonuses(db dbtx, userID int64) ([]UserBonus, error) {
+	return listBonuses(db, `WHERE b.user_id = ? AND b.status = 'active' AND b.expires_at > datetime('now') ORDER BY b.id`, userID)
+}
+
+func getBonus(db dbtx, id int64) (UserBonus, error) {
+	b, err := scanBonus(db.QueryRow(`SELECT `+bonusColumns+bonusFrom+`WHERE b.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return b, ErrBonusNotFound
+	}
+	return b, err
+}
+
+// insertBonus concede o bônus. Devolve false se a campanha já tinha sido
+// concedida ao jogador com a mesma referência.
+func insertBonus(db dbtx, b UserBonus, validDays int) (int64, bool, error) {
+	res, err := db.Exec(`
+		INSERT OR IGNORE INTO user_bonuses (user_id, campaign_id, reference, status, granted_amount, balance,
+			wagering_required, free_spins_remaining, spin_value, expires_at, created_at)
+		VALUES (?, ?, ?, 'active', ?, ?, ?, ?, ?, datetime('now', ?), datetime('now'))`,
+		b.UserID, b.CampaignID, b.ref, b.GrantedAmount, b.Balance, b.WageringRequired,
+		b.FreeSpinsRemaining, b.SpinValue, daysModifier(validDays))
+	if err != nil {
+		return 0, false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, false, nil
+	}
+	id, err := res.LastInsertId()
+	return id, true, err
+}
+
+// weight devolve o peso do jogo no rollover; jogos não cadastrados não contam.
+func weight(db dbtx, game string) (float64, error) {
+	var w float64
+	err := db.QueryRow(`SELECT weight FROM bonus_game_weights WHERE game = ?`, game).Scan(&w)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return w, err
+}
+
+// GameWeight é o peso de um jogo no rollover.
+type GameWeight struct {
+	Game   string  `json:"game"`
+	Weight float64 `json:"weight"`
+}
+
+// GetGameWeights lista os pesos dos jogos.
+func GetGameWeights() ([]GameWeight, error) {
+	rows, err := config.DB.Query(`SELECT game, weight FROM bonus_game_weights ORDER BY game`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []GameWeight{}
+	for rows.Next() {
+		var g GameWeight
+		if err := rows.Scan(&g.Game, &g.Weight); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+// SetGameWeight define o peso do jogo no rollover.
+func SetGameWeight(game string, w float64) error {
+	_, err := config.DB.Exec(`
+		INSERT INTO bonus_game_weights (game, weight) VALUES (?, ?)
+		ON CONFLICT (game) DO UPDATE SET weight = excluded.weight`, game, w)
+	return err
+}
@@ -0,0 +1,570 @@
+package bonus
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/wallet"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	KindDepositMatch = "deposit_match"
+	KindFreeSpins    = "free_spins"
+	KindCashback     = "cashback"
+	KindReferral     = "referral"
+	KindAchievement  = "achievement"
+)
+
+const (
+	StatusActive    = "active"
+	StatusCompleted = "completed"
+	StatusForfeited = "forfeited"
+	StatusExpired   = "expired"
+)
+
+// minCashback é o menor cashback concedido; valores abaixo são ignorados.
+const minCashback = 1.0
+
+var (
+	ErrCampaignNotFound = errors.New("bonus campaign not found")
+	ErrBonusNotFound    = errors.New("bonus not found")
+	ErrNotClaimable     = errors.New("campaign cannot be claimed")
+	ErrAlreadyClaimed   = errors.New("campaign already claimed")
+	ErrNoFreeSpins      = errors.New("no free spins available")
+	ErrInvalidCampaign  = errors.New("invalid campaign")
+)
+
+// Summary é o saldo de bônus do jogador.
+type Summary struct {
+	BonusBalance float64 `json:"bonus_balance"`
+	FreeSpins    int     `json:"free_spins"`
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func daysModifier(days int) string {
+	return "+" + strconv.Itoa(days) + " days"
+}
+
+// ValidateCampaign confere os campos obrigatórios de cada tipo de campanha.
+func ValidateCampaign(c Campaign) error {
+	if c.Code == "" || c.Name == "" || c.WageringMultiplier < 0 || c.ValidDays <= 0 {
+		return ErrInvalidCampaign
+	}
+	switch c.Kind {
+	case KindDepositMatch:
+		if c.MatchPercent <= 0 || c.MaxBonus <= 0 {
+			return ErrInvalidCampaign
+		}
+	case KindFreeSpins:
+		if c.FreeSpins <= 0 || c.SpinValue <= 0 {
+			return ErrInvalidCampaign
+		}
+	case KindCashback:
+		if c.CashbackPercent <= 0 || c.CashbackPercent > 100 {
+			return ErrInvalidCampaign
+		}
+	case KindReferral:
+		if c.MaxBonus <= 0 || c.MinBets < 0 || c.MinDeposit < 0 {
+			return ErrInvalidCampaign
+		}
+	case KindAchievement:
+		if c.MaxBonus <= 0 {
+			return ErrInvalidCampaign
+		}
+	default:
+		return ErrInvalidCampaign
+	}
+	return nil
+}
+
+// GetSummary soma o saldo de bônus e os giros grátis ativos do jogador.
+func GetSummary(userID int64) (Summary, error) {
+	var s Summary
+	err := config.DB.QueryRow(`
+		SELECT COALESCE(SUM(balance), 0), COALESCE(SUM(free_spins_remaining), 0)
+		FROM user_bonuses WHERE user_id = ? AND status = 'active' AND expires_at > datetime('now')`, userID).
+		Scan(&s.BonusBalance, &s.FreeSpins)
+	s.BonusBalance = round2(s.BonusBalance)
+	return s, err
+}
+
+// HasActive indica se o jogador tem algum bônus em andamento.
+func HasActive(userID int64) (bool, error) {
+	var active bool
+	err := config.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM user_bonuses WHERE user_id = ? AND status = 'active')`, userID).Scan(&active)
+	return active, err
+}
+
+// checkEligible recusa bônus para contas em pausa ou autoexcluídas,
+// devolvendo *responsible_gaming.ExclusionError.
+func checkEligible(userID int64) error {
+	ex, err := responsible_gaming.GetActiveExclusion(userID)
+	if err != nil {
+		return err
+	}
+	if ex != nil {
+		return &responsible_gaming.ExclusionError{Kind: ex.Kind, EndsAt: ex.EndsAt}
+	}
+	return nil
+}
+
+// grantTx concede o bônus e registra a auditoria.
+func grantTx(tx *sql.Tx, c Campaign, b UserBonus) (*UserBonus, error) {
+	b.CampaignID = c.ID
+	id, ok, err := insertBonus(tx, b, c.ValidDays)
+	if err != nil || !ok {
+		return nil, err
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      audit.System,
+		Action:     "bonus.granted",
+		TargetType: "user",
+		TargetID:   b.UserID,
+		Details: map[string]any{"bonus_id": id, "campaign": c.Code, "amount": b.GrantedAmount,
+			"free_spins": b.FreeSpinsRemaining, "wagering_required": b.WageringRequired},
+	})
+	if err != nil {
+		return nil, err
+	}
+	granted, err := getBonus(tx, id)
+	return &granted, err
+}
+
+// GrantDepositMatchTx concede o bônus de primeiro depósito, dentro da
+// transação que creditou o depósito. Só vale se este for o primeiro depósito
+// do jogador e ele não estiver autoexcluído.
+func GrantDepositMatchTx(tx *sql.Tx, userID int64, deposit float64) (*UserBonus, error) {
+	var deposits int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM transactions WHERE user_id = ? AND type = 'deposit'`, userID).Scan(&deposits); err != nil {
+		return nil, err
+	}
+	if deposits != 1 {
+		return nil, nil
+	}
+	campaigns, err := listCampaigns(tx, `WHERE active = 1 AND kind = ? AND min_deposit <= ?`, KindDepositMatch, deposit)
+	if err != nil || len(campaigns) == 0 {
+		return nil, err
+	}
+	c := campaigns[0]
+	amount := round2(math.Min(deposit*c.MatchPercent/100, c.MaxBonus))
+	if amount <= 0 {
+		return nil, nil
+	}
+	return grantTx(tx, c, UserBonus{
+		UserID:           userID,
+		GrantedAmount:    amount,
+		Balance:          amount,
+		WageringRequired: round2(amount * c.WageringMultiplier),
+	})
+}
+
+// GetReferralCampaign devolve a campanha de indicação ativa, ou nil se não houver.
+func GetReferralCampaign() (*Campaign, error) {
+	campaigns, err := listCampaigns(config.DB, `WHERE active = 1 AND kind = ?`, KindReferral)
+	if err != nil || len(campaigns) == 0 {
+		return nil, err
+	}
+	return &campaigns[0], nil
+}
+
+// GrantReferralTx concede ao padrinho o bônus de indicação (max_bonus da
+// campanha) pelo convidado informado. Devolve nil se já foi concedido.
+func GrantReferralTx(tx *sql.Tx, c Campaign, referrerID, refereeID int64) (*UserBonus, error) {
+	return GrantRewardTx(tx, c, referrerID, "referee:"+strconv.FormatInt(refereeID, 10))
+}
+
+// GrantRewardTx concede a campanha como prêmio: os giros de uma campanha
+// free_spins ou o valor fixo (max_bonus) de uma campanha referral ou
+// achievement. reference evita pagar duas vezes o mesmo prêmio; devolve nil
+// nesse caso.
+func GrantRewardTx(tx *sql.Tx, c Campaign, userID int64, reference string) (*UserBonus, error) {
+	b := UserBonus{UserID: userID, ref: reference}
+	switch c.Kind {
+	case KindFreeSpins:
+		b.FreeSpinsRemaining = c.FreeSpins
+		b.SpinValue = c.SpinValue
+	case KindReferral, KindAchievement:
+		b.GrantedAmount = c.MaxBonus
+		b.Balance = c.MaxBonus
+		b.WageringRequired = round2(c.MaxBonus * c.WageringMultiplier)
+	default:
+		return nil, ErrNotClaimable
+	}
+	return grantTx(tx, c, b)
+}
+
+// Claim resgata uma campanha de giros grátis pelo código. Depósito em dobro,
+// cashback e indicação são concedidos automaticamente.
+func Claim(userID int64, code string) (*UserBonus, error) {
+	c, err := getCampaignByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Active || c.Kind != KindFreeSpins {
+		return nil, ErrNotClaimable
+	}
+	if err := checkEligible(userID); err != nil {
+		return nil, err
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	b, err := grantTx(tx, c, UserBonus{
+		UserID:             userID,
+		FreeSpinsRemaining: c.FreeSpins,
+		SpinValue:          c.SpinValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrAlreadyClaimed
+	}
+	return b, tx.Commit()
+}
+
+// FreeSpin é um giro grátis reservado para uma jogada.
+type FreeSpin struct {
+	BonusID int64
+	Value   float64
+}
+
+// UseFreeSpin consome um giro grátis do bônus mais antigo que ainda tenha giros.
+func UseFreeSpin(userID int64) (FreeSpin, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return FreeSpin{}, err
+	}
+	defer tx.Rollback()
+	var spin FreeSpin
+	err = tx.QueryRow(`
+		SELECT id, spin_value FROM user_bonuses
+		WHERE user_id = ? AND status = 'active' AND free_spins_remaining > 0 AND expires_at > datetime('now')
+		ORDER BY id LIMIT 1`, userID).Scan(&spin.BonusID, &spin.Value)
+	if err == sql.ErrNoRows {
+		return FreeSpin{}, ErrNoFreeSpins
+	}
+	if err != nil {
+		return FreeSpin{}, err
+	}
+	res, err := tx.Exec(`
+		UPDATE user_bonuses SET free_spins_remaining = free_spins_remaining - 1
+		WHERE id = ? AND free_spins_remaining > 0`, spin.BonusID)
+	if err != nil {
+		return FreeSpin{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return FreeSpin{}, ErrNoFreeSpins
+	}
+	return spin, tx.Commit()
+}
+
+// SettleFreeSpin credita o ganho do giro grátis no saldo de bônus, somando o
+// rollover correspondente.
+func SettleFreeSpin(spin FreeSpin, winAmount float64) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	b, err := getBonus(tx, spin.BonusID)
+	if err != nil {
+		return err
+	}
+	if b.Status != StatusActive {
+		return nil
+	}
+	if winAmount > 0 {
+		_, err = tx.Exec(`
+			UPDATE user_bonuses SET granted_amount = granted_amount + ?, balance = balance + ?,
+				wagering_required = wagering_required + ?
+			WHERE id = ?`, winAmount, winAmount, round2(winAmount*b.multiplier), b.ID)
+		if err != nil {
+			return err
+		}
+	}
+	converted, err := completeIfDoneTx(tx, b.ID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	notifyConverted(b.UserID, converted)
+	return nil
+}
+
+// RecordWager registra uma aposta com dinheiro real no rollover dos bônus
+// ativos. A contribuição (valor apostado vezes o peso do jogo) vai primeiro
+// para o bônus mais antigo; o que sobrar segue para o próximo.
+func RecordWager(userID int64, game string, stake float64) error {
+	if stake <= 0 {
+		return nil
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	bonuses, err := activeBonuses(tx, userID)
+	if err != nil || len(bonuses) == 0 {
+		return err
+	}
+	w, err := weight(tx, game)
+	if err != nil {
+		return err
+	}
+	left := stake * w
+	converted := 0.0
+	for _, b := range bonuses {
+		if left <= 0 {
+			break
+		}
+		if b.Balance <= 0 {
+			continue
+		}
+		contribution := math.Min(left, math.Max(b.WageringRequired-b.Wagered, 0))
+		if contribution <= 0 && b.FreeSpinsRemaining > 0 {
+			continue
+		}
+		left -= contribution
+		if _, err := tx.Exec(`UPDATE user_bonuses SET wagered = wagered + ? WHERE id = ?`, contribution, b.ID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO bonus_wagers (user_bonus_id, user_id, game, stake, weight, contribution, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, datetime('now'))`, b.ID, userID, game, stake, w, contribution)
+		if err != nil {
+			return err
+		}
+		amount, err := completeIfDoneTx(tx, b.ID)
+		if err != nil {
+			return err
+		}
+		converted += amount
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	notifyConverted(userID, converted)
+	return nil
+}
+
+// TrackWager chama RecordWager e apenas loga erros, para não recusar uma
+// aposta já liquidada.
+func TrackWager(userID int64, game string, stake float64) {
+	if err := RecordWager(userID, game, stake); err != nil {
+		log.Printf("Erro ao registrar rollover de bônus do usuário %d: %v", userID, err)
+	}
+}
+
+// completeIfDoneTx converte o bônus em saldo real quando o rollover foi
+// cumprido e não restam giros grátis. Bônus sem saldo e sem giros são apenas
+// encerrados. Devolve o valor convertido, para o aviso após o commit.
+func completeIfDoneTx(tx *sql.Tx, id int64) (float64, error) {
+	b, err := getBonus(tx, id)
+	if err != nil {
+		return 0, err
+	}
+	if b.Status != StatusActive || b.FreeSpinsRemaining > 0 {
+		return 0, nil
+	}
+	if b.Balance > 0 && b.Wagered+0.005 < b.WageringRequired {
+		return 0, nil
+	}
+	res, err := tx.Exec(`
+		UPDATE user_bonuses SET status = 'completed', balance = 0, finished_at = datetime('now')
+		WHERE id = ? AND status = 'active'`, id)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 || b.Balance <= 0 {
+		return 0, nil
+	}
+	amount := round2(b.Balance)
+	if _, err := wallet.Credit(tx, b.UserID, amount, "bonus", fmt.Sprintf("Bônus %s #%d convertido", b.CampaignCode, b.ID)); err != nil {
+		return 0, err
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      audit.System,
+		Action:     "bonus.converted",
+		TargetType: "user",
+		TargetID:   b.UserID,
+		Details:    map[string]any{"bonus_id": b.ID, "campaign": b.CampaignCode, "amount": amount},
+	})
+	return amount, err
+}
+
+func notifyConverted(userID int64, amount float64) {
+	if amount > 0 {
+		notifications.Notify(userID, "bonus", "Bônus liberado",
+			fmt.Sprintf("Você cumpriu o rollover e R$ %.2f de bônus viraram saldo real.", amount))
+	}
+}
+
+// ForfeitActiveTx cancela os bônus em andamento do jogador (por exemplo, ao
+// sacar antes de cumprir o rollover). Devolve o saldo de bônus perdido.
+func ForfeitActiveTx(tx *sql.Tx, actor audit.Actor, userID int64, reason string) (float64, error) {
+	bonuses, err := listBonuses(tx, `WHERE b.user_id = ? AND b.status = 'active'`, userID)
+	if err != nil || len(bonuses) == 0 {
+		return 0, err
+	}
+	total := 0.0
+	ids := []int64{}
+	for _, b := range bonuses {
+		total += b.Balance
+		ids = append(ids, b.ID)
+	}
+	_, err = tx.Exec(`
+		UPDATE user_bonuses SET status = 'forfeited', balance = 0, free_spins_remaining = 0, finished_at = datetime('now')
+		WHERE user_id = ? AND status = 'active'`, userID)
+	if err != nil {
+		return 0, err
+	}
+	total = round2(total)
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     "bonus.forfeited",
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    map[string]any{"bonus_ids": ids, "amount": total, "reason": reason},
+	})
+	return total, err
+}
+
+// ExpireBonuses encerra os bônus vencidos; o saldo de bônus restante é perdido.
+func ExpireBonuses() (int64, error) {
+	res, err := config.DB.Exec(`
+		UPDATE user_bonuses SET status = 'expired', balance = 0, free_spins_remaining = 0, finished_at = datetime('now')
+		WHERE status = 'active' AND expires_at <= datetime('now')`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// cashbackWeek devolve o início e o fim (UTC) da última semana completa,
+// de segunda a segunda, e a referência usada para não conceder duas vezes.
+func cashbackWeek(now time.Time) (time.Time, time.Time, string) {
+	now = now.UTC()
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	end := time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	year, week := start.ISOWeek()
+	return start, end, fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// GrantCashback concede o cashback da última semana completa sobre a perda
+// líquida (apostas menos ganhos) de cada jogador. Pode rodar várias vezes: a
+// referência da semana impede concessões repetidas.
+func GrantCashback(now time.Time) (int, error) {
+	campaigns, err := listCampaigns(config.DB, `WHERE active = 1 AND kind = ?`, KindCashback)
+	if err != nil || len(campaigns) == 0 {
+		return 0, err
+	}
+	start, end, ref := cashbackWeek(now)
+	rows, err := config.DB.Query(`
+		SELECT user_id,
+			COALESCE(SUM(CASE WHEN type = 'bet' THEN ABS(amount) END), 0) -
+			COALESCE(SUM(CASE WHEN type = 'win' THEN ABS(amount) END), 0) AS net_loss
+		FROM transactions
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY user_id HAVING net_loss > 0`,
+		start.Format("2006-01-02 15:04:05"), end.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	type loss struct {
+		userID int64
+		amount float64
+	}
+	var losses []loss
+	for rows.Next() {
+		var l loss
+		if err := rows.Scan(&l.userID, &l.amount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		losses = append(losses, l)
+	}
+	rows.Close()
+
+	granted := 0
+	for _, c := range campaigns {
+		for _, l := range losses {
+			amount := round2(l.amount * c.CashbackPercent / 100)
+			if amount < minCashback {
+				continue
+			}
+			if err := checkEligible(l.userID); err != nil {
+				var exclusionErr *responsible_gaming.ExclusionError
+				if errors.As(err, &exclusionErr) {
+					continue
+				}
+				return granted, err
+			}
+			b, err := grantCashback(c, l.userID, amount, ref)
+			if err != nil {
+				return granted, err
+			}
+			if b != nil {
+				granted++
+				notifications.Notify(l.userID, "bonus", "Cashback semanal",
+					fmt.Sprintf("Você recebeu R$ %.2f de cashback em bônus.", amount))
+			}
+		}
+	}
+	return granted, nil
+}
+
+func grantCashback(c Campaign, userID int64, amount float64, ref string) (*UserBonus, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	b, err := grantTx(tx, c, UserBonus{
+		UserID:           userID,
+		GrantedAmount:    amount,
+		Balance:          amount,
+		WageringRequired: round2(amount * c.WageringMultiplier),
+		ref:              ref,
+	})
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return b, tx.Commit()
+}
+
+// StartWorker expira bônus vencidos e concede o cashback semanal.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := ExpireBonuses(); err != nil {
+				log.Printf("Erro ao expirar bônus: %v", err)
+			} else if n > 0 {
+				log.Printf("%d bônus expirado(s)", n)
+			}
+			if n, err := GrantCashback(time.Now()); err != nil {
+				log.Printf("Erro ao conceder cashback: %v", err)
+			} else if n > 0 {
+				log.Printf("Cashback concedido a %d jogador(es)", n)
+			}
+		}
+	}()
+}
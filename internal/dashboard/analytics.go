@@ -0,0 +1,241 @@
+package dashboard
+
+import (
+	"berry_bet/internal/users"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Granularidades aceitas pelas análises
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// Período padrão quando from/to não são informados
+	defaultAnalyticsDays = 30
+	// Limites de intervalo: diário até um ano, semanal/mensal até cinco anos
+	maxDailyRangeDays = 366
+	maxRangeDays      = 5 * 366
+)
+
+var (
+	ErrInvalidRange       = errors.New("intervalo inválido: use from/to no formato AAAA-MM-DD com from <= to")
+	ErrRangeTooLong       = errors.New("intervalo longo demais para a granularidade escolhida")
+	ErrInvalidGranularity = errors.New("granularity deve ser 'day', 'week' ou 'month'")
+)
+
+// GetAnalytics agrega o histórico de apostas do usuário no intervalo pedido,
+// em períodos de dia, semana (começando na segunda) ou mês no fuso do usuário.
+func (s *Service) GetAnalytics(userID int, q AnalyticsQuery) (*AnalyticsResponse, error) {
+	granularity := q.Granularity
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	if granularity != GranularityDay && granularity != GranularityWeek && granularity != GranularityMonth {
+		return nil, ErrInvalidGranularity
+	}
+
+	loc, err := users.GetLocation(int64(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := analyticsRange(q, loc)
+	if err != nil {
+		return nil, err
+	}
+	days := int(math.Round(to.Sub(from).Hours()/24)) + 1
+	if days > maxRangeDays || (granularity == GranularityDay && days > maxDailyRangeDays) {
+		return nil, ErrRangeTooLong
+	}
+
+	// Períodos vazios também aparecem, para o gráfico não ter buracos
+	var starts []time.Time
+	for p := periodStart(from, granularity); !p.After(to); p = nextPeriod(p, granularity) {
+		starts = append(starts, p)
+	}
+	index := make(map[string]int, len(starts))
+	for i, p := range starts {
+		index[p.Format(dateLayout)] = i
+	}
+	newSeries := func() []AnalyticsPoint {
+		series := make([]AnalyticsPoint, len(starts))
+		for i, p := range starts {
+			end := nextPeriod(p, granularity).AddDate(0, 0, -1)
+			if p.Before(from) {
+				p = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			series[i] = AnalyticsPoint{Period: starts[i].Format(dateLayout), From: p.Format(dateLayout), To: end.Format(dateLayout)}
+		}
+		return series
+	}
+
+	query := `
+		SELECT game_type, bet_amount, COALESCE(win_amount, 0), profit_loss, result, CAST(created_at AS TEXT)
+		FROM bet_history
+		WHERE user_id = ? AND datetime(created_at) >= ? AND datetime(created_at) < ?`
+	args := []any{userID, from.UTC().Format(dbTimeLayout), to.AddDate(0, 0, 1).UTC().Format(dbTimeLayout)}
+	if q.GameType != "" {
+		query += ` AND game_type = ?`
+		args = append(args, q.GameType)
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp := &AnalyticsResponse{
+		From:        from.Format(dateLayout),
+		To:          to.Format(dateLayout),
+		Granularity: granularity,
+		Timezone:    loc.String(),
+		GameType:    q.GameType,
+		Series:      newSeries(),
+	}
+	games := map[string]*GameAnalytics{}
+	for rows.Next() {
+		var gameType, result, createdAt string
+		var betAmount, winAmount, profitLoss float64
+		if err := rows.Scan(&gameType, &betAmount, &winAmount, &profitLoss, &result, &createdAt); err != nil {
+			return nil, err
+		}
+		at := parseDBTime(createdAt).In(loc)
+		i, ok := index[periodStart(at, granularity).Format(dateLayout)]
+		if !ok {
+			continue
+		}
+
+		g, ok := games[gameType]
+		if !ok {
+			g = &GameAnalytics{GameType: gameType, Series: newSeries()}
+			games[gameType] = g
+		}
+		resp.Totals.add(betAmount, winAmount, profitLoss, result)
+		resp.Series[i].add(betAmount, winAmount, profitLoss, result)
+		g.Totals.add(betAmount, winAmount, profitLoss, result)
+		g.Series[i].add(betAmount, winAmount, profitLoss, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	resp.Totals.finish()
+	accumulate(resp.Series)
+	resp.Games = make([]GameAnalytics, 0, len(games))
+	for _, g := range games {
+		g.Totals.finish()
+		accumulate(g.Series)
+		resp.Games = append(resp.Games, *g)
+	}
+	sort.Slice(resp.Games, func(i, j int) bool { return resp.Games[i].GameType < resp.Games[j].GameType })
+	return resp, nil
+}
+
+// analyticsRange interpreta from/to (datas locais do usuário). Sem from, usa
+// os últimos LastDays (ou defaultAnalyticsDays) dias até to; sem to, até hoje.
+func analyticsRange(q AnalyticsQuery, loc *time.Location) (time.Time, time.Time, error) {
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if q.To != "" {
+		t, err := time.ParseInLocation(dateLayout, q.To, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		to = t
+	}
+	days := q.LastDays
+	if days <= 0 {
+		days = defaultAnalyticsDays
+	}
+	from := to.AddDate(0, 0, -(days - 1))
+	if q.From != "" {
+		f, err := time.ParseInLocation(dateLayout, q.From, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		from = f
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	return from, to, nil
+}
+
+// periodStart devolve o início (meia-noite local) do período que contém t
+func periodStart(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch granularity {
+	case GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // segunda = 0
+		return day.AddDate(0, 0, -offset)
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+func nextPeriod(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func (b *BetCounters) add(betAmount, winAmount, profitLoss float64, result string) {
+	b.Bets++
+	switch result {
+	case "win":
+		b.Wins++
+	case "loss":
+		b.Losses++
+	}
+	b.Wagered += betAmount
+	b.Won += winAmount
+	b.Profit += profitLoss
+}
+
+func (b *BetCounters) round() {
+	b.Wagered = roundCents(b.Wagered)
+	b.Won = roundCents(b.Won)
+	b.Profit = roundCents(b.Profit)
+}
+
+func (t *AnalyticsTotals) finish() {
+	t.round()
+	if t.Bets > 0 {
+		t.WinRate = roundCents(float64(t.Wins) / float64(t.Bets) * 100)
+	}
+	if t.Wagered > 0 {
+		t.ROI = roundCents(t.Profit / t.Wagered * 100)
+	}
+}
+
+// accumulate arredonda os valores de cada período e preenche os acumulados
+func accumulate(series []AnalyticsPoint) {
+	var profit, wagered float64
+	for i := range series {
+		profit += series[i].Profit
+		wagered += series[i].Wagered
+		series[i].round()
+		series[i].CumulativeProfit = roundCents(profit)
+		series[i].CumulativeWagered = roundCents(wagered)
+	}
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
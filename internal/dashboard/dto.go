@@ -15,6 +15,12 @@ type RecordBetRequest struct {
 	Details    string  `json:"details"`
 }
 
+// AdminRecordBetRequest é o lançamento manual de uma aposta por um administrador
+type AdminRecordBetRequest struct {
+	UserID int64 `json:"user_id"`
+	RecordBetRequest
+}
+
 // DashboardResponse representa a resposta do dashboard
 type DashboardResponse struct {
 	TotalBets       int                 `json:"total_bets"`
@@ -61,6 +67,63 @@ type WeeklyDataResponse struct {
 	Bets   int     `json:"bets"`
 }
 
+// AnalyticsQuery são os filtros de GET /api/dashboard/analytics. from/to são
+// datas (AAAA-MM-DD) no fuso do usuário.
+type AnalyticsQuery struct {
+	From        string `form:"from"`
+	To          string `form:"to"`
+	Granularity string `form:"granularity"`
+	GameType    string `form:"game_type"`
+	// LastDays substitui o período padrão quando from não é informado
+	LastDays int `form:"-"`
+}
+
+// BetCounters são os contadores comuns aos totais e a cada período
+type BetCounters struct {
+	Bets    int     `json:"bets"`
+	Wins    int     `json:"wins"`
+	Losses  int     `json:"losses"`
+	Wagered float64 `json:"wagered"`
+	Won     float64 `json:"won"`
+	Profit  float64 `json:"profit"`
+}
+
+// AnalyticsTotals resume um intervalo inteiro
+type AnalyticsTotals struct {
+	BetCounters
+	WinRate float64 `json:"win_rate"`
+	ROI     float64 `json:"roi"`
+}
+
+// AnalyticsPoint é um período (dia, semana ou mês) da série temporal
+type AnalyticsPoint struct {
+	Period string `json:"period"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	BetCounters
+	CumulativeProfit  float64 `json:"cumulative_profit"`
+	CumulativeWagered float64 `json:"cumulative_wagered"`
+}
+
+// GameAnalytics é o recorte de um jogo no mesmo intervalo
+type GameAnalytics struct {
+	GameType string           `json:"game_type"`
+	Totals   AnalyticsTotals  `json:"totals"`
+	Series   []AnalyticsPoint `json:"series"`
+}
+
+// AnalyticsResponse representa a resposta das análises do dashboard
+type AnalyticsResponse struct {
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Granularity string           `json:"granularity"`
+	Timezone    string           `json:"timezone"`
+	GameType    string           `json:"game_type,omitempty"`
+	Totals      AnalyticsTotals  `json:"totals"`
+	Series      []AnalyticsPoint `json:"series"`
+	Games       []GameAnalytics  `json:"games"`
+}
+
 // ActivityResponse representa uma atividade recente
 type ActivityResponse struct {
 	Type        string    `json:"type"`
@@ -1,9 +1,11 @@
 package dashboard
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"berry_bet/internal/audit"
 	"berry_bet/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -119,8 +121,9 @@ func (h *Handler) GetRecentActivity(c *gin.Context) {
 	utils.RespondSuccess(c, activities, "Atividade recente carregada com sucesso")
 }
 
-// GetGameStats retorna estatísticas por jogo
-func (h *Handler) GetGameStats(c *gin.Context) {
+// GetAnalytics retorna a série temporal do usuário em um intervalo
+// (?from=&to=&granularity=day|week|month&game_type=), no fuso dele
+func (h *Handler) GetAnalytics(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		utils.RespondError(c, http.StatusUnauthorized, "AUTH_REQUIRED", "Usuário não autenticado", nil)
@@ -133,18 +136,30 @@ func (h *Handler) GetGameStats(c *gin.Context) {
 		return
 	}
 
-	// Buscar dados do dashboard que incluem game_stats
-	dashboard, err := h.service.GetDashboardData(int(uid))
-	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "GAME_STATS_ERROR", "Erro ao buscar estatísticas por jogo", err.Error())
+	var q AnalyticsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Parâmetros inválidos", err.Error())
 		return
 	}
 
-	utils.RespondSuccess(c, dashboard.GameStats, "Estatísticas por jogo carregadas com sucesso")
+	analytics, err := h.service.GetAnalytics(int(uid), q)
+	switch {
+	case errors.Is(err, ErrInvalidGranularity):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_GRANULARITY", "Granularidade inválida", err.Error())
+		return
+	case errors.Is(err, ErrInvalidRange), errors.Is(err, ErrRangeTooLong):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_RANGE", "Intervalo inválido", err.Error())
+		return
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "ANALYTICS_ERROR", "Erro ao calcular as análises", err.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, analytics, "Análises carregadas com sucesso")
 }
 
-// RecordBet registra uma nova aposta no histórico
-func (h *Handler) RecordBet(c *gin.Context) {
+// GetGameStats retorna estatísticas por jogo
+func (h *Handler) GetGameStats(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		utils.RespondError(c, http.StatusUnauthorized, "AUTH_REQUIRED", "Usuário não autenticado", nil)
@@ -157,11 +172,27 @@ func (h *Handler) RecordBet(c *gin.Context) {
 		return
 	}
 
-	var req RecordBetRequest
+	// Buscar dados do dashboard que incluem game_stats
+	dashboard, err := h.service.GetDashboardData(int(uid))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "GAME_STATS_ERROR", "Erro ao buscar estatísticas por jogo", err.Error())
+		return
+	}
+
+	utils.RespondSuccess(c, dashboard.GameStats, "Estatísticas por jogo carregadas com sucesso")
+}
+
+// RecordBet lança manualmente uma aposta no dashboard de um usuário (somente admin)
+func (h *Handler) RecordBet(c *gin.Context) {
+	var req AdminRecordBetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Dados inválidos", err.Error())
 		return
 	}
+	if req.UserID <= 0 {
+		utils.RespondError(c, http.StatusBadRequest, "VALIDATION_ERROR", "Dados inválidos", "user_id é obrigatório")
+		return
+	}
 
 	// Validar dados
 	if err := req.Validate(); err != nil {
@@ -170,11 +201,17 @@ func (h *Handler) RecordBet(c *gin.Context) {
 	}
 
 	// Registrar aposta
-	err := h.service.RecordBet(int(uid), &req)
+	err := h.service.RecordBet(int(req.UserID), &req.RecordBetRequest)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "RECORD_BET_ERROR", "Erro ao registrar aposta", err.Error())
 		return
 	}
 
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "dashboard.record_bet",
+		TargetType: "user",
+		TargetID:   req.UserID,
+		After:      req.RecordBetRequest,
+	})
 	utils.RespondSuccess(c, nil, "Aposta registrada com sucesso")
 }
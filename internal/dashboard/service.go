@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"berry_bet/internal/achievements"
 	"database/sql"
 	"fmt"
 	"time"
@@ -30,24 +31,38 @@ func (s *Service) RecordBet(userID int, req *RecordBetRequest) error {
 	}
 	defer tx.Rollback()
 
+	if err := s.recordBetTx(tx, userID, req, time.Now()); err != nil {
+		return err
+	}
+
+	// Commit da transação
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Conquistas são avaliadas depois de cada aposta registrada
+	achievements.TrackBet(int64(userID))
+	return nil
+}
+
+// recordBetTx grava a aposta no histórico, nas estatísticas por jogo e nas
+// métricas diárias usando o instante informado (o backfill preserva a data
+// original das apostas)
+func (s *Service) recordBetTx(tx *sql.Tx, userID int, req *RecordBetRequest, at time.Time) error {
 	// Criar registro no histórico de apostas
 	history := req.ToBetHistory(userID)
+	history.CreatedAt = at
 	if err := s.createBetHistoryTx(tx, history); err != nil {
 		return err
 	}
 
 	// Atualizar estatísticas por jogo
-	if err := s.updateGameStatsTx(tx, userID, req.GameType, req.BetAmount, req.ProfitLoss, req.Result); err != nil {
+	if err := s.updateGameStatsTx(tx, userID, req.GameType, req.BetAmount, req.ProfitLoss, req.Result, at); err != nil {
 		return err
 	}
 
 	// Atualizar métricas diárias
-	if err := s.updateDailyMetricsTx(tx, userID, req.BetAmount, req.ProfitLoss, req.Result); err != nil {
-		return err
-	}
-
-	// Commit da transação
-	return tx.Commit()
+	return s.updateDailyMetricsTx(tx, userID, req.BetAmount, req.ProfitLoss, req.Result, at)
 }
 
 // GetCompleteDashboard retorna o dashboard completo do usuário
@@ -177,32 +192,17 @@ func (s *Service) GetDashboardData(userID int) (*DashboardResponse, error) {
 	}, nil
 }
 
-// GetWeeklyData retorna os dados dos últimos 7 dias
+// GetWeeklyData retorna os dados dos últimos 7 dias no fuso do usuário
 func (s *Service) GetWeeklyData(userID int) ([]WeeklyDataResponse, error) {
-	query := `
-		SELECT date, COALESCE(total_profit, 0) as profit, COALESCE(bets_count, 0) as bets
-		FROM daily_metrics
-		WHERE user_id = ? AND date >= date('now', '-7 days')
-		ORDER BY date ASC
-	`
-
-	rows, err := s.db.Query(query, userID)
+	analytics, err := s.GetAnalytics(userID, AnalyticsQuery{Granularity: GranularityDay, LastDays: 7})
 	if err != nil {
-		// Retornar dados vazios se as tabelas não existirem
-		return []WeeklyDataResponse{}, nil
+		return nil, err
 	}
-	defer rows.Close()
 
-	var weeklyData []WeeklyDataResponse
-	for rows.Next() {
-		var data WeeklyDataResponse
-		err := rows.Scan(&data.Date, &data.Profit, &data.Bets)
-		if err != nil {
-			continue
-		}
-		weeklyData = append(weeklyData, data)
+	weeklyData := make([]WeeklyDataResponse, 0, len(analytics.Series))
+	for _, p := range analytics.Series {
+		weeklyData = append(weeklyData, WeeklyDataResponse{Date: p.Period, Profit: p.Profit, Bets: p.Bets})
 	}
-
 	return weeklyData, nil
 }
 
@@ -261,15 +261,15 @@ func (s *Service) GetRecentActivity(userID int, limit int) ([]ActivityResponse,
 
 func (s *Service) createBetHistoryTx(tx *sql.Tx, history *BetHistory) error {
 	query := `
-		INSERT INTO bet_history (user_id, game_type, bet_amount, win_amount, profit_loss, result, details)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO bet_history (user_id, game_type, bet_amount, win_amount, profit_loss, result, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := tx.Exec(query, history.UserID, history.GameType, history.BetAmount,
-		history.WinAmount, history.ProfitLoss, history.Result, history.Details)
+		history.WinAmount, history.ProfitLoss, history.Result, history.Details, history.CreatedAt.UTC().Format(dbTimeLayout))
 	return err
 }
 
-func (s *Service) updateGameStatsTx(tx *sql.Tx, userID int, gameType string, betAmount, profitLoss float64, result string) error {
+func (s *Service) updateGameStatsTx(tx *sql.Tx, userID int, gameType string, betAmount, profitLoss float64, result string, at time.Time) error {
 	// Verificar se já existe um registro
 	var exists bool
 	checkQuery := `SELECT EXISTS(SELECT 1 FROM game_stats WHERE user_id = ? AND game_type = ?)`
@@ -292,7 +292,7 @@ func (s *Service) updateGameStatsTx(tx *sql.Tx, userID int, gameType string, bet
 			INSERT INTO game_stats (user_id, game_type, total_bets, total_wins, total_losses,
 				total_amount_bet, total_profit, biggest_win, biggest_loss, current_streak,
 				best_win_streak, worst_loss_streak, last_played_at)
-			VALUES (?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			VALUES (?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`
 
 		streak := 0
@@ -316,7 +316,7 @@ func (s *Service) updateGameStatsTx(tx *sql.Tx, userID int, gameType string, bet
 		}
 
 		_, err = tx.Exec(insertQuery, userID, gameType, wins, losses, betAmount, profitLoss,
-			biggestWin, biggestLoss, streak, bestWin, worstLoss)
+			biggestWin, biggestLoss, streak, bestWin, worstLoss, at.UTC().Format(dbTimeLayout))
 		return err
 	}
 
@@ -343,7 +343,7 @@ func (s *Service) updateGameStatsTx(tx *sql.Tx, userID int, gameType string, bet
 			current_streak = ?,
 			best_win_streak = CASE WHEN ? > 0 AND ? > best_win_streak THEN ? ELSE best_win_streak END,
 			worst_loss_streak = CASE WHEN ? < 0 AND ? > worst_loss_streak THEN ? ELSE worst_loss_streak END,
-			last_played_at = CURRENT_TIMESTAMP
+			last_played_at = ?
 		WHERE user_id = ? AND game_type = ?
 	`
 
@@ -356,12 +356,12 @@ func (s *Service) updateGameStatsTx(tx *sql.Tx, userID int, gameType string, bet
 		profitLoss, profitLoss, profitLoss, profitLoss, newStreak,
 		newStreak, newStreak, newStreak,
 		newStreak, absNewStreak, absNewStreak,
-		userID, gameType)
+		at.UTC().Format(dbTimeLayout), userID, gameType)
 	return err
 }
 
-func (s *Service) updateDailyMetricsTx(tx *sql.Tx, userID int, betAmount, profitLoss float64, result string) error {
-	today := time.Now().Format("2006-01-02")
+func (s *Service) updateDailyMetricsTx(tx *sql.Tx, userID int, betAmount, profitLoss float64, result string, at time.Time) error {
+	today := at.Format("2006-01-02")
 
 	// Verificar se já existe um registro para hoje
 	var exists bool
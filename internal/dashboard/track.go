@@ -0,0 +1,225 @@
+package dashboard
+
+import (
+	"berry_bet/config"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+const dbTimeLayout = "2006-01-02 15:04:05"
+
+// Tipos de jogo registrados pelo servidor
+const (
+	GameRoleta = "roleta"
+	GameSports = "sports"
+)
+
+// RoletaBetRequest monta o registro de uma rodada de roleta liquidada.
+// win é o valor devolvido ao jogador (aposta + lucro) ou zero na derrota.
+func RoletaBetRequest(betValue, win float64, card string) RecordBetRequest {
+	req := RecordBetRequest{
+		GameType:   GameRoleta,
+		BetAmount:  betValue,
+		WinAmount:  win,
+		ProfitLoss: math.Round((win-betValue)*100) / 100,
+		Result:     "loss",
+		Details:    detailsJSON(map[string]any{"card": card}),
+	}
+	if win > 0 {
+		req.Result = "win"
+	}
+	return req
+}
+
+// SportsBetRequest monta o registro de uma aposta esportiva liquidada.
+// Retorna false quando o status não é final (won/lost).
+func SportsBetRequest(betID, gameID int64, amount, profitLoss float64, status string) (RecordBetRequest, bool) {
+	req := RecordBetRequest{
+		GameType:   GameSports,
+		BetAmount:  amount,
+		ProfitLoss: profitLoss,
+		Details:    detailsJSON(map[string]any{"bet_id": betID, "game_id": gameID}),
+	}
+	switch status {
+	case "won":
+		req.Result = "win"
+		req.WinAmount = amount + profitLoss
+	case "lost":
+		req.Result = "loss"
+	default:
+		return req, false
+	}
+	return req, true
+}
+
+// TrackBet registra no dashboard uma aposta liquidada pelo servidor.
+// Falhas são apenas registradas em log: o dashboard nunca invalida a aposta.
+func TrackBet(userID int64, req RecordBetRequest) {
+	if err := NewService(config.DB).RecordBet(int(userID), &req); err != nil {
+		log.Printf("Erro ao registrar aposta no dashboard (usuário %d, jogo %s): %v", userID, req.GameType, err)
+	}
+}
+
+// BackfillResult resume a reconstrução das tabelas do dashboard
+type BackfillResult struct {
+	RoletaBets int `json:"roleta_bets"`
+	SportsBets int `json:"sports_bets"`
+}
+
+// Backfill apaga bet_history, game_stats e daily_metrics e os reconstrói a
+// partir das transações da roleta e das apostas esportivas já liquidadas.
+// Conquistas não são reavaliadas aqui; isso acontece na próxima aposta.
+func (s *Service) Backfill() (BackfillResult, error) {
+	var res BackfillResult
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"bet_history", "game_stats", "daily_metrics"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return res, err
+		}
+	}
+
+	// Cada rodada da roleta gera uma transação "bet" e, quando há ganho, uma
+	// transação "win" logo em seguida para o mesmo usuário
+	rows, err := tx.Query(`
+		SELECT user_id, type, amount, COALESCE(description, ''), CAST(created_at AS TEXT)
+		FROM transactions
+		WHERE (type = 'bet' AND description LIKE 'Aposta na roleta%')
+		   OR (type = 'win' AND description LIKE 'Ganho na roleta%')
+		ORDER BY user_id, id`)
+	if err != nil {
+		return res, err
+	}
+	type round struct {
+		userID   int64
+		betValue float64
+		win      float64
+		card     string
+		at       time.Time
+	}
+	var rounds []round
+	var open *round
+	for rows.Next() {
+		var userID int64
+		var kind, description, createdAt string
+		var amount float64
+		if err := rows.Scan(&userID, &kind, &amount, &description, &createdAt); err != nil {
+			rows.Close()
+			return res, err
+		}
+		if kind == "bet" {
+			if open != nil {
+				rounds = append(rounds, *open)
+			}
+			open = &round{userID: userID, betValue: abs64(amount), card: "perca", at: parseDBTime(createdAt)}
+			continue
+		}
+		if open == nil || open.userID != userID {
+			continue
+		}
+		open.win = abs64(amount)
+		open.card = cardFromDescription(description)
+		rounds = append(rounds, *open)
+		open = nil
+	}
+	if open != nil {
+		rounds = append(rounds, *open)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	for _, r := range rounds {
+		req := RoletaBetRequest(r.betValue, r.win, r.card)
+		if err := s.recordBetTx(tx, int(r.userID), &req, r.at); err != nil {
+			return res, fmt.Errorf("roleta (usuário %d): %w", r.userID, err)
+		}
+		res.RoletaBets++
+	}
+
+	rows, err = tx.Query(`
+		SELECT id, user_id, game_id, amount, COALESCE(profit_loss, 0), bet_status, CAST(created_at AS TEXT)
+		FROM bets
+		WHERE bet_status IN ('won', 'lost')
+		ORDER BY created_at, id`)
+	if err != nil {
+		return res, err
+	}
+	type settled struct {
+		userID int64
+		req    RecordBetRequest
+		at     time.Time
+	}
+	var bets []settled
+	for rows.Next() {
+		var betID, userID, gameID int64
+		var amount, profitLoss float64
+		var status, createdAt string
+		if err := rows.Scan(&betID, &userID, &gameID, &amount, &profitLoss, &status, &createdAt); err != nil {
+			rows.Close()
+			return res, err
+		}
+		if req, ok := SportsBetRequest(betID, gameID, amount, profitLoss, status); ok {
+			bets = append(bets, settled{userID: userID, req: req, at: parseDBTime(createdAt)})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+
+	for _, b := range bets {
+		if err := s.recordBetTx(tx, int(b.userID), &b.req, b.at); err != nil {
+			return res, fmt.Errorf("aposta esportiva (usuário %d): %w", b.userID, err)
+		}
+		res.SportsBets++
+	}
+
+	return res, tx.Commit()
+}
+
+// cardFromDescription extrai a carta de "Ganho na roleta - Carta: X - Valor: ..."
+func cardFromDescription(description string) string {
+	_, rest, ok := strings.Cut(description, "Carta: ")
+	if !ok {
+		return ""
+	}
+	card, _, _ := strings.Cut(rest, " - ")
+	return strings.TrimSpace(card)
+}
+
+// parseDBTime interpreta timestamps do SQLite (UTC) e devolve no fuso local,
+// que é o usado para agrupar as métricas diárias
+func parseDBTime(value string) time.Time {
+	for _, layout := range []string{dbTimeLayout, time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t.Local()
+		}
+	}
+	return time.Now()
+}
+
+func detailsJSON(v map[string]any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func abs64(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 )
 
+// ErrInvalidGameStatus indica que o jogo não existe ou não está no status exigido pela operação.
+var ErrInvalidGameStatus = errors.New("game not found or not in the required status")
+
 type Game struct {
 	ID              int64  `json:"id"`
 	GameName        string `json:"game_name"`
@@ -238,7 +241,7 @@ func StartGame(gameID int64) error {
 		}
 	}()
 
-	_, err = tx.Exec(`
+	res, err := tx.Exec(`
 		UPDATE games 
 		SET game_status = 'active', start_time = datetime('now') 
 		WHERE id = ? AND game_status = 'scheduled'`, gameID)
@@ -246,6 +249,10 @@ func StartGame(gameID int64) error {
 	if err != nil {
 		return err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = ErrInvalidGameStatus
+		return err
+	}
 
 	return tx.Commit()
 }
@@ -262,7 +269,7 @@ func EndGame(gameID int64) error {
 		}
 	}()
 
-	_, err = tx.Exec(`
+	res, err := tx.Exec(`
 		UPDATE games 
 		SET game_status = 'finished', end_time = datetime('now') 
 		WHERE id = ? AND game_status = 'active'`, gameID)
@@ -270,6 +277,10 @@ func EndGame(gameID int64) error {
 	if err != nil {
 		return err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = ErrInvalidGameStatus
+		return err
+	}
 
 	return tx.Commit()
 }
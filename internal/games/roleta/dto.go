@@ -1,8 +1,14 @@
 package roleta
 
+import (
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/responsible_gaming"
+)
+
 type RoletaBetRequest struct {
 	UserID   int64   `json:"user_id"`
 	BetValue float64 `json:"valor_aposta"`
+	FreeSpin bool    `json:"free_spin"` // uses a free spin from a bonus instead of the balance
 }
 
 type RoletaBetResponse struct {
@@ -11,4 +17,8 @@ type RoletaBetResponse struct {
 	Card           string  `json:"card"`            // card matrix sent by the backend (agora string)
 	CurrentBalance float64 `json:"current_balance"` // user's updated balance
 	Message        string  `json:"message"`         // message to the user
+
+	Limits       []responsible_gaming.AllowanceResponse `json:"limits,omitempty"`        // remaining responsible-gaming allowance
+	RealityCheck *responsible_gaming.RealityCheck       `json:"reality_check,omitempty"` // present when the player's reality-check interval elapsed
+	Bonus        *bonus.Summary                         `json:"bonus,omitempty"`         // bonus balance and free spins left, when the player has any
 }
@@ -1,10 +1,17 @@
 package roleta
 
 import (
-	"berry_bet/internal/transactions"
+	"berry_bet/config"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/dashboard"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/tournaments"
 	"berry_bet/internal/user_stats"
 	"berry_bet/internal/utils"
+	"berry_bet/internal/wallet"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -49,6 +56,9 @@ func GetBetValueHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INSUFFICIENT_FUNDS", "User does not have enough balance to place this bet.", nil)
 		return
 	}
+	if !responsible_gaming.CheckBet(c, userID, betValue) {
+		return
+	}
 }
 
 func RoletaBetHandler(c *gin.Context) {
@@ -68,6 +78,14 @@ func RoletaBetHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
 		return
 	}
+	if req.FreeSpin {
+		freeSpinHandler(c, userID)
+		return
+	}
+	if req.BetValue <= 0 {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Bet value must be greater than zero.", nil)
+		return
+	}
 
 	// Busca dados do usuário
 	user, err := user_stats.GetUserStatsByID(fmt.Sprintf("%d", userID))
@@ -82,6 +100,11 @@ func RoletaBetHandler(c *gin.Context) {
 		return
 	}
 
+	// Limites de jogo responsável
+	if !responsible_gaming.CheckBet(c, userID, req.BetValue) {
+		return
+	}
+
 	// Executa a lógica da roleta
 	res := ExecutaRoleta(userID, req.BetValue)
 	if res == nil {
@@ -95,58 +118,76 @@ func RoletaBetHandler(c *gin.Context) {
 		return
 	}
 
-	// Atualiza estatísticas do usuário
 	isWin := roletaRes.CartinhaSorteada != "perca"
+	winAmount := 0.0
+	profit := 0.0
+	if isWin {
+		winAmount = roletaRes.Lucro + req.BetValue // Retorna a aposta + lucro
+		profit = roletaRes.Lucro
+	}
 
-	user.TotalBets += 1
-	user.TotalAmountBet += req.BetValue
-	user.Balance -= req.BetValue // Sempre debita a aposta
+	// Liquida a rodada pela carteira: débito da aposta, crédito do ganho e
+	// estatísticas na mesma transação, sem regravar o saldo inteiro
+	tx, err := config.DB.Begin()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to settle bet.", err.Error())
+		return
+	}
+	defer tx.Rollback()
+	balance, err := wallet.Debit(tx, userID, req.BetValue, "bet", fmt.Sprintf("Aposta na roleta - Valor: R$ %.2f", req.BetValue))
+	if errors.Is(err, wallet.ErrInsufficientFunds) {
+		utils.RespondError(c, http.StatusBadRequest, "INSUFFICIENT_FUNDS", "User does not have enough balance to place this bet.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to settle bet.", err.Error())
+		return
+	}
+	if isWin {
+		balance, err = wallet.Credit(tx, userID, winAmount, "win", fmt.Sprintf("Ganho na roleta - Carta: %s - Valor: R$ %.2f", roletaRes.CartinhaSorteada, winAmount))
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to settle bet.", err.Error())
+			return
+		}
+	}
+	if err := user_stats.RecordBetTx(tx, userID, req.BetValue, isWin, profit); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update user stats.", err.Error())
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to settle bet.", err.Error())
+		return
+	}
 
-	// Cria transação da aposta
-	betTransaction := transactions.Transaction{
-		UserID:      userID,
-		Type:        "bet",
-		Amount:      -req.BetValue,
-		Description: fmt.Sprintf("Aposta na roleta - Valor: R$ %.2f", req.BetValue),
+	// Pontua a aposta nos torneios em andamento
+	if err := tournaments.RecordPlay(userID, "roleta", req.BetValue, winAmount); err != nil {
+		log.Printf("Erro ao registrar aposta nos torneios: %v", err)
 	}
-	transactions.AddTransaction(betTransaction)
+	bonus.TrackWager(userID, "roleta", req.BetValue)
+	dashboard.TrackBet(userID, dashboard.RoletaBetRequest(req.BetValue, winAmount, roletaRes.CartinhaSorteada))
 
+	netResult := -req.BetValue
 	if isWin {
-		// Vitória
-		user.TotalWins += 1
-		user.TotalProfit += roletaRes.Lucro
-		user.Balance += roletaRes.Lucro + req.BetValue // Retorna a aposta + lucro
-		user.ConsecutiveLosses = 0                     // Reseta perdas consecutivas
-
-		// Cria transação do ganho
-		winTransaction := transactions.Transaction{
-			UserID:      userID,
-			Type:        "win",
-			Amount:      roletaRes.Lucro + req.BetValue,
-			Description: fmt.Sprintf("Ganho na roleta - Carta: %s - Valor: R$ %.2f", roletaRes.CartinhaSorteada, roletaRes.Lucro+req.BetValue),
-		}
-		transactions.AddTransaction(winTransaction)
-	} else {
-		// Perda
-		user.TotalLosses += 1
-		user.ConsecutiveLosses += 1 // Incrementa perdas consecutivas
+		netResult = roletaRes.Lucro
 	}
+	realityCheck := responsible_gaming.TrackPlay(userID, req.BetValue, netResult)
 
-	// Salva no banco
-	_, err = user_stats.UpdateUserStats(user, user.ID)
+	remaining, err := responsible_gaming.GetAllowances(userID)
 	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update user stats.", err.Error())
-		return
+		log.Printf("Erro ao buscar limites do usuário %d: %v", userID, err)
 	}
 
 	// Resposta para o frontend
 	if isWin {
 		resp := RoletaBetResponse{
 			Result:         "win",
-			WinAmount:      roletaRes.Lucro + req.BetValue,
+			WinAmount:      winAmount,
 			Card:           roletaRes.CartinhaSorteada,
-			CurrentBalance: user.Balance,
+			CurrentBalance: balance,
 			Message:        "Parabéns, você ganhou!",
+			Limits:         responsible_gaming.ToAllowanceResponses(remaining),
+			RealityCheck:   realityCheck,
+			Bonus:          bonusSummary(userID),
 		}
 		c.JSON(http.StatusOK, resp)
 	} else {
@@ -154,9 +195,85 @@ func RoletaBetHandler(c *gin.Context) {
 			Result:         "lose",
 			WinAmount:      0,
 			Card:           roletaRes.CartinhaSorteada,
-			CurrentBalance: user.Balance,
+			CurrentBalance: balance,
 			Message:        "Que pena, você perdeu.",
+			Limits:         responsible_gaming.ToAllowanceResponses(remaining),
+			RealityCheck:   realityCheck,
+			Bonus:          bonusSummary(userID),
 		}
 		c.JSON(http.StatusOK, resp)
 	}
 }
+
+// freeSpinHandler joga um giro grátis: a aposta sai do bônus e o ganho vai
+// para o saldo de bônus, sem movimentar o saldo real.
+func freeSpinHandler(c *gin.Context, userID int64) {
+	if !responsible_gaming.CheckBet(c, userID, 0) {
+		return
+	}
+	spin, err := bonus.UseFreeSpin(userID)
+	if err == bonus.ErrNoFreeSpins {
+		utils.RespondError(c, http.StatusBadRequest, "NO_FREE_SPINS", "You have no free spins available.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to use free spin.", err.Error())
+		return
+	}
+
+	res := ExecutaRoleta(userID, spin.Value)
+	roletaRes, ok := res.(RoletaResult)
+	if !ok {
+		utils.RespondError(c, http.StatusInternalServerError, "GAME_ERROR", "Failed to execute roleta game.", nil)
+		return
+	}
+	isWin := roletaRes.CartinhaSorteada != "perca"
+	winAmount := 0.0
+	if isWin {
+		winAmount = roletaRes.Lucro + spin.Value
+	}
+	if err := bonus.SettleFreeSpin(spin, winAmount); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to settle free spin.", err.Error())
+		return
+	}
+
+	realityCheck := responsible_gaming.TrackPlay(userID, 0, 0)
+	remaining, err := responsible_gaming.GetAllowances(userID)
+	if err != nil {
+		log.Printf("Erro ao buscar limites do usuário %d: %v", userID, err)
+	}
+	user, err := user_stats.GetUserStatsByID(fmt.Sprintf("%d", userID))
+	if err != nil {
+		log.Printf("Erro ao buscar saldo do usuário %d: %v", userID, err)
+	}
+
+	resp := RoletaBetResponse{
+		Result:         "lose",
+		Card:           roletaRes.CartinhaSorteada,
+		CurrentBalance: user.Balance,
+		Message:        "Giro grátis: não foi dessa vez.",
+		Limits:         responsible_gaming.ToAllowanceResponses(remaining),
+		RealityCheck:   realityCheck,
+		Bonus:          bonusSummary(userID),
+	}
+	if isWin {
+		resp.Result = "win"
+		resp.WinAmount = winAmount
+		resp.Message = "Giro grátis premiado! O ganho foi para o seu saldo de bônus."
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// bonusSummary devolve o saldo de bônus para a resposta, ou nil se o jogador
+// não tiver bônus em andamento.
+func bonusSummary(userID int64) *bonus.Summary {
+	summary, err := bonus.GetSummary(userID)
+	if err != nil {
+		log.Printf("Erro ao buscar bônus do usuário %d: %v", userID, err)
+		return nil
+	}
+	if summary.BonusBalance == 0 && summary.FreeSpins == 0 {
+		return nil
+	}
+	return &summary
+}
@@ -3,6 +3,8 @@ package roleta
 import (
 	"berry_bet/config"
 	"berry_bet/internal/user_stats"
+	"berry_bet/internal/wallet"
+	"errors"
 	"fmt"
 	"strconv"
 )
@@ -16,66 +18,35 @@ type RoletaResult struct {
 
 // Conagem de ganhos e percas
 func Update_wins_losses(userID int64, ganhou bool) int {
-	stats, err := user_stats.GetUserStatsByID(strconv.FormatInt(userID, 10))
-	if err != nil {
-		return int(stats.TotalLosses)
-	}
+	column := "total_losses"
 	if ganhou {
-		stats.TotalWins++
-	} else {
-		stats.TotalLosses++
+		column = "total_wins"
 	}
-	_, _ = user_stats.UpdateUserStats(stats, stats.ID)
+	_, _ = config.DB.Exec("UPDATE user_stats SET "+column+" = "+column+" + 1, updated_at = datetime('now') WHERE user_id = ?", userID)
+	stats, _ := user_stats.GetUserStatsByID(strconv.FormatInt(userID, 10))
 	return int(stats.TotalLosses)
 }
 
-// ATENÇÃO: Função para atualizar o saldo do usuário no banco de dados
-func UpdateUserBalance(userID int64, newBalance float64) error {
-	stmt, err := config.DB.Prepare("UPDATE user_stats SET balance = ?, updated_at = datetime('now') WHERE user_id = ?")
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-	_, err = stmt.Exec(newBalance, userID)
-	return err
-}
-
 // Função para obter o saldo atual do usuário (OK)
 func Get_Saldo_Atual(userID int64) (float64, error) {
 	return user_stats.GetUserBalance(userID)
 }
 
-// Função para incrementar (ou decrementar) o saldo do usuário no banco de dados
-func Inclement_amount(userID int64, valor float64) (float64, error) {
-	balance, err := user_stats.GetUserBalance(userID)
-	if err != nil {
-		return 0, err
-	}
-	new_balance := balance + valor
-	// >>> ALTERAÇÃO: Agora salva o novo saldo no banco <<<
-	err = UpdateUserBalance(userID, new_balance)
-	if err != nil {
-		return 0, err
-	}
-	return new_balance, nil
-}
-
-// Função para debitar o valor da aposta do saldo do usuário no banco de dados
+// Função para debitar o valor da aposta do saldo do usuário pela carteira
 func Value_aport(userID int64, valor float64) (float64, error) {
-	balance, err := user_stats.GetUserBalance(userID)
+	tx, err := config.DB.Begin()
 	if err != nil {
 		return 0, err
 	}
-	if balance < valor {
+	defer tx.Rollback()
+	_, err = wallet.Debit(tx, userID, valor, "bet", fmt.Sprintf("Aposta na roleta - Valor: R$ %.2f", valor))
+	if errors.Is(err, wallet.ErrInsufficientFunds) {
 		return 0, fmt.Errorf("saldo insuficiente")
 	}
-	new_balance := balance - valor
-	// >>> ALTERAÇÃO: Agora salva o novo saldo no banco <<<
-	err = UpdateUserBalance(userID, new_balance)
 	if err != nil {
 		return 0, err
 	}
-	return valor, nil
+	return valor, tx.Commit()
 }
 
 // Atualiza o total de apostas do usuário
@@ -0,0 +1,11 @@
+package kyc
+
+type RejectRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// SubmissionDetailResponse é o envio com os dados cadastrais para o revisor.
+type SubmissionDetailResponse struct {
+	Submission
+	Applicant Applicant `json:"applicant"`
+}
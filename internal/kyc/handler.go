@@ -0,0 +1,234 @@
+package kyc
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/utils"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+func respondKYCError(c *gin.Context, err error) {
+	var missing *MissingError
+	switch {
+	case errors.As(err, &missing):
+		utils.RespondError(c, http.StatusUnprocessableEntity, "KYC_INCOMPLETE", "Missing documents or profile data.",
+			gin.H{"missing_documents": missing.Documents, "missing_fields": missing.Fields})
+	case errors.Is(err, ErrInvalidState):
+		utils.RespondError(c, http.StatusConflict, "INVALID_KYC_STATUS", "Operation not allowed in the current verification status.", nil)
+	case errors.Is(err, ErrSubmissionNotFound), errors.Is(err, ErrUserNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Verification not found.", nil)
+	case errors.Is(err, ErrReasonRequired):
+		utils.RespondError(c, http.StatusBadRequest, "REASON_REQUIRED", "A rejection reason of at least 5 characters is required.", nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to process verification.", err.Error())
+	}
+}
+
+// GetMyKYCHandler returns the caller's verification status, the documents
+// uploaded for the next submission and the latest submission.
+func GetMyKYCHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	status, err := GetUserStatus(userID)
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, status, "Verification status found")
+}
+
+// UploadDocumentHandler stores one identity document (multipart fields
+// "type" and "file"). Uploading the same type again replaces the previous
+// file until the documents are submitted.
+func UploadDocumentHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	docType := c.PostForm("type")
+	if !isDocumentType(docType) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_DOCUMENT_TYPE", "Invalid document type.", gin.H{"allowed": DocumentTypes})
+		return
+	}
+	status, err := GetStatus(userID)
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	if !canEdit(status) {
+		respondKYCError(c, ErrInvalidState)
+		return
+	}
+	saved, err := utils.SaveUpload(c, documentUpload, documentPrefix(userID, docType))
+	if err != nil {
+		utils.RespondUploadError(c, err)
+		return
+	}
+	old, err := replaceDocument(Document{
+		UserID:      userID,
+		Type:        docType,
+		FilePath:    saved.Path,
+		ContentType: saved.ContentType,
+		Size:        saved.Size,
+	})
+	if err != nil {
+		os.Remove(saved.Path)
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to save document.", err.Error())
+		return
+	}
+	if old != "" {
+		if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+			log.Printf("Erro ao remover documento KYC substituído %s: %v", old, err)
+		}
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "kyc.document_uploaded",
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    map[string]any{"type": docType, "content_type": saved.ContentType, "size": saved.Size},
+	})
+	s, err := GetUserStatus(userID)
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, s, "Document uploaded.")
+}
+
+// SubmitKYCHandler sends the uploaded documents for review.
+func SubmitKYCHandler(c *gin.Context) {
+	if _, ok := utils.CurrentUserID(c); !ok {
+		return
+	}
+	s, err := Submit(audit.ActorFromContext(c))
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, s, "Documents submitted for review.")
+}
+
+// ListSubmissionsHandler lists submissions for review, oldest first.
+// ?status defaults to pending; "all" lists every submission.
+func ListSubmissionsHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", StatusPending)
+	switch status {
+	case "all":
+		status = ""
+	case StatusPending, StatusVerified, StatusRejected:
+	default:
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_STATUS", "Invalid status.", nil)
+		return
+	}
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	list, total, err := ListSubmissions(status, page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch submissions.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"submissions": list, "total": total, "page": page, "limit": limit}, "Submissions found")
+}
+
+// GetSubmissionHandler returns a submission with its documents and the
+// applicant's registration data, for comparison with the documents.
+func GetSubmissionHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	s, err := GetSubmission(id)
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	applicant, err := GetApplicant(s.UserID)
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, SubmissionDetailResponse{Submission: s, Applicant: applicant}, "Submission found")
+}
+
+// GetDocumentFileHandler streams a document to a reviewer. Documents are
+// never served from the public uploads directory.
+func GetDocumentFileHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	d, err := GetDocument(id)
+	if errors.Is(err, ErrDocumentNotFound) {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Document not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch document.", err.Error())
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "kyc.document_viewed",
+		TargetType: "user",
+		TargetID:   d.UserID,
+		Details:    map[string]any{"document_id": d.ID, "type": d.Type},
+	})
+	c.Header("Content-Type", d.ContentType)
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.File(d.FilePath)
+}
+
+// ApproveSubmissionHandler marks the applicant as verified.
+func ApproveSubmissionHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	s, err := Review(audit.ActorFromContext(c), id, true, "")
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, s, "Submission approved.")
+}
+
+// RejectSubmissionHandler rejects a submission; the applicant may upload new
+// documents and submit again.
+func RejectSubmissionHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid input.", err.Error())
+		return
+	}
+	s, err := Review(audit.ActorFromContext(c), id, false, req.Reason)
+	if err != nil {
+		respondKYCError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, s, "Submission rejected.")
+}
@@ -0,0 +1,39 @@
+package kyc
+
+import (
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RespondRequiredError escreve a resposta de verificação exigida e devolve
+// true quando err é *RequiredError.
+func RespondRequiredError(c *gin.Context, err error) bool {
+	var reqErr *RequiredError
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+	utils.RespondError(c, http.StatusForbidden, "KYC_REQUIRED", "Verify your identity before doing this.", gin.H{"kyc_status": reqErr.Status})
+	return true
+}
+
+// RequireVerified blocks users whose identity has not been verified. Must run after JWTAuthMiddleware.
+func RequireVerified() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := utils.CurrentUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if err := CheckVerified(userID); err != nil {
+			if !RespondRequiredError(c, err) {
+				utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch verification status.", err.Error())
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
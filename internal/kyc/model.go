@@ -0,0 +1,204 @@
+package kyc
+
+import (
+	"berry_bet/config"
+	"database/sql"
+)
+
+// Submission é um envio para análise.
+type Submission struct {
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"user_id"`
+	Status          string     `json:"status"`
+	SubmittedAt     string     `json:"submitted_at"`
+	ReviewedBy      *int64     `json:"reviewed_by"`
+	ReviewedAt      *string    `json:"reviewed_at"`
+	RejectionReason *string    `json:"rejection_reason"`
+	Documents       []Document `json:"documents,omitempty"`
+}
+
+// Document é um documento enviado. FilePath nunca é exposto na API.
+type Document struct {
+	ID          int64  `json:"id"`
+	Type        string `json:"type"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	UploadedAt  string `json:"uploaded_at"`
+	FilePath    string `json:"-"`
+	UserID      int64  `json:"-"`
+}
+
+// Applicant são os dados cadastrais conferidos pelo revisor.
+type Applicant struct {
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	CPF       string `json:"cpf"`
+	DateBirth string `json:"date_birth"`
+}
+
+// GetStatus devolve o status KYC do usuário.
+func GetStatus(userID int64) (string, error) {
+	var status string
+	err := config.DB.QueryRow(`SELECT kyc_status FROM users WHERE id = ?`, userID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrUserNotFound
+	}
+	return status, err
+}
+
+// transitionTx muda o status do usuário apenas se ele ainda estiver em from,
+// evitando corridas entre revisores ou entre envios simultâneos.
+func transitionTx(tx *sql.Tx, userID int64, from, to string) (bool, error) {
+	res, err := tx.Exec(`UPDATE users SET kyc_status = ?, updated_at = datetime('now') WHERE id = ? AND kyc_status = ?`, to, userID, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+const submissionColumns = `id, user_id, status, CAST(submitted_at AS TEXT), reviewed_by, CAST(reviewed_at AS TEXT), rejection_reason`
+
+func scanSubmission(row interface{ Scan(dest ...any) error }) (Submission, error) {
+	var s Submission
+	var reviewedBy sql.NullInt64
+	var reviewedAt, reason sql.NullString
+	err := row.Scan(&s.ID, &s.UserID, &s.Status, &s.SubmittedAt, &reviewedBy, &reviewedAt, &reason)
+	if reviewedBy.Valid {
+		s.ReviewedBy = &reviewedBy.Int64
+	}
+	if reviewedAt.Valid {
+		s.ReviewedAt = &reviewedAt.String
+	}
+	if reason.Valid {
+		s.RejectionReason = &reason.String
+	}
+	return s, err
+}
+
+// GetSubmission devolve o envio com os documentos.
+func GetSubmission(id int64) (Submission, error) {
+	s, err := scanSubmission(config.DB.QueryRow(`SELECT `+submissionColumns+` FROM kyc_submissions WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return s, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	s.Documents, err = listDocuments(`submission_id = ?`, id)
+	return s, err
+}
+
+// GetLatestSubmission devolve o envio mais recente do usuário, ou nil.
+func GetLatestSubmission(userID int64) (*Submission, error) {
+	s, err := scanSubmission(config.DB.QueryRow(`
+		SELECT `+submissionColumns+` FROM kyc_submissions WHERE user_id = ? ORDER BY submitted_at DESC, id DESC LIMIT 1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &s, err
+}
+
+// ListSubmissions lista os envios com o status informado ("" para todos),
+// os mais antigos primeiro, para a fila de análise.
+func ListSubmissions(status string, page, limit int) ([]Submission, int, error) {
+	var total int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM kyc_submissions WHERE ? = '' OR status = ?`, status, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := config.DB.Query(`
+		SELECT `+submissionColumns+` FROM kyc_submissions
+		WHERE ? = '' OR status = ?
+		ORDER BY submitted_at, id LIMIT ? OFFSET ?`, status, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	list := []Submission{}
+	for rows.Next() {
+		s, err := scanSubmission(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, s)
+	}
+	return list, total, rows.Err()
+}
+
+func listDocuments(where string, arg int64) ([]Document, error) {
+	rows, err := config.DB.Query(`
+		SELECT id, user_id, doc_type, file_path, content_type, size, CAST(uploaded_at AS TEXT)
+		FROM kyc_documents WHERE `+where+` ORDER BY doc_type, id`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Document{}
+	for rows.Next() {
+		var d Document
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Type, &d.FilePath, &d.ContentType, &d.Size, &d.UploadedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// GetPendingDocuments devolve os documentos ainda não enviados para análise.
+func GetPendingDocuments(userID int64) ([]Document, error) {
+	return listDocuments(`submission_id IS NULL AND user_id = ?`, userID)
+}
+
+// GetDocument devolve um documento pelo id.
+func GetDocument(id int64) (Document, error) {
+	docs, err := listDocuments(`id = ?`, id)
+	if err != nil {
+		return Document{}, err
+	}
+	if len(docs) == 0 {
+		return Document{}, ErrDocumentNotFound
+	}
+	return docs[0], nil
+}
+
+// replaceDocument grava o documento, descartando o anterior do mesmo tipo
+// ainda não enviado. Devolve o caminho do arquivo substituído, se houver.
+func replaceDocument(d Document) (string, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	var oldPath string
+	err = tx.QueryRow(`SELECT file_path FROM kyc_documents WHERE user_id = ? AND doc_type = ? AND submission_id IS NULL`, d.UserID, d.Type).Scan(&oldPath)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	if _, err := tx.Exec(`DELETE FROM kyc_documents WHERE user_id = ? AND doc_type = ? AND submission_id IS NULL`, d.UserID, d.Type); err != nil {
+		return "", err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO kyc_documents (user_id, doc_type, file_path, content_type, size, uploaded_at)
+		VALUES (?, ?, ?, ?, ?, datetime('now'))`, d.UserID, d.Type, d.FilePath, d.ContentType, d.Size)
+	if err != nil {
+		return "", err
+	}
+	return oldPath, tx.Commit()
+}
+
+// GetApplicant devolve os dados cadastrais do usuário para a análise.
+func GetApplicant(userID int64) (Applicant, error) {
+	var a Applicant
+	var birth sql.NullString
+	err := config.DB.QueryRow(`SELECT id, username, name, email, cpf, CAST(date_birth AS TEXT) FROM users WHERE id = ?`, userID).
+		Scan(&a.UserID, &a.Username, &a.Name, &a.Email, &a.CPF, &birth)
+	if err == sql.ErrNoRows {
+		return a, ErrUserNotFound
+	}
+	if birth.Valid && len(birth.String) >= 10 {
+		a.DateBirth = birth.String[:10]
+	}
+	return a, err
+}
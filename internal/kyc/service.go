@@ -0,0 +1,325 @@
+package kyc
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/utils"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	StatusUnverified = "unverified"
+	StatusPending    = "pending"
+	StatusVerified   = "verified"
+	StatusRejected   = "rejected"
+)
+
+// DocumentTypes são os tipos aceitos; RequiredDocuments precisam estar
+// presentes para enviar para análise.
+var (
+	DocumentTypes     = []string{"id_front", "id_back", "selfie", "proof_of_address"}
+	RequiredDocuments = []string{"id_front", "id_back", "selfie"}
+)
+
+// DocumentDir fica fora de uploads/, que é servido publicamente.
+const DocumentDir = "data/kyc/"
+
+var documentUpload = utils.UploadRule{
+	Field:    "file",
+	Dir:      DocumentDir,
+	MaxBytes: 10 << 20,
+	AllowedTypes: map[string]string{
+		"image/jpeg":      ".jpg",
+		"image/png":       ".png",
+		"image/webp":      ".webp",
+		"application/pdf": ".pdf",
+	},
+}
+
+// DefaultHighStakeThreshold é o valor de aposta a partir do qual a
+// verificação é exigida, quando KYC_HIGH_STAKE_THRESHOLD não está definido.
+const DefaultHighStakeThreshold = 500.0
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrSubmissionNotFound = errors.New("kyc submission not found")
+	ErrDocumentNotFound   = errors.New("kyc document not found")
+	ErrInvalidDocType     = errors.New("invalid document type")
+	ErrInvalidState       = errors.New("operation not allowed in the current kyc status")
+	ErrReasonRequired     = errors.New("a rejection reason is required")
+)
+
+// MissingError indica o que falta para enviar para análise.
+type MissingError struct {
+	Documents []string
+	Fields    []string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("missing documents %v or fields %v", e.Documents, e.Fields)
+}
+
+// RequiredError indica que a operação exige conta verificada.
+type RequiredError struct {
+	Status string
+}
+
+func (e *RequiredError) Error() string {
+	return "identity verification required (status " + e.Status + ")"
+}
+
+// Status resume a situação KYC do usuário.
+type Status struct {
+	Status     string      `json:"status"`
+	Documents  []Document  `json:"documents"`
+	Missing    []string    `json:"missing_documents"`
+	Submission *Submission `json:"submission"`
+}
+
+func isDocumentType(t string) bool {
+	for _, d := range DocumentTypes {
+		if d == t {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserStatus devolve o status, os documentos ainda não enviados e o
+// envio mais recente do usuário.
+func GetUserStatus(userID int64) (Status, error) {
+	var s Status
+	var err error
+	if s.Status, err = GetStatus(userID); err != nil {
+		return s, err
+	}
+	if s.Documents, err = GetPendingDocuments(userID); err != nil {
+		return s, err
+	}
+	s.Missing = missingDocuments(s.Documents)
+	s.Submission, err = GetLatestSubmission(userID)
+	return s, err
+}
+
+func missingDocuments(docs []Document) []string {
+	have := map[string]bool{}
+	for _, d := range docs {
+		have[d.Type] = true
+	}
+	missing := []string{}
+	for _, t := range RequiredDocuments {
+		if !have[t] {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// canEdit indica se o usuário pode enviar documentos no status atual.
+func canEdit(status string) bool {
+	return status == StatusUnverified || status == StatusRejected
+}
+
+// Submit envia os documentos para análise, levando o usuário a pending.
+func Submit(actor audit.Actor) (Submission, error) {
+	userID := actor.UserID
+	status, err := GetStatus(userID)
+	if err != nil {
+		return Submission{}, err
+	}
+	if !canEdit(status) {
+		return Submission{}, ErrInvalidState
+	}
+	docs, err := GetPendingDocuments(userID)
+	if err != nil {
+		return Submission{}, err
+	}
+	applicant, err := GetApplicant(userID)
+	if err != nil {
+		return Submission{}, err
+	}
+	missing := &MissingError{Documents: missingDocuments(docs)}
+	if !utils.IsValidCPF(applicant.CPF) {
+		missing.Fields = append(missing.Fields, "cpf")
+	}
+	if !utils.IsAdult(applicant.DateBirth) {
+		missing.Fields = append(missing.Fields, "date_birth")
+	}
+	if len(missing.Documents) > 0 || len(missing.Fields) > 0 {
+		return Submission{}, missing
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return Submission{}, err
+	}
+	defer tx.Rollback()
+	ok, err := transitionTx(tx, userID, status, StatusPending)
+	if err != nil {
+		return Submission{}, err
+	}
+	if !ok {
+		return Submission{}, ErrInvalidState
+	}
+	res, err := tx.Exec(`INSERT INTO kyc_submissions (user_id, status, submitted_at) VALUES (?, 'pending', datetime('now'))`, userID)
+	if err != nil {
+		return Submission{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Submission{}, err
+	}
+	if _, err := tx.Exec(`UPDATE kyc_documents SET submission_id = ? WHERE user_id = ? AND submission_id IS NULL`, id, userID); err != nil {
+		return Submission{}, err
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     "kyc.submitted",
+		TargetType: "kyc_submission",
+		TargetID:   id,
+		Details:    map[string]any{"documents": len(docs)},
+	})
+	if err != nil {
+		return Submission{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Submission{}, err
+	}
+	return GetSubmission(id)
+}
+
+// Review aprova ou rejeita um envio pendente.
+func Review(actor audit.Actor, submissionID int64, approve bool, reason string) (Submission, error) {
+	reason = strings.TrimSpace(reason)
+	if !approve && len(reason) < 5 {
+		return Submission{}, ErrReasonRequired
+	}
+	s, err := GetSubmission(submissionID)
+	if err != nil {
+		return s, err
+	}
+	if s.Status != StatusPending {
+		return s, ErrInvalidState
+	}
+	if approve {
+		// A data de nascimento pode ter mudado desde o envio.
+		applicant, err := GetApplicant(s.UserID)
+		if err != nil {
+			return s, err
+		}
+		if !utils.IsAdult(applicant.DateBirth) {
+			return s, &MissingError{Fields: []string{"date_birth"}}
+		}
+	}
+	to := StatusRejected
+	if approve {
+		to = StatusVerified
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return s, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`
+		UPDATE kyc_submissions SET status = ?, reviewed_by = ?, reviewed_at = datetime('now'), rejection_reason = ?
+		WHERE id = ? AND status = 'pending'`, to, actor.UserID, nullable(reason), submissionID)
+	if err != nil {
+		return s, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrInvalidState
+		}
+		return s, err
+	}
+	ok, err := transitionTx(tx, s.UserID, StatusPending, to)
+	if err != nil {
+		return s, err
+	}
+	if !ok {
+		return s, ErrInvalidState
+	}
+	details := map[string]any{"submission_id": submissionID}
+	if !approve {
+		details["reason"] = reason
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     "kyc." + to,
+		TargetType: "user",
+		TargetID:   s.UserID,
+		Details:    details,
+	})
+	if err != nil {
+		return s, err
+	}
+	if err := tx.Commit(); err != nil {
+		return s, err
+	}
+
+	if approve {
+		notifications.Notify(s.UserID, "kyc", "Identidade verificada",
+			"Sua identidade foi verificada. Saques e apostas altas estão liberados.")
+	} else {
+		notifications.Notify(s.UserID, "kyc", "Verificação de identidade recusada",
+			"Sua verificação foi recusada: "+reason+". Envie novos documentos para tentar novamente.")
+	}
+	return GetSubmission(submissionID)
+}
+
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// CheckVerified devolve *RequiredError se o usuário não estiver verificado.
+func CheckVerified(userID int64) error {
+	status, err := GetStatus(userID)
+	if err != nil {
+		return err
+	}
+	if status != StatusVerified {
+		return &RequiredError{Status: status}
+	}
+	return nil
+}
+
+// HighStakeThreshold lê KYC_HIGH_STAKE_THRESHOLD; 0 desativa a exigência.
+func HighStakeThreshold() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("KYC_HIGH_STAKE_THRESHOLD"), 64); err == nil && v >= 0 {
+		return v
+	}
+	return DefaultHighStakeThreshold
+}
+
+// CheckStake exige verificação para apostas a partir do limite configurado.
+func CheckStake(userID int64, amount float64) error {
+	threshold := HighStakeThreshold()
+	if threshold <= 0 || amount < threshold {
+		return nil
+	}
+	return CheckVerified(userID)
+}
+
+// IsLocked indica se os dados de identidade do usuário não podem mais ser
+// alterados pelo próprio usuário (em análise ou já verificado).
+func IsLocked(userID int64) (bool, error) {
+	status, err := GetStatus(userID)
+	if err != nil {
+		return false, err
+	}
+	return status == StatusPending || status == StatusVerified, nil
+}
+
+// documentPrefix identifica o dono no nome do arquivo.
+func documentPrefix(userID int64, docType string) string {
+	return strconv.FormatInt(userID, 10) + "_" + docType
+}
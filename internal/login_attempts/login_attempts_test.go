@@ -0,0 +1,157 @@
+package login_attempts
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestDelayAndLockoutEscalation(t *testing.T) {
+	p := Policies[ScopeAccount]
+	delays := map[int]time.Duration{0: 0, 2: 0, 3: 2 * time.Second, 4: 4 * time.Second, 5: 8 * time.Second, 20: 30 * time.Second}
+	for failures, want := range delays {
+		if got := delayFor(p, failures); got != want {
+			t.Errorf("delayFor(%d) = %v, want %v", failures, got, want)
+		}
+	}
+	lockouts := map[int]time.Duration{1: 15 * time.Minute, 2: 30 * time.Minute, 3: time.Hour, 50: 24 * time.Hour}
+	for n, want := range lockouts {
+		if got := lockoutFor(p, n); got != want {
+			t.Errorf("lockoutFor(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestIPKeyGroupsIPv6By64(t *testing.T) {
+	a := IPKey("2001:db8:1:2:aaaa::1")
+	b := IPKey("2001:db8:1:2:bbbb::2")
+	c := IPKey("2001:db8:1:3::1")
+	if a != b {
+		t.Fatalf("addresses in the same /64 got different keys: %v, %v", a, b)
+	}
+	if a == c {
+		t.Fatalf("addresses in different /64s share a key: %v", a)
+	}
+	if got := IPKey("203.0.113.7"); got.Value != "203.0.113.7" || got.Scope != ScopeIP {
+		t.Fatalf("IPKey(v4) = %+v", got)
+	}
+	if got := IPKey("::ffff:203.0.113.7"); got.Value != "::ffff:203.0.113.7" {
+		t.Fatalf("IPv4-mapped address was grouped: %+v", got)
+	}
+}
+
+func check(t *testing.T, keys ...Key) Decision {
+	t.Helper()
+	d, err := Check(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func fail(t *testing.T, k Key, times int) time.Time {
+	t.Helper()
+	var lockedUntil time.Time
+	for i := 0; i < times; i++ {
+		var err error
+		if lockedUntil, err = RegisterFailure(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return lockedUntil
+}
+
+func TestAccountThrottleAndLockout(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	k := AccountKey(user, "ana")
+	ip := IPKey("203.0.113.7")
+
+	if lockedUntil := fail(t, k, 2); !lockedUntil.IsZero() {
+		t.Fatal("locked before MaxFailures")
+	}
+	if d := check(t, k, ip); !d.Allowed {
+		t.Fatalf("free attempts were throttled: %+v", d)
+	}
+
+	fail(t, k, 1)
+	d := check(t, ip, k)
+	if d.Allowed || d.Scope != ScopeAccount || d.Reason != ReasonThrottled {
+		t.Fatalf("after 3 failures: %+v, want throttled on the account", d)
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > 2*time.Second {
+		t.Fatalf("RetryAfter = %v, want up to 2s", d.RetryAfter)
+	}
+
+	lockedUntil := fail(t, k, 2)
+	if until := time.Until(lockedUntil); until < 14*time.Minute || until > 15*time.Minute {
+		t.Fatalf("first lockout lasts %v, want 15m", until)
+	}
+	if d := check(t, k); d.Allowed || d.Reason != ReasonLocked {
+		t.Fatalf("after 5 failures: %+v, want locked", d)
+	}
+	if lock, err := GetAccountLock(user); err != nil || !lock.Locked {
+		t.Fatalf("GetAccountLock = %+v, %v; want locked", lock, err)
+	}
+
+	// Depois que o bloqueio expira, um novo bloqueio dura o dobro
+	if _, err := config.DB.Exec(`UPDATE login_attempts SET locked_until = datetime('now', '-1 minute') WHERE key = ?`, k.Value); err != nil {
+		t.Fatal(err)
+	}
+	if d := check(t, k); !d.Allowed {
+		t.Fatalf("after the lockout expired: %+v, want allowed", d)
+	}
+	lockedUntil = fail(t, k, 5)
+	if until := time.Until(lockedUntil); until < 29*time.Minute || until > 30*time.Minute {
+		t.Fatalf("second lockout lasts %v, want 30m", until)
+	}
+
+	unlocked, err := Unlock(user)
+	if err != nil || !unlocked {
+		t.Fatalf("Unlock = %v, %v; want true", unlocked, err)
+	}
+	if d := check(t, k); !d.Allowed {
+		t.Fatalf("after Unlock: %+v, want allowed", d)
+	}
+	// O escalonamento recomeça depois do desbloqueio manual
+	lockedUntil = fail(t, k, 5)
+	if until := time.Until(lockedUntil); until > 15*time.Minute {
+		t.Fatalf("lockout after Unlock lasts %v, want 15m", until)
+	}
+}
+
+func TestResetClearsFailures(t *testing.T) {
+	testutil.DB(t)
+	k := AccountKey(0, " Fulano ")
+	if k != AccountKey(0, "fulano") {
+		t.Fatal("unknown identifiers are not normalized")
+	}
+	fail(t, k, 4)
+	if d := check(t, k); d.Allowed {
+		t.Fatal("expected throttling before Reset")
+	}
+	if err := Reset(k); err != nil {
+		t.Fatal(err)
+	}
+	if d := check(t, k); !d.Allowed {
+		t.Fatalf("after Reset: %+v, want allowed", d)
+	}
+}
+
+func TestIPLockoutCoversWholePrefix(t *testing.T) {
+	testutil.DB(t)
+	p := Policies[ScopeIP]
+	for i := 0; i < p.MaxFailures; i++ {
+		// Um atacante trocando de endereço dentro do mesmo /64
+		if _, err := RegisterFailure(IPKey("2001:db8::" + string(rune('a'+i%6)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := check(t, IPKey("2001:db8::ffff")); d.Allowed || d.Scope != ScopeIP || d.Reason != ReasonLocked {
+		t.Fatalf("%+v, want the /64 locked", d)
+	}
+	if d := check(t, IPKey("2001:db8:0:1::1")); !d.Allowed {
+		t.Fatalf("neighbouring /64 was locked: %+v", d)
+	}
+}
@@ -0,0 +1,153 @@
+package login_attempts
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dbTimeLayout = "2006-01-02 15:04:05"
+
+const (
+	ScopeAccount = "account"
+	ScopeIP      = "ip"
+)
+
+// Key identifica o contador de falhas de uma conta ou de um IP.
+type Key struct {
+	Scope  string
+	Value  string
+	UserID int64
+}
+
+// AccountKey devolve a chave da conta. Logins que não correspondem a nenhum
+// usuário (userID zero) são contados pelo identificador digitado, para que
+// contas inexistentes se comportem como as existentes.
+func AccountKey(userID int64, identifier string) Key {
+	if userID > 0 {
+		return Key{Scope: ScopeAccount, Value: "user:" + strconv.FormatInt(userID, 10), UserID: userID}
+	}
+	return Key{Scope: ScopeAccount, Value: "name:" + strings.ToLower(strings.TrimSpace(identifier))}
+}
+
+// IPKey devolve a chave do endereço IP de origem. O IP deve vir de
+// c.ClientIP(), que só aceita X-Forwarded-For de proxies em TRUSTED_PROXIES.
+// Endereços IPv6 são agrupados pelo prefixo /64, que costuma ser inteiro de um
+// mesmo cliente, para que trocar de endereço dentro dele não zere o contador.
+func IPKey(ip string) Key {
+	if addr, err := netip.ParseAddr(ip); err == nil && addr.Is6() && !addr.Is4In6() {
+		if prefix, err := addr.Prefix(64); err == nil {
+			return Key{Scope: ScopeIP, Value: prefix.String()}
+		}
+	}
+	return Key{Scope: ScopeIP, Value: ip}
+}
+
+// state é o contador de falhas de uma chave.
+type state struct {
+	Failures      int
+	Lockouts      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+func parseTime(s sql.NullString) time.Time {
+	if !s.Valid {
+		return time.Time{}
+	}
+	t, _ := time.Parse(dbTimeLayout, s.String)
+	return t
+}
+
+func formatTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(dbTimeLayout)
+}
+
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func loadState(q queryer, k Key) (state, error) {
+	var s state
+	var lastFailure, lockedUntil sql.NullString
+	err := q.QueryRow(`
+		SELECT failures, lockouts, CAST(last_failure_at AS TEXT), CAST(locked_until AS TEXT)
+		FROM login_attempts WHERE scope = ? AND key = ?`, k.Scope, k.Value).
+		Scan(&s.Failures, &s.Lockouts, &lastFailure, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return state{}, nil
+	}
+	if err != nil {
+		return state{}, err
+	}
+	s.LastFailureAt = parseTime(lastFailure)
+	s.LockedUntil = parseTime(lockedUntil)
+	return s, nil
+}
+
+func saveStateTx(tx *sql.Tx, k Key, s state) error {
+	var userID any
+	if k.UserID > 0 {
+		userID = k.UserID
+	}
+	_, err := tx.Exec(`
+		INSERT INTO login_attempts (scope, key, user_id, failures, lockouts, last_failure_at, locked_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(scope, key) DO UPDATE SET
+			failures = excluded.failures,
+			lockouts = excluded.lockouts,
+			last_failure_at = excluded.last_failure_at,
+			locked_until = excluded.locked_until`,
+		k.Scope, k.Value, userID, s.Failures, s.Lockouts, formatTime(s.LastFailureAt), formatTime(s.LockedUntil))
+	return err
+}
+
+// Reset zera as falhas da chave após um login bem-sucedido. O histórico de
+// bloqueios é mantido para o escalonamento.
+func Reset(k Key) error {
+	_, err := config.DB.Exec(`
+		UPDATE login_attempts SET failures = 0, locked_until = NULL
+		WHERE scope = ? AND key = ?`, k.Scope, k.Value)
+	return err
+}
+
+// Unlock remove o bloqueio e o histórico de falhas da conta do usuário.
+// Retorna false se a conta não estava bloqueada.
+func Unlock(userID int64) (bool, error) {
+	k := AccountKey(userID, "")
+	s, err := loadState(config.DB, k)
+	if err != nil {
+		return false, err
+	}
+	_, err = config.DB.Exec(`
+		UPDATE login_attempts SET failures = 0, lockouts = 0, locked_until = NULL
+		WHERE scope = ? AND key = ?`, k.Scope, k.Value)
+	return s.LockedUntil.After(time.Now()), err
+}
+
+// AccountLock descreve a situação de bloqueio de login de uma conta.
+type AccountLock struct {
+	FailedAttempts int    `json:"failed_attempts"`
+	Locked         bool   `json:"locked"`
+	LockedUntil    string `json:"locked_until,omitempty"`
+}
+
+// GetAccountLock retorna as falhas recentes e o bloqueio vigente da conta.
+func GetAccountLock(userID int64) (AccountLock, error) {
+	s, err := loadState(config.DB, AccountKey(userID, ""))
+	if err != nil {
+		return AccountLock{}, err
+	}
+	lock := AccountLock{FailedAttempts: s.Failures}
+	if s.LockedUntil.After(time.Now()) {
+		lock.Locked = true
+		lock.LockedUntil = s.LockedUntil.Format(dbTimeLayout)
+	}
+	return lock, nil
+}
@@ -0,0 +1,146 @@
+package login_attempts
+
+import (
+	"berry_bet/config"
+	"sync"
+	"time"
+)
+
+// Policy define a tolerância a falhas de login de um escopo.
+type Policy struct {
+	FreeAttempts    int           // falhas sem espera antes do atraso progressivo
+	MaxFailures     int           // falhas que disparam o bloqueio temporário
+	BaseDelay       time.Duration // atraso após a primeira falha além das gratuitas; dobra a cada nova falha
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration // duração do primeiro bloqueio; dobra a cada bloqueio recente
+	MaxLockout      time.Duration
+	Window          time.Duration // falhas mais antigas que isso deixam de contar
+}
+
+var Policies = map[string]Policy{
+	ScopeAccount: {
+		FreeAttempts:    3,
+		MaxFailures:     5,
+		BaseDelay:       2 * time.Second,
+		MaxDelay:        30 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+		MaxLockout:      24 * time.Hour,
+		Window:          time.Hour,
+	},
+	ScopeIP: {
+		FreeAttempts:    10,
+		MaxFailures:     30,
+		BaseDelay:       time.Second,
+		MaxDelay:        30 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+		MaxLockout:      24 * time.Hour,
+		Window:          time.Hour,
+	},
+}
+
+// Motivos de recusa devolvidos em Decision.Reason.
+const (
+	ReasonLocked    = "locked"
+	ReasonThrottled = "throttled"
+)
+
+// Decision é o resultado de Check.
+type Decision struct {
+	Allowed    bool
+	Scope      string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// writeMu serializa as atualizações deste processo, evitando que falhas
+// simultâneas da mesma chave se sobrescrevam.
+var writeMu sync.Mutex
+
+func delayFor(p Policy, failures int) time.Duration {
+	if failures < p.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+func lockoutFor(p Policy, lockouts int) time.Duration {
+	d := p.LockoutDuration
+	for i := 1; i < lockouts && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxLockout)
+}
+
+// Check verifica se uma nova tentativa de login é permitida para todas as
+// chaves informadas. Devolve a primeira recusa encontrada.
+func Check(keys ...Key) (Decision, error) {
+	now := time.Now().UTC()
+	for _, k := range keys {
+		s, err := loadState(config.DB, k)
+		if err != nil {
+			return Decision{}, err
+		}
+		if s.LockedUntil.After(now) {
+			return Decision{Scope: k.Scope, Reason: ReasonLocked, RetryAfter: s.LockedUntil.Sub(now)}, nil
+		}
+		p := Policies[k.Scope]
+		if now.Sub(s.LastFailureAt) > p.Window {
+			continue
+		}
+		if wait := s.LastFailureAt.Add(delayFor(p, s.Failures)).Sub(now); wait > 0 {
+			return Decision{Scope: k.Scope, Reason: ReasonThrottled, RetryAfter: wait}, nil
+		}
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// RegisterFailure conta uma falha de login para a chave. Ao atingir o limite da
+// política, a chave é bloqueada e a data de desbloqueio é devolvida (zero se
+// não houve bloqueio).
+func RegisterFailure(k Key) (time.Time, error) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	p := Policies[k.Scope]
+	now := time.Now().UTC()
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	s, err := loadState(tx, k)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if now.Sub(s.LastFailureAt) > p.Window {
+		s.Failures = 0
+	}
+	if now.Sub(s.LastFailureAt) > p.MaxLockout {
+		s.Lockouts = 0
+	}
+	if !s.LockedUntil.IsZero() && !s.LockedUntil.After(now) {
+		// O bloqueio anterior expirou: recomeça a contagem.
+		s.Failures = 0
+		s.LockedUntil = time.Time{}
+	}
+	s.Failures++
+	s.LastFailureAt = now
+
+	var lockedUntil time.Time
+	if s.Failures >= p.MaxFailures {
+		s.Lockouts++
+		s.Failures = 0
+		lockedUntil = now.Add(lockoutFor(p, s.Lockouts))
+		s.LockedUntil = lockedUntil
+	}
+	if err := saveStateTx(tx, k, s); err != nil {
+		return time.Time{}, err
+	}
+	return lockedUntil, tx.Commit()
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// Message é um e-mail em texto puro.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envia e-mails.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var current Mailer = NewFileMailer(defaultMailDir)
+
+// Configure escolhe a implementação usada por Send: "smtp" (SMTP_HOST,
+// SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, MAIL_FROM) ou "file" (padrão), que
+// grava cada mensagem em MAIL_DIR e no log, para uso offline.
+func Configure(kind string) {
+	switch strings.ToLower(kind) {
+	case "smtp":
+		current = NewSMTPMailer(
+			os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"))
+	case "", "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = defaultMailDir
+		}
+		current = NewFileMailer(dir)
+	default:
+		log.Printf("MAILER %q desconhecido, gravando e-mails em arquivo", kind)
+		current = NewFileMailer(defaultMailDir)
+	}
+}
+
+// Send envia a mensagem pela implementação configurada.
+func Send(msg Message) error {
+	return current.Send(msg)
+}
@@ -0,0 +1,34 @@
+package notifications
+
+type NotificationResponse struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	Read      bool   `json:"read"`
+	ReadAt    string `json:"read_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Unread        int                    `json:"unread"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+}
+
+func ToNotificationResponse(n *Notification) NotificationResponse {
+	readAt := ""
+	if n.ReadAt != nil {
+		readAt = *n.ReadAt
+	}
+	return NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Message:   n.Message,
+		Read:      n.ReadAt != nil,
+		ReadAt:    readAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMeNotificationsHandler returns the authenticated user's notifications
+// (page, limit, unread=true to list only unread ones).
+func GetMeNotificationsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	page, limit := 1, 20
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	list, err := GetNotificationsByUserID(userID, c.Query("unread") == "true", page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch notifications.", err.Error())
+		return
+	}
+	unread, err := CountUnread(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to count notifications.", err.Error())
+		return
+	}
+	resp := NotificationListResponse{
+		Notifications: make([]NotificationResponse, 0, len(list)),
+		Unread:        unread,
+		Page:          page,
+		Limit:         limit,
+	}
+	for _, n := range list {
+		resp.Notifications = append(resp.Notifications, ToNotificationResponse(&n))
+	}
+	utils.RespondSuccess(c, resp, "Notifications found")
+}
+
+// MarkNotificationReadHandler marks one of the authenticated user's notifications as read.
+func MarkNotificationReadHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return
+	}
+	err = MarkAsRead(id, userID)
+	if errors.Is(err, ErrNotFound) {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Notification not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update notification.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, nil, "Notification marked as read.")
+}
@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"errors"
+)
+
+var ErrNotFound = errors.New("notification not found")
+
+// Notification é uma mensagem exibida ao jogador.
+type Notification struct {
+	ID        int64   `json:"id"`
+	UserID    int64   `json:"user_id"`
+	Type      string  `json:"type"`
+	Title     string  `json:"title"`
+	Message   string  `json:"message"`
+	ReadAt    *string `json:"read_at"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// AddNotification grava uma nova notificação para o usuário.
+func AddNotification(n Notification) (int64, error) {
+	res, err := config.DB.Exec(`
+		INSERT INTO notifications (user_id, type, title, message, created_at)
+		VALUES (?, ?, ?, ?, datetime('now'))`, n.UserID, n.Type, n.Title, n.Message)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetNotificationsByUserID retorna as notificações do usuário, mais recentes primeiro.
+func GetNotificationsByUserID(userID int64, unreadOnly bool, page, limit int) ([]Notification, error) {
+	query := `SELECT id, user_id, type, title, message, read_at, created_at FROM notifications WHERE user_id = ?`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	rows, err := config.DB.Query(query, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Notification, 0)
+	for rows.Next() {
+		var n Notification
+		var readAt sql.NullString
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message, &readAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.String
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+// CountUnread retorna quantas notificações do usuário ainda não foram lidas.
+func CountUnread(userID int64) (int, error) {
+	var count int
+	err := config.DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkAsRead marca a notificação do usuário como lida.
+func MarkAsRead(id, userID int64) error {
+	res, err := config.DB.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, datetime('now'))
+		WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
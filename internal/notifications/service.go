@@ -0,0 +1,12 @@
+package notifications
+
+import "log"
+
+// Notify envia uma notificação ao usuário. Falhas são apenas logadas, para não
+// interromper o fluxo que originou o aviso.
+func Notify(userID int64, kind, title, message string) {
+	_, err := AddNotification(Notification{UserID: userID, Type: kind, Title: title, Message: message})
+	if err != nil {
+		log.Printf("Erro ao notificar o usuário %d (%s): %v", userID, kind, err)
+	}
+}
@@ -0,0 +1,9 @@
+package payments
+
+type CreateIntentRequest struct {
+	Amount float64 `json:"amount" binding:"required"`
+}
+
+type SimulateRequest struct {
+	Status string `json:"status" binding:"required"`
+}
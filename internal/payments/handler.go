@@ -0,0 +1,156 @@
+package payments
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/utils"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody limita o corpo aceito nas notificações dos provedores.
+const maxWebhookBody = 64 << 10
+
+// myIntent carrega a intenção da URL, respondendo 404 se ela não for do usuário.
+func myIntent(c *gin.Context) (Intent, bool) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return Intent{}, false
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return Intent{}, false
+	}
+	intent, err := GetIntent(id)
+	if errors.Is(err, ErrIntentNotFound) || (err == nil && intent.UserID != userID) {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Payment intent not found.", nil)
+		return Intent{}, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch payment intent.", err.Error())
+		return Intent{}, false
+	}
+	return intent, true
+}
+
+// CreateIntentHandler starts a deposit. The response carries the payment
+// data (for PIX, the copy-and-paste QR code payload); the balance is only
+// credited when the provider confirms the payment.
+func CreateIntentHandler(c *gin.Context) {
+	if _, ok := utils.CurrentUserID(c); !ok {
+		return
+	}
+	var req CreateIntentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	intent, err := CreateIntent(audit.ActorFromContext(c), req.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidAmount):
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Invalid deposit amount.",
+				gin.H{"min": MinDepositAmount, "max": MaxDepositAmount})
+		case errors.Is(err, ErrProviderNotConfigured):
+			utils.RespondError(c, http.StatusServiceUnavailable, "PAYMENTS_UNAVAILABLE", "Payments are not available.", nil)
+		case responsible_gaming.RespondPlayError(c, err):
+		default:
+			utils.RespondError(c, http.StatusInternalServerError, "PAYMENT_ERROR", "Failed to create payment.", err.Error())
+		}
+		return
+	}
+	utils.RespondSuccess(c, intent, "Payment created. The deposit is credited once the payment is confirmed.")
+}
+
+// GetMyIntentsHandler lists the caller's deposits, newest first.
+func GetMyIntentsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	page, limit := 1, 20
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	list, err := GetIntentsByUserID(userID, page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch payments.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, list, "Payments found")
+}
+
+// GetMyIntentHandler returns one of the caller's deposits, so clients can poll
+// for confirmation.
+func GetMyIntentHandler(c *gin.Context) {
+	intent, ok := myIntent(c)
+	if !ok {
+		return
+	}
+	utils.RespondSuccess(c, intent, "Payment found")
+}
+
+// SimulateIntentHandler emulates the provider paying ("confirmed") or
+// declining ("failed") the caller's pending deposit. It is disabled unless
+// PAYMENT_SIMULATION_ENABLED=true, and only test providers such as the PIX
+// stub support it; the notification goes through the same signed webhook path
+// as a real one.
+func SimulateIntentHandler(c *gin.Context) {
+	if !SimulationEnabled() {
+		utils.RespondError(c, http.StatusForbidden, "SIMULATION_DISABLED", "Payment simulation is disabled.", nil)
+		return
+	}
+	intent, ok := myIntent(c)
+	if !ok {
+		return
+	}
+	var req SimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Status != StatusConfirmed && req.Status != StatusFailed) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_STATUS", "Status must be confirmed or failed.", nil)
+		return
+	}
+	res, err := Simulate(audit.ActorFromContext(c), intent, req.Status)
+	switch {
+	case errors.Is(err, ErrSimulationUnsupported):
+		utils.RespondError(c, http.StatusNotImplemented, "SIMULATION_UNSUPPORTED", "This payment provider does not support simulation.", nil)
+	case errors.Is(err, ErrIntentNotPending):
+		utils.RespondError(c, http.StatusConflict, "INTENT_NOT_PENDING", "Payment is no longer pending.", gin.H{"status": intent.Status})
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "PAYMENT_ERROR", "Failed to process payment.", err.Error())
+	default:
+		utils.RespondSuccess(c, res, "Payment processed")
+	}
+}
+
+// WebhookHandler receives payment notifications from a provider. It is not
+// authenticated with a JWT; each provider verifies its own signature.
+func WebhookHandler(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Failed to read body.", err.Error())
+		return
+	}
+	res, err := HandleWebhook(audit.ActorFromContext(c), c.Param("provider"), c.Request.Header, body)
+	switch {
+	case errors.Is(err, ErrUnknownProvider):
+		utils.RespondError(c, http.StatusNotFound, "UNKNOWN_PROVIDER", "Unknown payment provider.", nil)
+	case errors.Is(err, ErrInvalidSignature):
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid webhook signature.", nil)
+	case errors.Is(err, ErrInvalidEvent):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_EVENT", "Invalid webhook event.", nil)
+	case errors.Is(err, ErrIntentNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Payment intent not found.", nil)
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "PAYMENT_ERROR", "Failed to process webhook.", err.Error())
+	default:
+		utils.RespondSuccess(c, res, "Webhook processed")
+	}
+}
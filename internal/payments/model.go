@@ -0,0 +1,179 @@
+package payments
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"time"
+)
+
+// Intent é uma intenção de depósito.
+type Intent struct {
+	ID            int64   `json:"id"`
+	UserID        int64   `json:"user_id"`
+	Provider      string  `json:"provider"`
+	Method        string  `json:"method"`
+	Amount        float64 `json:"amount"`
+	Status        string  `json:"status"`
+	ProviderRef   string  `json:"provider_ref"`
+	QRCode        string  `json:"qr_code,omitempty"`
+	FailureReason *string `json:"failure_reason"`
+	ExpiresAt     string  `json:"expires_at"`
+	ConfirmedAt   *string `json:"confirmed_at"`
+	RefundStatus  *string `json:"refund_status"`
+	CreatedAt     string  `json:"created_at"`
+
+	expiresAt time.Time
+}
+
+const intentColumns = `id, user_id, provider, method, amount, status, COALESCE(provider_ref, ''), COALESCE(qr_code, ''),
+	failure_reason, CAST(expires_at AS TEXT), CAST(confirmed_at AS TEXT), refund_status, CAST(created_at AS TEXT)`
+
+func scanIntent(row interface{ Scan(dest ...any) error }) (Intent, error) {
+	var i Intent
+	var reason, confirmedAt, refundStatus sql.NullString
+	err := row.Scan(&i.ID, &i.UserID, &i.Provider, &i.Method, &i.Amount, &i.Status, &i.ProviderRef, &i.QRCode,
+		&reason, &i.ExpiresAt, &confirmedAt, &refundStatus, &i.CreatedAt)
+	if reason.Valid {
+		i.FailureReason = &reason.String
+	}
+	if confirmedAt.Valid {
+		i.ConfirmedAt = &confirmedAt.String
+	}
+	if refundStatus.Valid {
+		i.RefundStatus = &refundStatus.String
+	}
+	return i, err
+}
+
+// GetIntent devolve a intenção pelo id.
+func GetIntent(id int64) (Intent, error) {
+	i, err := scanIntent(config.DB.QueryRow(`SELECT `+intentColumns+` FROM payment_intents WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return i, ErrIntentNotFound
+	}
+	return i, err
+}
+
+func getIntentByRef(tx *sql.Tx, provider, ref string) (Intent, error) {
+	i, err := scanIntent(tx.QueryRow(`SELECT `+intentColumns+` FROM payment_intents WHERE provider = ? AND provider_ref = ?`, provider, ref))
+	if err == sql.ErrNoRows {
+		return i, ErrIntentNotFound
+	}
+	return i, err
+}
+
+// GetIntentsByUserID lista as intenções do usuário, as mais recentes primeiro.
+func GetIntentsByUserID(userID int64, page, limit int) ([]Intent, error) {
+	rows, err := config.DB.Query(`
+		SELECT `+intentColumns+` FROM payment_intents
+		WHERE user_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Intent{}
+	for rows.Next() {
+		i, err := scanIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, i)
+	}
+	return list, rows.Err()
+}
+
+func insertIntent(i Intent) (int64, error) {
+	res, err := config.DB.Exec(`
+		INSERT INTO payment_intents (user_id, provider, method, amount, status, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'pending', ?, datetime('now'), datetime('now'))`,
+		i.UserID, i.Provider, i.Method, i.Amount, i.expiresAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func attachCharge(id int64, ch Charge) error {
+	_, err := config.DB.Exec(`
+		UPDATE payment_intents SET provider_ref = ?, qr_code = ?, expires_at = ?, updated_at = datetime('now')
+		WHERE id = ?`, ch.ProviderRef, ch.QRCode, ch.ExpiresAt.UTC().Format("2006-01-02 15:04:05"), id)
+	return err
+}
+
+// finishTx move a intenção de pending para status. Devolve false se ela já
+// tinha saído de pending, o que garante que a confirmação credite uma vez só.
+func finishTx(tx *sql.Tx, id int64, status, reason string) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE payment_intents
+		SET status = ?, failure_reason = NULLIF(?, ''),
+		    confirmed_at = CASE WHEN ? = 'confirmed' THEN datetime('now') END,
+		    updated_at = datetime('now')
+		WHERE id = ? AND status = 'pending'`, status, reason, status, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// markRefundTx marca o pagamento da intenção para devolução. Devolve false se
+// a devolução já tinha sido pedida, para que ela não seja feita duas vezes.
+func markRefundTx(tx *sql.Tx, id int64) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE payment_intents SET refund_status = 'pending', updated_at = datetime('now')
+		WHERE id = ? AND refund_status IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func markRefunded(id int64) error {
+	_, err := config.DB.Exec(`
+		UPDATE payment_intents SET refund_status = 'refunded', refunded_at = datetime('now'), updated_at = datetime('now')
+		WHERE id = ? AND refund_status = 'pending'`, id)
+	return err
+}
+
+// getPendingRefunds lista as intenções com devolução ainda não concluída.
+func getPendingRefunds() ([]Intent, error) {
+	rows, err := config.DB.Query(`SELECT ` + intentColumns + ` FROM payment_intents WHERE refund_status = 'pending' ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Intent{}
+	for rows.Next() {
+		i, err := scanIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, i)
+	}
+	return list, rows.Err()
+}
+
+// recordEventTx grava a notificação recebida. Devolve false se o evento já
+// tinha sido processado.
+func recordEventTx(tx *sql.Tx, provider string, ev WebhookEvent, intentID int64, outcome string) (bool, error) {
+	res, err := tx.Exec(`
+		INSERT OR IGNORE INTO payment_webhook_events (provider, event_id, intent_id, status, outcome, received_at)
+		VALUES (?, ?, ?, ?, ?, datetime('now'))`, provider, ev.EventID, intentID, ev.Status, outcome)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// expireStale marca como expiradas as intenções pendentes vencidas.
+func expireStale() (int64, error) {
+	res, err := config.DB.Exec(`
+		UPDATE payment_intents SET status = 'expired', updated_at = datetime('now')
+		WHERE status = 'pending' AND expires_at <= datetime('now')`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,268 @@
+package payments
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/testutil"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// setup cria um banco com um jogador e instala um PixStub como provedor ativo.
+func setup(t *testing.T) (*PixStub, int64) {
+	t.Helper()
+	testutil.DB(t)
+	stub, err := NewPixStub("", "segredo-de-teste")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousProviders, previousActive := providers, active
+	providers = map[string]PaymentProvider{}
+	Register(stub)
+	active = stub
+	t.Cleanup(func() { providers, active = previousProviders, previousActive })
+	return stub, testutil.User(t, "ana", 0)
+}
+
+func deposits(t *testing.T, userID int64) int {
+	t.Helper()
+	var n int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM transactions WHERE user_id = ? AND type = 'deposit'`, userID).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestWebhookCreditsOnce(t *testing.T) {
+	stub, user := setup(t)
+	intent, err := CreateIntent(audit.Actor{UserID: user}, 50)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	if got := testutil.Balance(t, user); got != 0 {
+		t.Fatalf("balance before confirmation = %v, want 0", got)
+	}
+
+	header, body, err := stub.SimulateWebhook(intent.ProviderRef, StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := HandleWebhook(audit.System, stub.Name(), header, body)
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if res.Outcome != OutcomeConfirmed {
+		t.Fatalf("outcome = %q, want %q", res.Outcome, OutcomeConfirmed)
+	}
+
+	// O mesmo evento reenviado não credita de novo
+	res, err = HandleWebhook(audit.System, stub.Name(), header, body)
+	if err != nil {
+		t.Fatalf("HandleWebhook (replay): %v", err)
+	}
+	if res.Outcome != OutcomeDuplicate {
+		t.Fatalf("replay outcome = %q, want %q", res.Outcome, OutcomeDuplicate)
+	}
+
+	// Um novo evento para a intenção já confirmada é ignorado
+	header, body, err = stub.SimulateWebhook(intent.ProviderRef, StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = HandleWebhook(audit.System, stub.Name(), header, body)
+	if err != nil {
+		t.Fatalf("HandleWebhook (second event): %v", err)
+	}
+	if res.Outcome != OutcomeIgnored {
+		t.Fatalf("second event outcome = %q, want %q", res.Outcome, OutcomeIgnored)
+	}
+
+	if got := testutil.Balance(t, user); got != 50 {
+		t.Fatalf("balance = %v, want 50", got)
+	}
+	if n := deposits(t, user); n != 1 {
+		t.Fatalf("%d deposit entries, want 1", n)
+	}
+}
+
+func TestWebhookFailedDoesNotCredit(t *testing.T) {
+	stub, user := setup(t)
+	intent, err := CreateIntent(audit.Actor{UserID: user}, 20)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	header, body, err := stub.SimulateWebhook(intent.ProviderRef, StatusFailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := HandleWebhook(audit.System, stub.Name(), header, body)
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if res.Status != StatusFailed || testutil.Balance(t, user) != 0 {
+		t.Fatalf("status %q and balance %v after a failed payment", res.Status, testutil.Balance(t, user))
+	}
+}
+
+func TestWebhookRejectsBadSignatures(t *testing.T) {
+	stub, user := setup(t)
+	intent, err := CreateIntent(audit.Actor{UserID: user}, 30)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	header, body, err := stub.SimulateWebhook(intent.ProviderRef, StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewPixStub("", "outro-segredo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := func(p *PixStub, at time.Time, body []byte) http.Header {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		h := http.Header{}
+		h.Set(PixSignatureHeader, "t="+ts+",v1="+hex.EncodeToString(p.sign(ts, body)))
+		return h
+	}
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-2] ^= 1
+
+	cases := map[string]struct {
+		header http.Header
+		body   []byte
+	}{
+		"missing header": {http.Header{}, body},
+		"wrong secret":   {signed(other, time.Now(), body), body},
+		"tampered body":  {header, tampered},
+		"stale":          {signed(stub, time.Now().Add(-time.Hour), body), body},
+		"future":         {signed(stub, time.Now().Add(time.Hour), body), body},
+	}
+	for name, tc := range cases {
+		if _, err := HandleWebhook(audit.System, stub.Name(), tc.header, tc.body); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: error = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+	if got := testutil.Balance(t, user); got != 0 {
+		t.Fatalf("balance = %v, want 0", got)
+	}
+}
+
+// flakyRefunds é um PixStub cujas devoluções falham enquanto err estiver definido.
+type flakyRefunds struct {
+	*PixStub
+	err   error
+	calls int
+}
+
+func (f *flakyRefunds) Refund(intent Intent) error {
+	f.calls++
+	return f.err
+}
+
+func refundStatus(t *testing.T, intentID int64) string {
+	t.Helper()
+	intent, err := GetIntent(intentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intent.RefundStatus == nil {
+		return ""
+	}
+	return *intent.RefundStatus
+}
+
+func TestLateConfirmationIsRefunded(t *testing.T) {
+	stub, user := setup(t)
+	provider := &flakyRefunds{PixStub: stub, err: errors.New("provider unavailable")}
+	providers[stub.Name()] = provider
+	active = provider
+
+	intent, err := CreateIntent(audit.Actor{UserID: user}, 40)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	if _, err := config.DB.Exec(`UPDATE payment_intents SET expires_at = datetime('now', '-1 minute') WHERE id = ?`, intent.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := expireStale(); err != nil || n != 1 {
+		t.Fatalf("expireStale = %d, %v; want 1", n, err)
+	}
+
+	header, body, err := stub.SimulateWebhook(intent.ProviderRef, StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := HandleWebhook(audit.System, stub.Name(), header, body)
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if res.Outcome != OutcomeLate || res.Status != StatusExpired {
+		t.Fatalf("result = %+v, want outcome %q on the expired intent", res, OutcomeLate)
+	}
+	if got := testutil.Balance(t, user); got != 0 {
+		t.Fatalf("balance = %v, want 0", got)
+	}
+	// A devolução falhou e continua pendente
+	if got := refundStatus(t, intent.ID); got != "pending" || provider.calls != 1 {
+		t.Fatalf("refund status %q after %d calls, want pending after 1", got, provider.calls)
+	}
+
+	// Outro evento de confirmação não pede a devolução de novo
+	header, body, err = stub.SimulateWebhook(intent.ProviderRef, StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := HandleWebhook(audit.System, stub.Name(), header, body); err != nil || res.Outcome != OutcomeIgnored {
+		t.Fatalf("second event = %+v, %v; want %q", res, err, OutcomeIgnored)
+	}
+	if provider.calls != 1 {
+		t.Fatalf("%d refund calls, want 1", provider.calls)
+	}
+
+	provider.err = nil
+	if n, err := RetryRefunds(); err != nil || n != 1 {
+		t.Fatalf("RetryRefunds = %d, %v; want 1", n, err)
+	}
+	if got := refundStatus(t, intent.ID); got != "refunded" {
+		t.Fatalf("refund status = %q, want refunded", got)
+	}
+	if n, err := RetryRefunds(); err != nil || n != 0 {
+		t.Fatalf("RetryRefunds after the refund = %d, %v; want 0", n, err)
+	}
+}
+
+func TestRejectedConfirmationIsRefunded(t *testing.T) {
+	stub, user := setup(t)
+	intent, err := CreateIntent(audit.Actor{UserID: user}, 25)
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	// O jogador se autoexclui antes de o pagamento ser confirmado
+	if _, err := responsible_gaming.Exclude(user, "24h"); err != nil {
+		t.Fatalf("Exclude: %v", err)
+	}
+
+	header, body, err := stub.SimulateWebhook(intent.ProviderRef, StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := HandleWebhook(audit.System, stub.Name(), header, body)
+	if err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if res.Outcome != OutcomeRejected || res.Status != StatusFailed {
+		t.Fatalf("result = %+v, want outcome %q", res, OutcomeRejected)
+	}
+	if got := testutil.Balance(t, user); got != 0 {
+		t.Fatalf("balance = %v, want 0", got)
+	}
+	if got := refundStatus(t, intent.ID); got != "refunded" {
+		t.Fatalf("refund status = %q, want refunded", got)
+	}
+}
@@ -0,0 +1,185 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pixStubName = "pix_stub"
+	// PixSignatureHeader leva "t=<unix>,v1=<hmac-sha256 hex de "t.corpo">".
+	PixSignatureHeader = "X-Pix-Signature"
+	// pixSignatureTolerance recusa notificações antigas reenviadas por terceiros.
+	pixSignatureTolerance = 5 * time.Minute
+	defaultPixKey         = "pagamentos@berrybet.local"
+	pixMerchantName       = "BERRY BET"
+	pixMerchantCity       = "PORTO ALEGRE"
+)
+
+// PixStub emula localmente um provedor PIX: gera o "copia e cola" no formato
+// BR Code do Banco Central e assina as notificações como um PSP faria, mas
+// nenhum dinheiro é movimentado. As confirmações vêm de SimulateWebhook.
+type PixStub struct {
+	key    string
+	secret []byte
+}
+
+// NewPixStub cria o provedor com a chave PIX e o segredo dos webhooks. Sem
+// segredo, um aleatório é gerado (suficiente para simulações no mesmo processo).
+func NewPixStub(key, secret string) (*PixStub, error) {
+	if key == "" {
+		key = defaultPixKey
+	}
+	s := []byte(secret)
+	if len(s) == 0 {
+		s = make([]byte, 32)
+		if _, err := rand.Read(s); err != nil {
+			return nil, err
+		}
+	}
+	return &PixStub{key: key, secret: s}, nil
+}
+
+func (p *PixStub) Name() string   { return pixStubName }
+func (p *PixStub) Method() string { return "pix" }
+
+// CreateCharge gera o txid e o BR Code da cobrança.
+func (p *PixStub) CreateCharge(intent Intent) (Charge, error) {
+	txid, err := randomAlnum(25)
+	if err != nil {
+		return Charge{}, err
+	}
+	return Charge{
+		ProviderRef: txid,
+		QRCode:      brCode(p.key, intent.Amount, txid),
+		ExpiresAt:   intent.expiresAt,
+	}, nil
+}
+
+// ParseWebhook confere a assinatura e o horário da notificação.
+func (p *PixStub) ParseWebhook(header http.Header, body []byte) (WebhookEvent, error) {
+	var ts, sig string
+	for _, part := range strings.Split(header.Get(PixSignatureHeader), ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+	if d := time.Since(time.Unix(unix, 0)); d > pixSignatureTolerance || d < -pixSignatureTolerance {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, p.sign(ts, body)) {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	var ev struct {
+		EventID string `json:"event_id"`
+		TxID    string `json:"txid"`
+		Status  string `json:"status"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &ev); err != nil || ev.EventID == "" || ev.TxID == "" {
+		return WebhookEvent{}, ErrInvalidEvent
+	}
+	return WebhookEvent{EventID: ev.EventID, ProviderRef: ev.TxID, Status: ev.Status, Reason: ev.Reason}, nil
+}
+
+// Refund aceita a devolução sem movimentar dinheiro, como o resto do stub.
+func (p *PixStub) Refund(intent Intent) error {
+	return nil
+}
+
+// SimulateWebhook monta a notificação assinada que o PSP enviaria quando a
+// cobrança fosse paga (confirmed) ou recusada (failed).
+func (p *PixStub) SimulateWebhook(txid, status string) (http.Header, []byte, error) {
+	eventID, err := randomAlnum(20)
+	if err != nil {
+		return nil, nil, err
+	}
+	ev := map[string]string{"event_id": eventID, "txid": txid, "status": status}
+	if status == StatusFailed {
+		ev["reason"] = "simulated_failure"
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return nil, nil, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header := http.Header{}
+	header.Set(PixSignatureHeader, "t="+ts+",v1="+hex.EncodeToString(p.sign(ts, body)))
+	return header, body, nil
+}
+
+func (p *PixStub) sign(ts string, body []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// brCode monta o payload EMV do PIX dinâmico ("copia e cola").
+func brCode(key string, amount float64, txid string) string {
+	payload := emv("00", "01") +
+		emv("01", "12") +
+		emv("26", emv("00", "br.gov.bcb.pix")+emv("01", key)) +
+		emv("52", "0000") +
+		emv("53", "986") +
+		emv("54", strconv.FormatFloat(amount, 'f', 2, 64)) +
+		emv("58", "BR") +
+		emv("59", pixMerchantName) +
+		emv("60", pixMerchantCity) +
+		emv("62", emv("05", txid)) +
+		"6304"
+	return payload + fmt.Sprintf("%04X", crc16(payload))
+}
+
+func emv(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// crc16 é o CRC-16/CCITT-FALSE exigido pelo BR Code.
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for b := 0; b < 8; b++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+const alnum = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+func randomAlnum(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = alnum[int(b[i])%len(alnum)]
+	}
+	return string(b), nil
+}
+
+var _ Simulator = (*PixStub)(nil)
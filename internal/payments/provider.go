@@ -0,0 +1,106 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// PaymentProvider integra um meio de pagamento externo. O provedor cria a
+// cobrança e depois avisa o resultado por webhook; nada é creditado antes
+// da confirmação.
+type PaymentProvider interface {
+	// Name identifica o provedor na URL do webhook e nas intenções gravadas.
+	Name() string
+	// Method é o meio de pagamento oferecido (por exemplo, "pix").
+	Method() string
+	// CreateCharge registra a cobrança da intenção no provedor.
+	CreateCharge(intent Intent) (Charge, error)
+	// ParseWebhook verifica a assinatura da notificação e a converte em evento.
+	// Deve devolver ErrInvalidSignature se a assinatura não conferir.
+	ParseWebhook(header http.Header, body []byte) (WebhookEvent, error)
+	// Refund devolve ao pagador um pagamento confirmado que não foi creditado
+	// (recusado pelo jogo responsável ou recebido depois de a intenção
+	// expirar). Falhas são tentadas de novo, então a devolução deve ser
+	// idempotente por intenção.
+	Refund(intent Intent) error
+}
+
+// Simulator é implementado por provedores de teste que conseguem gerar a
+// notificação assinada de um pagamento, como se ele tivesse acontecido.
+type Simulator interface {
+	SimulateWebhook(providerRef, status string) (http.Header, []byte, error)
+}
+
+// Charge é a cobrança criada no provedor.
+type Charge struct {
+	ProviderRef string
+	// QRCode é o conteúdo a ser pago (no PIX, o "copia e cola" do QR code).
+	QRCode    string
+	ExpiresAt time.Time
+}
+
+// WebhookEvent é o resultado de um pagamento informado pelo provedor.
+type WebhookEvent struct {
+	EventID     string `json:"event_id"`
+	ProviderRef string `json:"provider_ref"`
+	Status      string `json:"status"` // confirmed ou failed
+	Reason      string `json:"reason,omitempty"`
+}
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidEvent     = errors.New("invalid webhook event")
+	ErrUnknownProvider  = errors.New("unknown payment provider")
+)
+
+var (
+	providers = map[string]PaymentProvider{}
+	active    PaymentProvider
+)
+
+// Register disponibiliza um provedor para webhooks.
+func Register(p PaymentProvider) {
+	providers[p.Name()] = p
+}
+
+// Provider devolve o provedor registrado com o nome informado.
+func Provider(name string) (PaymentProvider, error) {
+	p, ok := providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Configure registra o provedor escolhido em PAYMENT_PROVIDER, usado em novos
+// depósitos e nos webhooks. A variável é obrigatória: sem ela o servidor não
+// sobe, para que o stub nunca seja usado por acidente.
+func Configure() error {
+	name := os.Getenv("PAYMENT_PROVIDER")
+	switch name {
+	case "":
+		return errors.New("PAYMENT_PROVIDER is not set")
+	case "pix_stub":
+		stub, err := NewPixStub(os.Getenv("PIX_KEY"), os.Getenv("PIX_STUB_WEBHOOK_SECRET"))
+		if err != nil {
+			return err
+		}
+		Register(stub)
+	}
+	p, err := Provider(name)
+	if err != nil {
+		return fmt.Errorf("PAYMENT_PROVIDER %q: %w", name, err)
+	}
+	active = p
+	return nil
+}
+
+// SimulationEnabled indica se a simulação de pagamentos está liberada
+// (PAYMENT_SIMULATION_ENABLED=true). Desligada por padrão: só faz sentido em
+// ambientes de teste, com um provedor que implemente Simulator.
+func SimulationEnabled() bool {
+	return os.Getenv("PAYMENT_SIMULATION_ENABLED") == "true"
+}
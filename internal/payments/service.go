@@ -0,0 +1,321 @@
+package payments
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/wallet"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusFailed    = "failed"
+	StatusExpired   = "expired"
+)
+
+const (
+	MinDepositAmount = 1.0
+	MaxDepositAmount = 50000.0
+	// IntentTTL é a validade da cobrança; depois disso ela não pode mais ser paga.
+	IntentTTL = 30 * time.Minute
+)
+
+var (
+	ErrIntentNotFound        = errors.New("payment intent not found")
+	ErrInvalidAmount         = errors.New("invalid deposit amount")
+	ErrIntentNotPending      = errors.New("payment intent is no longer pending")
+	ErrSimulationUnsupported = errors.New("payment provider does not support simulation")
+	ErrProviderNotConfigured = errors.New("payment provider not configured")
+)
+
+// Webhook outcomes gravados em payment_webhook_events.
+const (
+	OutcomeConfirmed = "confirmed"
+	OutcomeFailed    = "failed"
+	OutcomeRejected  = "rejected"  // pago, mas recusado pelas regras de jogo responsável; devolvido
+	OutcomeLate      = "late"      // pago depois de a intenção expirar; devolvido
+	OutcomeIgnored   = "ignored"   // intenção já finalizada
+	OutcomeDuplicate = "duplicate" // evento já processado
+)
+
+// WebhookResult resume o efeito de uma notificação.
+type WebhookResult struct {
+	IntentID int64  `json:"intent_id"`
+	Status   string `json:"status"`
+	Outcome  string `json:"outcome"`
+}
+
+// CreateIntent cria a intenção de depósito e a cobrança no provedor ativo.
+// Os limites de depósito e a autoexclusão são verificados já aqui, para o
+// jogador não pagar uma cobrança que seria recusada, e de novo na confirmação.
+func CreateIntent(actor audit.Actor, amount float64) (Intent, error) {
+	if active == nil {
+		return Intent{}, ErrProviderNotConfigured
+	}
+	if amount < MinDepositAmount || amount > MaxDepositAmount || math.Round(amount*100) != amount*100 {
+		return Intent{}, ErrInvalidAmount
+	}
+	if err := responsible_gaming.CheckDepositTx(config.DB, actor.UserID, amount); err != nil {
+		return Intent{}, err
+	}
+	intent := Intent{
+		UserID:    actor.UserID,
+		Provider:  active.Name(),
+		Method:    active.Method(),
+		Amount:    amount,
+		expiresAt: time.Now().Add(IntentTTL),
+	}
+	id, err := insertIntent(intent)
+	if err != nil {
+		return Intent{}, err
+	}
+	intent.ID = id
+	charge, err := active.CreateCharge(intent)
+	if err == nil {
+		err = attachCharge(id, charge)
+	}
+	if err != nil {
+		config.DB.Exec(`UPDATE payment_intents SET status = 'failed', failure_reason = 'provider_error', updated_at = datetime('now') WHERE id = ?`, id)
+		return Intent{}, fmt.Errorf("create charge: %w", err)
+	}
+	if err := audit.Record(audit.Entry{
+		Actor:      actor,
+		Action:     "payment.intent_created",
+		TargetType: "payment_intent",
+		TargetID:   id,
+		Details:    map[string]any{"provider": intent.Provider, "amount": amount},
+	}); err != nil {
+		log.Printf("Erro ao auditar intenção de pagamento %d: %v", id, err)
+	}
+	return GetIntent(id)
+}
+
+// HandleWebhook processa uma notificação do provedor. A confirmação credita
+// a carteira na mesma transação SQL que tira a intenção de pending, então
+// reenvios e notificações concorrentes nunca creditam duas vezes. Pagamentos
+// que não podem ser creditados (recusados pelo jogo responsável ou recebidos
+// depois de a intenção expirar) são devolvidos pelo provedor.
+func HandleWebhook(actor audit.Actor, providerName string, header http.Header, body []byte) (WebhookResult, error) {
+	p, err := Provider(providerName)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	ev, err := p.ParseWebhook(header, body)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	if ev.Status != StatusConfirmed && ev.Status != StatusFailed {
+		return WebhookResult{}, ErrInvalidEvent
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	defer tx.Rollback()
+	intent, err := getIntentByRef(tx, p.Name(), ev.ProviderRef)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	res := WebhookResult{IntentID: intent.ID, Status: intent.Status, Outcome: OutcomeIgnored}
+	reason := ev.Reason
+	var granted *bonus.UserBonus
+
+	if intent.Status == StatusPending {
+		to := ev.Status
+		if to == StatusConfirmed {
+			if err := responsible_gaming.CheckDepositTx(tx, intent.UserID, intent.Amount); err != nil {
+				if reason = rejectionReason(err); reason == "" {
+					return res, err
+				}
+				to = StatusFailed
+			}
+		}
+		ok, err := finishTx(tx, intent.ID, to, reason)
+		if err != nil {
+			return res, err
+		}
+		if ok {
+			res.Status = to
+			res.Outcome = to
+			if to == StatusConfirmed {
+				desc := fmt.Sprintf("Depósito %s #%d", intent.Method, intent.ID)
+				if _, err := wallet.Credit(tx, intent.UserID, intent.Amount, "deposit", desc); err != nil {
+					return res, err
+				}
+				if granted, err = bonus.GrantDepositMatchTx(tx, intent.UserID, intent.Amount); err != nil {
+					return res, err
+				}
+			} else if ev.Status == StatusConfirmed {
+				res.Outcome = OutcomeRejected
+				if _, err := markRefundTx(tx, intent.ID); err != nil {
+					return res, err
+				}
+			}
+		}
+	} else if intent.Status == StatusExpired && ev.Status == StatusConfirmed {
+		// Pago depois de a cobrança vencer: o valor é devolvido, não creditado.
+		ok, err := markRefundTx(tx, intent.ID)
+		if err != nil {
+			return res, err
+		}
+		if ok {
+			res.Outcome = OutcomeLate
+		}
+	}
+	if res.Outcome != OutcomeIgnored {
+		err = audit.RecordTx(tx, audit.Entry{
+			Actor:      actor,
+			Action:     "payment." + res.Outcome,
+			TargetType: "payment_intent",
+			TargetID:   intent.ID,
+			Details:    map[string]any{"user_id": intent.UserID, "amount": intent.Amount, "event_id": ev.EventID, "reason": reason},
+		})
+		if err != nil {
+			return res, err
+		}
+	}
+
+	fresh, err := recordEventTx(tx, p.Name(), ev, intent.ID, res.Outcome)
+	if err != nil {
+		return res, err
+	}
+	if !fresh {
+		// O mesmo evento já foi processado; desfaz tudo o que foi feito acima.
+		tx.Rollback()
+		current, err := GetIntent(intent.ID)
+		if err != nil {
+			return res, err
+		}
+		return WebhookResult{IntentID: intent.ID, Status: current.Status, Outcome: OutcomeDuplicate}, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return res, err
+	}
+	if res.Outcome == OutcomeRejected || res.Outcome == OutcomeLate {
+		if err := refund(p, intent); err != nil {
+			log.Printf("Erro ao devolver o pagamento da intenção %d: %v", intent.ID, err)
+		}
+	}
+	notifyOutcome(intent, res.Outcome)
+	if granted != nil {
+		notifications.Notify(intent.UserID, "bonus", "Bônus de boas-vindas",
+			fmt.Sprintf("Você ganhou R$ %.2f de bônus. Aposte R$ %.2f para liberar o saque.", granted.GrantedAmount, granted.WageringRequired))
+	}
+	return res, nil
+}
+
+// rejectionReason traduz a recusa das regras de jogo responsável; devolve ""
+// para outros erros.
+func rejectionReason(err error) string {
+	var exclusionErr *responsible_gaming.ExclusionError
+	if errors.As(err, &exclusionErr) {
+		return "self_excluded"
+	}
+	var limitErr *responsible_gaming.LimitError
+	if errors.As(err, &limitErr) {
+		return "deposit_limit"
+	}
+	return ""
+}
+
+func notifyOutcome(intent Intent, outcome string) {
+	amount := fmt.Sprintf("R$ %.2f", intent.Amount)
+	switch outcome {
+	case OutcomeConfirmed:
+		notifications.Notify(intent.UserID, "payment", "Depósito confirmado",
+			"Seu depósito de "+amount+" foi confirmado e já está no seu saldo.")
+	case OutcomeRejected:
+		notifications.Notify(intent.UserID, "payment", "Depósito não creditado",
+			"Seu depósito de "+amount+" ultrapassa os seus limites de jogo responsável e será devolvido.")
+	case OutcomeLate:
+		notifications.Notify(intent.UserID, "payment", "Depósito não creditado",
+			"O pagamento do seu depósito de "+amount+" chegou depois do vencimento da cobrança e será devolvido.")
+	case OutcomeFailed:
+		notifications.Notify(intent.UserID, "payment", "Depósito não concluído",
+			"O pagamento do seu depósito de "+amount+" não foi concluído.")
+	}
+}
+
+// refund pede ao provedor a devolução do pagamento da intenção. Se falhar, a
+// devolução continua pendente e é tentada de novo por RetryRefunds.
+func refund(p PaymentProvider, intent Intent) error {
+	if err := p.Refund(intent); err != nil {
+		return err
+	}
+	return markRefunded(intent.ID)
+}
+
+// RetryRefunds tenta de novo as devoluções pendentes e devolve quantas deram certo.
+func RetryRefunds() (int, error) {
+	pending, err := getPendingRefunds()
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	for _, intent := range pending {
+		p, err := Provider(intent.Provider)
+		if err != nil {
+			log.Printf("Devolução da intenção %d: %v", intent.ID, err)
+			continue
+		}
+		if err := refund(p, intent); err != nil {
+			log.Printf("Erro ao devolver o pagamento da intenção %d: %v", intent.ID, err)
+			continue
+		}
+		done++
+	}
+	return done, nil
+}
+
+// Simulate gera e processa a notificação que o provedor enviaria para a
+// intenção. Só funciona com provedores de teste (Simulator).
+func Simulate(actor audit.Actor, intent Intent, status string) (WebhookResult, error) {
+	p, err := Provider(intent.Provider)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	sim, ok := p.(Simulator)
+	if !ok {
+		return WebhookResult{}, ErrSimulationUnsupported
+	}
+	if intent.Status != StatusPending {
+		return WebhookResult{}, ErrIntentNotPending
+	}
+	header, body, err := sim.SimulateWebhook(intent.ProviderRef, status)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+	return HandleWebhook(actor, p.Name(), header, body)
+}
+
+// StartWorker expira periodicamente as intenções pendentes vencidas e tenta
+// de novo as devoluções pendentes.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := RetryRefunds(); err != nil {
+				log.Printf("Erro ao devolver pagamentos pendentes: %v", err)
+			}
+			n, err := expireStale()
+			if err != nil {
+				log.Printf("Erro ao expirar intenções de pagamento: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d intenção(ões) de pagamento expirada(s)", n)
+			}
+		}
+	}()
+}
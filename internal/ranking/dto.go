@@ -0,0 +1,52 @@
+package ranking
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Cursor identifica a última linha devolvida em uma página do ranking.
+type Cursor struct {
+	Rank   int
+	Score  float64
+	UserID int64
+}
+
+// RankingResponse mantém a lista em "data" para compatibilidade com o formato anterior.
+type RankingResponse struct {
+	Success    bool            `json:"success"`
+	Metric     string          `json:"metric"`
+	Period     string          `json:"period"`
+	Game       string          `json:"game,omitempty"`
+	Data       []RankingPlayer `json:"data"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Me         *RankingPlayer  `json:"me,omitempty"`
+}
+
+// EncodeCursor serializa a posição da última linha da página.
+func EncodeCursor(p RankingPlayer) string {
+	raw := strconv.Itoa(p.Rank) + "|" +
+		strconv.FormatFloat(p.Score, 'g', -1, 64) + "|" +
+		strconv.FormatInt(p.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor interpreta um cursor gerado por EncodeCursor.
+func DecodeCursor(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+	rank, err1 := strconv.Atoi(parts[0])
+	score, err2 := strconv.ParseFloat(parts[1], 64)
+	userID, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || rank < 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{Rank: rank, Score: score, UserID: userID}, nil
+}
@@ -0,0 +1,70 @@
+package ranking
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"berry_bet/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLimit   = 10
+	maxLimit       = 100
+	defaultMinBets = 10
+)
+
+// GetRankingHandler returns a ranking page for the requested metric, period and game,
+// plus the caller's own position when a valid token is sent.
+func GetRankingHandler(c *gin.Context) {
+	q := Query{
+		Metric:  strings.ToLower(c.Query("metric")),
+		Period:  strings.ToLower(c.Query("period")),
+		Game:    strings.ToLower(strings.TrimSpace(c.Query("game"))),
+		MinBets: defaultMinBets,
+		Limit:   defaultLimit,
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= maxLimit {
+		q.Limit = l
+	}
+	if m, err := strconv.Atoi(c.Query("min_bets")); err == nil && m >= 0 {
+		q.MinBets = m
+	}
+	if err := q.Validate(); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_QUERY", err.Error(), nil)
+		return
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_CURSOR", err.Error(), nil)
+			return
+		}
+		q.Cursor = decoded
+	}
+
+	players, err := GetRanking(q)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch ranking.", err.Error())
+		return
+	}
+	resp := RankingResponse{Success: true, Metric: q.Metric, Period: q.Period, Game: q.Game, Data: players}
+	if len(players) == q.Limit {
+		resp.NextCursor = EncodeCursor(players[len(players)-1])
+	}
+
+	if userID, ok := c.Get("userID"); ok {
+		if id, ok := userID.(int64); ok {
+			me, err := GetPlayerPosition(q, id)
+			if err != nil {
+				utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user position.", err.Error())
+				return
+			}
+			resp.Me = me
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
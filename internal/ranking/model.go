@@ -0,0 +1,224 @@
+package ranking
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidMetric = errors.New("metric must be balance, profit, wins, wager or win_rate")
+	ErrInvalidPeriod = errors.New("period must be day, week, month or all")
+	ErrBalancePeriod = errors.New("balance ranking is only available for period=all without game filter")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Query descreve os filtros de uma consulta de ranking.
+type Query struct {
+	Metric  string
+	Period  string
+	Game    string
+	MinBets int
+	Limit   int
+	Cursor  *Cursor
+}
+
+// RankingPlayer é uma linha do ranking com as estatísticas do período consultado.
+type RankingPlayer struct {
+	Rank           int     `json:"rank"`
+	ID             int64   `json:"id"`
+	Username       string  `json:"username"`
+	Name           string  `json:"name"`
+	AvatarURL      string  `json:"avatar_url"`
+	Score          float64 `json:"score"`
+	Balance        float64 `json:"balance"`
+	TotalBets      int64   `json:"total_bets"`
+	TotalWins      int64   `json:"total_wins"`
+	TotalLosses    int64   `json:"total_losses"`
+	TotalProfit    float64 `json:"total_profit"`
+	TotalAmountBet float64 `json:"total_amount_bet"`
+	WinRate        float64 `json:"win_rate"`
+}
+
+// scoreExpressions mapeia cada métrica para a expressão SQL de pontuação.
+var scoreExpressions = map[string]string{
+	"balance":  "balance",
+	"profit":   "total_profit",
+	"wins":     "total_wins",
+	"wager":    "total_amount_bet",
+	"win_rate": "CASE WHEN total_bets > 0 THEN CAST(total_wins AS REAL) * 100 / total_bets ELSE 0 END",
+}
+
+// periodStart devolve a expressão SQL da data inicial do período (calendário UTC).
+func periodStart(period string) string {
+	switch period {
+	case "day":
+		return "date('now')"
+	case "week":
+		return "date('now', 'weekday 0', '-6 days')"
+	case "month":
+		return "date('now', 'start of month')"
+	}
+	return ""
+}
+
+// Validate aplica valores padrão e valida a combinação de filtros.
+func (q *Query) Validate() error {
+	if q.Metric == "" {
+		q.Metric = "balance"
+	}
+	if q.Period == "" {
+		q.Period = "all"
+	}
+	if _, ok := scoreExpressions[q.Metric]; !ok {
+		return ErrInvalidMetric
+	}
+	if q.Period != "all" && periodStart(q.Period) == "" {
+		return ErrInvalidPeriod
+	}
+	if q.Metric == "balance" && (q.Period != "all" || q.Game != "") {
+		return ErrBalancePeriod
+	}
+	return nil
+}
+
+// scoredCTE monta a CTE "scored" (user_id, totais, balance, score) para a consulta.
+// Rankings de todo o período sem filtro de jogo usam user_stats; com período usam
+// daily_metrics; com filtro de jogo usam bet_history.
+func (q *Query) scoredCTE() (string, []any) {
+	var agg string
+	var args []any
+
+	switch {
+	case q.Game != "":
+		agg = `
+			SELECT user_id,
+				COUNT(*) AS total_bets,
+				SUM(CASE WHEN result = 'win' THEN 1 ELSE 0 END) AS total_wins,
+				SUM(CASE WHEN result = 'loss' THEN 1 ELSE 0 END) AS total_losses,
+				SUM(profit_loss) AS total_profit,
+				SUM(bet_amount) AS total_amount_bet
+			FROM bet_history
+			WHERE game_type = ?`
+		args = append(args, q.Game)
+		if start := periodStart(q.Period); start != "" {
+			agg += " AND created_at >= " + start
+		}
+		agg += " GROUP BY user_id"
+	case q.Period != "all":
+		agg = `
+			SELECT user_id,
+				SUM(bets_count) AS total_bets,
+				SUM(wins_count) AS total_wins,
+				SUM(losses_count) AS total_losses,
+				SUM(total_profit) AS total_profit,
+				SUM(total_bet_amount) AS total_amount_bet
+			FROM daily_metrics
+			WHERE date >= ` + periodStart(q.Period) + `
+			GROUP BY user_id`
+	default:
+		agg = `
+			SELECT user_id, total_bets, total_wins, total_losses, total_profit, total_amount_bet
+			FROM user_stats`
+	}
+
+	minBets := ""
+	if q.Metric == "win_rate" {
+		minBets = " WHERE total_bets >= ?"
+		args = append(args, q.MinBets)
+	}
+
+	cte := `
+		WITH agg AS (` + agg + `),
+		with_balance AS (
+			SELECT agg.*, COALESCE(us.balance, 0) AS balance
+			FROM agg LEFT JOIN user_stats us ON us.user_id = agg.user_id
+		),
+		scored AS (
+			SELECT with_balance.*, ` + scoreExpressions[q.Metric] + ` AS score
+			FROM with_balance` + minBets + `
+		)`
+	return cte, args
+}
+
+const playerColumns = `u.id, u.username, u.name, COALESCE(u.avatar_url, ''), s.score, s.balance,
+	s.total_bets, s.total_wins, s.total_losses, s.total_profit, s.total_amount_bet`
+
+func scanPlayer(row interface{ Scan(dest ...any) error }) (RankingPlayer, error) {
+	var p RankingPlayer
+	err := row.Scan(&p.ID, &p.Username, &p.Name, &p.AvatarURL, &p.Score, &p.Balance,
+		&p.TotalBets, &p.TotalWins, &p.TotalLosses, &p.TotalProfit, &p.TotalAmountBet)
+	if p.TotalBets > 0 {
+		p.WinRate = float64(p.TotalWins) * 100 / float64(p.TotalBets)
+	}
+	return p, err
+}
+
+// GetRanking retorna uma página do ranking, ordenada por pontuação e ID do usuário.
+func GetRanking(q Query) ([]RankingPlayer, error) {
+	cte, args := q.scoredCTE()
+
+	var sb strings.Builder
+	sb.WriteString(cte)
+	sb.WriteString(`
+		SELECT ` + playerColumns + `
+		FROM scored s
+		JOIN users u ON u.id = s.user_id`)
+	rank := 0
+	if q.Cursor != nil {
+		sb.WriteString(" WHERE (s.score < ? OR (s.score = ? AND u.id > ?))")
+		args = append(args, q.Cursor.Score, q.Cursor.Score, q.Cursor.UserID)
+		rank = q.Cursor.Rank
+	}
+	sb.WriteString(" ORDER BY s.score DESC, u.id ASC LIMIT ?")
+	args = append(args, q.Limit)
+
+	rows, err := config.DB.Query(sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := make([]RankingPlayer, 0)
+	for rows.Next() {
+		p, err := scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		rank++
+		p.Rank = rank
+		players = append(players, p)
+	}
+	return players, rows.Err()
+}
+
+// GetPlayerPosition retorna a linha do ranking de um usuário específico, com sua
+// posição absoluta. Retorna nil se o usuário não aparece no ranking consultado.
+func GetPlayerPosition(q Query, userID int64) (*RankingPlayer, error) {
+	cte, args := q.scoredCTE()
+	row := config.DB.QueryRow(cte+`
+		SELECT `+playerColumns+`
+		FROM scored s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.user_id = ?`, append(args, userID)...)
+	p, err := scanPlayer(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cte, args = q.scoredCTE()
+	var ahead int
+	err = config.DB.QueryRow(cte+`
+		SELECT COUNT(*) FROM scored
+		WHERE score > ? OR (score = ? AND user_id < ?)`,
+		append(args, p.Score, p.Score, userID)...).Scan(&ahead)
+	if err != nil {
+		return nil, err
+	}
+	p.Rank = ahead + 1
+	return &p, nil
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+// full indica se o balde já teria se reabastecido por completo.
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.updatedAt).Seconds()*b.limit.rate() >= b.limit.capacity()
+}
+
+// MemoryStore guarda os baldes na memória do processo.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), lastPrune: time.Now()}
+}
+
+// pruneInterval é o intervalo entre as remoções de baldes já cheios, que
+// equivalem a baldes novos.
+const pruneInterval = 10 * time.Minute
+
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) > pruneInterval {
+		for k, b := range s.buckets {
+			if b.full(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	capacity, rate := limit.capacity(), limit.rate()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now, limit: limit}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*rate)
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newResult(allowed, b.tokens, limit), nil
+}
+
+// newResult monta o Result a partir das fichas restantes no balde.
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	capacity, rate := limit.capacity(), limit.rate()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     int(capacity),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((capacity - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return r
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"berry_bet/internal/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc identifica o cliente de uma requisição.
+type KeyFunc func(c *gin.Context) string
+
+// ByIP identifica o cliente pelo IP de origem.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser identifica o cliente pelo usuário autenticado, ou pelo IP quando não
+// há usuário. Deve vir depois do middleware JWT.
+func ByUser(c *gin.Context) string {
+	if id, ok := c.Get("userID"); ok {
+		if userID, ok := id.(int64); ok {
+			return "user:" + strconv.FormatInt(userID, 10)
+		}
+	}
+	return ByIP(c)
+}
+
+// apiKeys guarda o hash das chaves de API emitidas. Só elas ganham balde
+// próprio; qualquer outro valor de X-API-Key é ignorado.
+var apiKeys = map[string]bool{}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SetAPIKeys registra as chaves de API emitidas, substituindo as anteriores.
+// Valores vazios são ignorados.
+func SetAPIKeys(keys []string) {
+	apiKeys = map[string]bool{}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			apiKeys[hashAPIKey(key)] = true
+		}
+	}
+}
+
+// ByAPIKey identifica o cliente pelo header X-API-Key quando ele corresponde a
+// uma chave emitida (ver SetAPIKeys). Sem isso, cada valor inventado teria um
+// balde novo e burlaria o limite, então o cliente é identificado pelo usuário
+// ou pelo IP (ByUser).
+func ByAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		if hash := hashAPIKey(key); apiKeys[hash] {
+			return "key:" + hash
+		}
+	}
+	return ByUser(c)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Middleware aplica a política de rate limit informada, contando cada cliente
+// (identificado por key) em um balde próprio. Falhas do armazenamento não
+// bloqueiam a requisição.
+func Middleware(name string, key KeyFunc) gin.HandlerFunc {
+	limit := policy(name)
+	policyHeader := strconv.Itoa(int(limit.capacity())) + ";w=" + seconds(time.Duration(limit.capacity()/limit.rate()*float64(time.Second)))
+	return func(c *gin.Context) {
+		res, err := store.Take(name+":"+key(c), limit)
+		if err != nil {
+			log.Printf("Erro no rate limiter (%s): %v", name, err)
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Policy", policyHeader)
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.Reset))
+		if !res.Allowed {
+			c.Header("Retry-After", seconds(res.RetryAfter))
+			utils.RespondError(c, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests. Please slow down.",
+				gin.H{"policy": name, "retry_after": int(math.Ceil(res.RetryAfter.Seconds()))})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
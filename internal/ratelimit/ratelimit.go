@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit define um balde de fichas: Requests fichas são repostas a cada Period
+// e o balde comporta no máximo Burst fichas (Requests, se Burst for zero).
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// rate devolve quantas fichas são repostas por segundo.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result é o estado do balde após uma requisição.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // espera até a próxima ficha (apenas quando recusada)
+	Reset      time.Duration // espera até o balde voltar a ficar cheio
+}
+
+// Store guarda os baldes de fichas.
+type Store interface {
+	// Take consome uma ficha do balde da chave, se houver.
+	Take(key string, limit Limit) (Result, error)
+}
+
+// Policies são os limites padrão de cada grupo de rotas. Podem ser ajustados
+// pela variável RATE_LIMIT_<NOME> no formato "requisições/período[,burst]",
+// por exemplo RATE_LIMIT_LOGIN="10/1m,5".
+var Policies = map[string]Limit{
+	"default":        {Requests: 300, Period: time.Minute},
+	"login":          {Requests: 10, Period: time.Minute, Burst: 5},
+	"register":       {Requests: 5, Period: time.Hour, Burst: 3},
+	"password_reset": {Requests: 5, Period: time.Hour, Burst: 3},
+	"refresh":        {Requests: 30, Period: time.Minute},
+	"bet":            {Requests: 30, Period: time.Minute, Burst: 10},
+	"payment_intent": {Requests: 10, Period: time.Minute, Burst: 5},
+}
+
+// ParseLimit interpreta o formato "requisições/período[,burst]".
+func ParseLimit(s string) (Limit, error) {
+	var l Limit
+	spec, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ",")
+	requests, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return l, fmt.Errorf("invalid rate limit %q: expected requests/period", s)
+	}
+	var err error
+	if l.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || l.Requests <= 0 {
+		return l, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	if l.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || l.Period <= 0 {
+		return l, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+	if hasBurst {
+		if l.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || l.Burst <= 0 {
+			return l, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", s)
+		}
+	}
+	return l, nil
+}
+
+// policy devolve o limite da política, aplicando a configuração do ambiente.
+func policy(name string) Limit {
+	limit, ok := Policies[name]
+	if !ok {
+		limit = Policies["default"]
+	}
+	if env := os.Getenv("RATE_LIMIT_" + strings.ToUpper(name)); env != "" {
+		parsed, err := ParseLimit(env)
+		if err != nil {
+			log.Printf("Ignorando RATE_LIMIT_%s: %v", strings.ToUpper(name), err)
+			return limit
+		}
+		return parsed
+	}
+	return limit
+}
+
+var store Store = NewMemoryStore()
+
+// Configure escolhe onde os baldes são guardados: "memory" (padrão, por
+// processo) ou "sqlite" (compartilhado entre processos pelo banco).
+func Configure(kind string) {
+	switch strings.ToLower(kind) {
+	case "", "memory":
+		store = NewMemoryStore()
+	case "sqlite":
+		store = NewSQLiteStore()
+	default:
+		log.Printf("RATE_LIMIT_STORE %q desconhecido, usando memória", kind)
+		store = NewMemoryStore()
+	}
+}
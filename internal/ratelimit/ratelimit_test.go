@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"berry_bet/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLimit(t *testing.T) {
+	got, err := ParseLimit(" 10/1m, 5 ")
+	if err != nil {
+		t.Fatalf("ParseLimit: %v", err)
+	}
+	if want := (Limit{Requests: 10, Period: time.Minute, Burst: 5}); got != want {
+		t.Fatalf("ParseLimit = %+v, want %+v", got, want)
+	}
+	for _, bad := range []string{"", "10", "0/1m", "10/0s", "10/abc", "10/1m,0", "-1/1m"} {
+		if _, err := ParseLimit(bad); err == nil {
+			t.Errorf("ParseLimit(%q) accepted an invalid limit", bad)
+		}
+	}
+}
+
+func TestPolicyEnvOverride(t *testing.T) {
+	t.Setenv("RATE_LIMIT_LOGIN", "3/1s")
+	if got := policy("login"); got != (Limit{Requests: 3, Period: time.Second}) {
+		t.Fatalf("policy(login) = %+v, want the env override", got)
+	}
+	t.Setenv("RATE_LIMIT_LOGIN", "nonsense")
+	if got := policy("login"); got != Policies["login"] {
+		t.Fatalf("policy(login) = %+v, want the default on a bad override", got)
+	}
+	if got := policy("unknown"); got != Policies["default"] {
+		t.Fatalf("policy(unknown) = %+v, want the default policy", got)
+	}
+}
+
+// testStore confere o comportamento comum aos dois armazenamentos: o burst é
+// consumido, depois as requisições são recusadas até a reposição, e cada chave
+// tem o seu balde.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	limit := Limit{Requests: 10, Period: time.Hour, Burst: 3}
+	for i := 0; i < 3; i++ {
+		res, err := s.Take("k", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != 2-i || res.Limit != 3 {
+			t.Fatalf("request %d: %+v, want allowed with %d remaining", i+1, res, 2-i)
+		}
+	}
+	res, err := s.Take("k", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed {
+		t.Fatal("request over the burst was allowed")
+	}
+	// Uma ficha a cada 6 minutos
+	if res.RetryAfter <= 5*time.Minute || res.RetryAfter > 6*time.Minute {
+		t.Fatalf("RetryAfter = %v, want about 6m", res.RetryAfter)
+	}
+	if res, _ := s.Take("other", limit); !res.Allowed {
+		t.Fatal("a different key shared the bucket")
+	}
+
+	// Com reposição rápida o balde volta a liberar
+	fast := Limit{Requests: 1, Period: 50 * time.Millisecond}
+	if res, _ := s.Take("fast", fast); !res.Allowed {
+		t.Fatal("first request was refused")
+	}
+	if res, _ := s.Take("fast", fast); res.Allowed {
+		t.Fatal("second immediate request was allowed")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if res, _ := s.Take("fast", fast); !res.Allowed {
+		t.Fatal("request after the refill was refused")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestSQLiteStore(t *testing.T) {
+	testutil.DB(t)
+	testStore(t, NewSQLiteStore())
+}
+
+func TestMiddlewareIgnoresUntrustedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("RATE_LIMIT_LOGIN", "2/1h")
+	previous := store
+	store = NewMemoryStore()
+	t.Cleanup(func() { store = previous })
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	r.POST("/login", Middleware("login", ByIP), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(forwarded string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if w := send(ip); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i+1, w.Code)
+		}
+	}
+	// Trocar o X-Forwarded-For não cria um balde novo
+	w := send("10.0.0.3")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("missing rate limit headers: %v", w.Header())
+	}
+}
+
+func TestByAPIKeyOnlyTrustsIssuedKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("RATE_LIMIT_DEFAULT", "2/1h")
+	previous := store
+	store = NewMemoryStore()
+	SetAPIKeys([]string{" issued-key ", ""})
+	t.Cleanup(func() {
+		store = previous
+		SetAPIKeys(nil)
+	})
+
+	r := gin.New()
+	r.GET("/", Middleware("default", ByAPIKey), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	for i, key := range []string{"random-1", "random-2"} {
+		if code := send(key); code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i+1, code)
+		}
+	}
+	// Uma chave inventada nova ainda conta no balde do IP
+	if code := send("random-3"); code != http.StatusTooManyRequests {
+		t.Fatalf("status %d with an unknown key, want 429", code)
+	}
+	// A chave emitida tem o seu próprio balde
+	if code := send("issued-key"); code != http.StatusOK {
+		t.Fatalf("status %d with the issued key, want 200", code)
+	}
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// SQLiteStore guarda os baldes na tabela rate_limit_buckets, para que vários
+// processos usando o mesmo banco compartilhem os limites.
+type SQLiteStore struct {
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewSQLiteStore() *SQLiteStore {
+	return &SQLiteStore{lastPrune: time.Now()}
+}
+
+// takeSQL recalcula e consome a ficha em um único comando, evitando corridas
+// entre processos.
+const takeSQL = `
+	INSERT INTO rate_limit_buckets (key, tokens, updated_at, allowed)
+	VALUES (:key, :capacity - 1, :now, 1)
+	ON CONFLICT(key) DO UPDATE SET
+		allowed = MIN(:capacity, tokens + MAX(0, :now - updated_at) * :rate) >= 1,
+		tokens = MIN(:capacity, tokens + MAX(0, :now - updated_at) * :rate)
+			- (MIN(:capacity, tokens + MAX(0, :now - updated_at) * :rate) >= 1),
+		updated_at = MAX(updated_at, :now)
+	RETURNING tokens, allowed`
+
+func (s *SQLiteStore) Take(key string, limit Limit) (Result, error) {
+	now := time.Now()
+	s.prune(now)
+
+	var tokens float64
+	var allowed bool
+	err := config.DB.QueryRow(takeSQL,
+		sql.Named("key", key),
+		sql.Named("capacity", limit.capacity()),
+		sql.Named("rate", limit.rate()),
+		sql.Named("now", float64(now.UnixNano())/float64(time.Second)),
+	).Scan(&tokens, &allowed)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(allowed, tokens, limit), nil
+}
+
+// prune remove periodicamente os baldes sem uso há mais de um dia.
+func (s *SQLiteStore) prune(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPrune) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.mu.Unlock()
+
+	cutoff := float64(now.Add(-24 * time.Hour).Unix())
+	if _, err := config.DB.Exec(`DELETE FROM rate_limit_buckets WHERE updated_at < ?`, cutoff); err != nil {
+		log.Printf("Erro ao limpar rate_limit_buckets: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package referrals
+
+import (
+	"berry_bet/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyReferralsHandler returns the caller's invite code, the current reward
+// rule and the invited players with the rewards earned.
+func GetMyReferralsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	summary, err := GetSummary(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch referrals.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, summary, "Referrals found")
+}
+
+// ListReferralsHandler lists referrals for the back-office, filtered by
+// ?status= (pending, qualified, rejected or all).
+func ListReferralsHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", "all")
+	switch status {
+	case "all":
+		status = ""
+	case StatusPending, StatusQualified, StatusRejected:
+	default:
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_STATUS", "Invalid status.", nil)
+		return
+	}
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	list, total, err := ListReferrals(status, page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch referrals.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"referrals": list, "total": total, "page": page, "limit": limit}, "Referrals found")
+}
@@ -0,0 +1,150 @@
+package referrals
+
+import (
+	"berry_bet/config"
+	"database/sql"
+)
+
+// Referral é um convite aceito no cadastro.
+type Referral struct {
+	ID              int64   `json:"id"`
+	ReferrerID      int64   `json:"referrer_id"`
+	RefereeID       int64   `json:"referee_id"`
+	RefereeUsername string  `json:"referee_username"`
+	Code            string  `json:"code"`
+	Status          string  `json:"status"`
+	RejectionReason *string `json:"rejection_reason"`
+	RewardAmount    float64 `json:"reward_amount"`
+	UserBonusID     *int64  `json:"user_bonus_id"`
+	CreatedAt       string  `json:"created_at"`
+	QualifiedAt     *string `json:"qualified_at"`
+}
+
+const referralColumns = `r.id, r.referrer_id, r.referee_id, u.username, r.code, r.status, r.rejection_reason,
+	r.reward_amount, r.user_bonus_id, CAST(r.created_at AS TEXT), CAST(r.qualified_at AS TEXT)`
+
+func listReferrals(where string, args ...any) ([]Referral, error) {
+	rows, err := config.DB.Query(`
+		SELECT `+referralColumns+`
+		FROM referrals r JOIN users u ON u.id = r.referee_id `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Referral{}
+	for rows.Next() {
+		var r Referral
+		err := rows.Scan(&r.ID, &r.ReferrerID, &r.RefereeID, &r.RefereeUsername, &r.Code, &r.Status, &r.RejectionReason,
+			&r.RewardAmount, &r.UserBonusID, &r.CreatedAt, &r.QualifiedAt)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// GetReferralsByReferrer lista os convidados do jogador, os mais recentes primeiro.
+func GetReferralsByReferrer(referrerID int64) ([]Referral, error) {
+	return listReferrals(`WHERE r.referrer_id = ? ORDER BY r.created_at DESC, r.id DESC`, referrerID)
+}
+
+// ListReferrals lista as indicações com o status informado ("" para todas).
+func ListReferrals(status string, page, limit int) ([]Referral, int, error) {
+	var total int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM referrals WHERE ? = '' OR status = ?`, status, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	list, err := listReferrals(`WHERE ? = '' OR r.status = ? ORDER BY r.created_at DESC, r.id DESC LIMIT ? OFFSET ?`,
+		status, status, limit, (page-1)*limit)
+	return list, total, err
+}
+
+func pendingReferrals() ([]Referral, error) {
+	return listReferrals(`WHERE r.status = 'pending' ORDER BY r.id`)
+}
+
+func getCode(userID int64) (string, error) {
+	var code sql.NullString
+	err := config.DB.QueryRow(`SELECT referral_code FROM users WHERE id = ?`, userID).Scan(&code)
+	return code.String, err
+}
+
+func setCode(userID int64, code string) (bool, error) {
+	res, err := config.DB.Exec(`UPDATE users SET referral_code = ? WHERE id = ? AND referral_code IS NULL`, code, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func getUserIDByCode(code string) (int64, error) {
+	var id int64
+	err := config.DB.QueryRow(`SELECT id FROM users WHERE referral_code = ?`, code).Scan(&id)
+	return id, err
+}
+
+func insertReferral(tx *sql.Tx, r Referral, ip, deviceID string) (int64, error) {
+	res, err := tx.Exec(`
+		INSERT INTO referrals (referrer_id, referee_id, code, status, rejection_reason, signup_ip, signup_device_id, created_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), datetime('now'))`,
+		r.ReferrerID, r.RefereeID, r.Code, r.Status, r.RejectionReason, ip, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func reject(tx *sql.Tx, id int64, reason string) (bool, error) {
+	res, err := tx.Exec(`UPDATE referrals SET status = 'rejected', rejection_reason = ? WHERE id = ? AND status = 'pending'`, reason, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// progress devolve o maior depósito e o número de apostas do convidado.
+func progress(userID int64) (float64, int, error) {
+	var deposit float64
+	var bets int
+	err := config.DB.QueryRow(`
+		SELECT
+			COALESCE((SELECT MAX(amount) FROM transactions WHERE user_id = ? AND type = 'deposit'), 0),
+			(SELECT COUNT(*) FROM transactions WHERE user_id = ? AND type = 'bet') +
+			(SELECT COUNT(*) FROM bets WHERE user_id = ?)`, userID, userID, userID).Scan(&deposit, &bets)
+	return deposit, bets, err
+}
+
+// sharesIdentity informa se padrinho e convidado têm o mesmo CPF, ou se
+// alguma sessão do padrinho usou o IP ou o aparelho do cadastro ou das
+// sessões do convidado. Devolve o motivo, ou "" se nada foi encontrado.
+func sharesIdentity(referrerID, refereeID int64, ip, deviceID string) (string, error) {
+	var sameCPF, sameIP, sameDevice bool
+	err := config.DB.QueryRow(`
+		SELECT
+			(SELECT a.cpf = b.cpf FROM users a, users b WHERE a.id = ? AND b.id = ?),
+			EXISTS (SELECT 1 FROM sessions s WHERE s.user_id = ? AND s.ip_address IS NOT NULL AND (
+				s.ip_address = ? OR s.ip_address IN (SELECT ip_address FROM sessions WHERE user_id = ?))),
+			EXISTS (SELECT 1 FROM sessions s WHERE s.user_id = ? AND s.device_id IS NOT NULL AND (
+				s.device_id = ? OR s.device_id IN (SELECT device_id FROM sessions WHERE user_id = ?)))`,
+		referrerID, refereeID, referrerID, ip, refereeID, referrerID, deviceID, refereeID).Scan(&sameCPF, &sameIP, &sameDevice)
+	switch {
+	case err != nil:
+		return "", err
+	case sameCPF:
+		return ReasonSameCPF, nil
+	case sameIP:
+		return ReasonSameIP, nil
+	case sameDevice:
+		return ReasonSameDevice, nil
+	}
+	return "", nil
+}
+
+func signupInfo(id int64) (string, string, error) {
+	var ip, deviceID sql.NullString
+	err := config.DB.QueryRow(`SELECT signup_ip, signup_device_id FROM referrals WHERE id = ?`, id).Scan(&ip, &deviceID)
+	return ip.String, deviceID.String, err
+}
@@ -0,0 +1,304 @@
+package referrals
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/responsible_gaming"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusQualified = "qualified"
+	StatusRejected  = "rejected"
+)
+
+// Motivos de recusa por suspeita de auto-indicação.
+const (
+	ReasonSameCPF    = "same_cpf"
+	ReasonSameIP     = "same_ip"
+	ReasonSameDevice = "same_device"
+)
+
+// codeAlphabet evita caracteres ambíguos (0/O, 1/I/L).
+const (
+	codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+)
+
+var ErrInvalidCode = errors.New("invalid referral code")
+
+// Summary é o painel de indicações do jogador.
+type Summary struct {
+	Code         string     `json:"code"`
+	Invited      int        `json:"invited"`
+	Qualified    int        `json:"qualified"`
+	TotalRewards float64    `json:"total_rewards"`
+	Rule         *Rule      `json:"rule"`
+	Referrals    []Referral `json:"referrals"`
+}
+
+// Rule é a regra da campanha de indicação em vigor.
+type Rule struct {
+	Reward     float64 `json:"reward"`
+	MinDeposit float64 `json:"min_deposit"`
+	MinBets    int     `json:"min_bets"`
+}
+
+func newCode() (string, error) {
+	b := make([]byte, codeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// NormalizeCode padroniza o código digitado pelo jogador.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// GetOrCreateCode devolve o código de convite do jogador, gerando-o no
+// primeiro acesso.
+func GetOrCreateCode(userID int64) (string, error) {
+	code, err := getCode(userID)
+	if err != nil || code != "" {
+		return code, err
+	}
+	for attempt := 0; attempt < 5; attempt++ {
+		candidate, err := newCode()
+		if err != nil {
+			return "", err
+		}
+		if _, err := setCode(userID, candidate); err != nil {
+			if strings.Contains(err.Error(), "UNIQUE") {
+				continue
+			}
+			return "", err
+		}
+		// Outra requisição pode ter gerado o código antes; vale o que foi gravado.
+		return getCode(userID)
+	}
+	return "", errors.New("could not generate a unique referral code")
+}
+
+// LookupCode devolve o dono do código de convite.
+func LookupCode(code string) (int64, error) {
+	id, err := getUserIDByCode(NormalizeCode(code))
+	if err == sql.ErrNoRows {
+		return 0, ErrInvalidCode
+	}
+	return id, err
+}
+
+// Attach registra a indicação do jogador recém-cadastrado (actor.UserID). Se
+// o convidado compartilha CPF, IP ou aparelho com o padrinho, a indicação já
+// nasce recusada.
+func Attach(actor audit.Actor, code, deviceID string) (Referral, error) {
+	code = NormalizeCode(code)
+	referrerID, err := LookupCode(code)
+	if err != nil {
+		return Referral{}, err
+	}
+	r := Referral{ReferrerID: referrerID, RefereeID: actor.UserID, Code: code, Status: StatusPending}
+	reason, err := sharesIdentity(referrerID, actor.UserID, actor.IPAddress, deviceID)
+	if err != nil {
+		return r, err
+	}
+	action := "referral.created"
+	if reason != "" {
+		r.Status = StatusRejected
+		r.RejectionReason = &reason
+		action = "referral.rejected"
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return r, err
+	}
+	defer tx.Rollback()
+	if r.ID, err = insertReferral(tx, r, actor.IPAddress, deviceID); err != nil {
+		return r, err
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   referrerID,
+		Details:    map[string]any{"referral_id": r.ID, "referee_id": actor.UserID, "reason": reason},
+	})
+	if err != nil {
+		return r, err
+	}
+	return r, tx.Commit()
+}
+
+// GetSummary monta o painel de indicações do jogador.
+func GetSummary(userID int64) (Summary, error) {
+	code, err := GetOrCreateCode(userID)
+	if err != nil {
+		return Summary{}, err
+	}
+	list, err := GetReferralsByReferrer(userID)
+	if err != nil {
+		return Summary{}, err
+	}
+	s := Summary{Code: code, Invited: len(list), Referrals: list}
+	for _, r := range list {
+		if r.Status == StatusQualified {
+			s.Qualified++
+			s.TotalRewards += r.RewardAmount
+		}
+	}
+	c, err := bonus.GetReferralCampaign()
+	if err != nil {
+		return s, err
+	}
+	if c != nil {
+		s.Rule = &Rule{Reward: c.MaxBonus, MinDeposit: c.MinDeposit, MinBets: c.MinBets}
+	}
+	return s, nil
+}
+
+// QualifyPending confere as indicações pendentes e concede o bônus ao padrinho
+// quando o convidado cumpre a regra da campanha. Antes de pagar, a checagem
+// de auto-indicação é refeita com as sessões abertas desde o cadastro.
+// Falhas em uma indicação são registradas no log e ela fica para a próxima
+// rodada. Devolve quantas indicações foram recompensadas.
+func QualifyPending() (int, error) {
+	c, err := bonus.GetReferralCampaign()
+	if err != nil || c == nil {
+		return 0, err
+	}
+	pending, err := pendingReferrals()
+	if err != nil {
+		return 0, err
+	}
+	rewarded := 0
+	for _, r := range pending {
+		// Um erro em uma indicação não impede a conferência das outras.
+		deposit, bets, err := progress(r.RefereeID)
+		if err != nil {
+			log.Printf("Erro ao conferir o progresso da indicação %d: %v", r.ID, err)
+			continue
+		}
+		if deposit <= 0 || deposit < c.MinDeposit || bets < c.MinBets {
+			continue
+		}
+		ip, deviceID, err := signupInfo(r.ID)
+		if err != nil {
+			log.Printf("Erro ao buscar o cadastro da indicação %d: %v", r.ID, err)
+			continue
+		}
+		reason, err := sharesIdentity(r.ReferrerID, r.RefereeID, ip, deviceID)
+		if err != nil {
+			log.Printf("Erro ao checar auto-indicação na indicação %d: %v", r.ID, err)
+			continue
+		}
+		if reason != "" {
+			if err := rejectReferral(r, reason); err != nil {
+				log.Printf("Erro ao recusar a indicação %d: %v", r.ID, err)
+			}
+			continue
+		}
+		// Padrinho autoexcluído fica pendente até a exclusão acabar.
+		if ex, err := responsible_gaming.GetActiveExclusion(r.ReferrerID); err != nil {
+			log.Printf("Erro ao checar autoexclusão do padrinho da indicação %d: %v", r.ID, err)
+			continue
+		} else if ex != nil {
+			continue
+		}
+		b, err := reward(*c, r)
+		if err != nil {
+			log.Printf("Erro ao premiar a indicação %d: %v", r.ID, err)
+			continue
+		}
+		if b != nil {
+			rewarded++
+			notifications.Notify(r.ReferrerID, "bonus", "Indicação premiada",
+				fmt.Sprintf("%s cumpriu a regra da indicação e você ganhou R$ %.2f de bônus.", r.RefereeUsername, b.GrantedAmount))
+		}
+	}
+	return rewarded, nil
+}
+
+func rejectReferral(r Referral, reason string) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	ok, err := reject(tx, r.ID, reason)
+	if err != nil || !ok {
+		return err
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      audit.System,
+		Action:     "referral.rejected",
+		TargetType: "user",
+		TargetID:   r.ReferrerID,
+		Details:    map[string]any{"referral_id": r.ID, "referee_id": r.RefereeID, "reason": reason},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func reward(c bonus.Campaign, r Referral) (*bonus.UserBonus, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	b, err := bonus.GrantReferralTx(tx, c, r.ReferrerID, r.RefereeID)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	res, err := tx.Exec(`
+		UPDATE referrals SET status = 'qualified', user_bonus_id = ?, reward_amount = ?, qualified_at = datetime('now')
+		WHERE id = ? AND status = 'pending'`, b.ID, b.GrantedAmount, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      audit.System,
+		Action:     "referral.qualified",
+		TargetType: "user",
+		TargetID:   r.ReferrerID,
+		Details:    map[string]any{"referral_id": r.ID, "referee_id": r.RefereeID, "bonus_id": b.ID, "amount": b.GrantedAmount},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, tx.Commit()
+}
+
+// StartWorker confere periodicamente as indicações pendentes.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := QualifyPending(); err != nil {
+				log.Printf("Erro ao conferir indicações: %v", err)
+			} else if n > 0 {
+				log.Printf("%d indicação(ões) recompensada(s)", n)
+			}
+		}
+	}()
+}
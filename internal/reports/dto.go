@@ -0,0 +1,84 @@
+package reports
+
+// ReportQuery são os filtros do relatório da casa. from/to são datas
+// (AAAA-MM-DD) no fuso dos relatórios; game é opcional.
+type ReportQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+	Game string `form:"game"`
+}
+
+type RebuildRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+type TheoreticalRTPRequest struct {
+	RTP *float64 `json:"rtp" binding:"required"`
+}
+
+// TheoreticalRTP é o RTP teórico (em %) configurado para um jogo
+type TheoreticalRTP struct {
+	Game      string  `json:"game"`
+	RTP       float64 `json:"rtp"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// GamingFigures são os números de jogo comuns aos totais, jogos e dias.
+// GGR = apostado - pago; RTP em % (nulo sem apostas).
+type GamingFigures struct {
+	Bets          int      `json:"bets"`
+	ActivePlayers int      `json:"active_players"`
+	Stakes        float64  `json:"stakes"`
+	Payouts       float64  `json:"payouts"`
+	GGR           float64  `json:"ggr"`
+	RTPActual     *float64 `json:"rtp_actual"`
+	AverageStake  float64  `json:"average_stake"`
+}
+
+// ReportTotals resume o intervalo. Bônus e NGR (GGR - bônus convertidos em
+// saldo real) só aparecem sem filtro de jogo, pois o custo do bônus não é
+// atribuível a um jogo.
+type ReportTotals struct {
+	GamingFigures
+	RTPTheoretical *float64 `json:"rtp_theoretical"`
+	BonusCost      *float64 `json:"bonus_cost,omitempty"`
+	NGR            *float64 `json:"ngr,omitempty"`
+}
+
+// CashflowTotals é a movimentação de caixa da casa toda no intervalo.
+// Withdrawals já desconta os saques estornados.
+type CashflowTotals struct {
+	Deposits         float64 `json:"deposits"`
+	DepositsCount    int     `json:"deposits_count"`
+	Withdrawals      float64 `json:"withdrawals"`
+	WithdrawalsCount int     `json:"withdrawals_count"`
+	NetDeposits      float64 `json:"net_deposits"`
+}
+
+type GameReport struct {
+	Game string `json:"game"`
+	GamingFigures
+	RTPTheoretical *float64 `json:"rtp_theoretical"`
+}
+
+type DayReport struct {
+	Date string `json:"date"`
+	GamingFigures
+	BonusCost   *float64 `json:"bonus_cost,omitempty"`
+	NGR         *float64 `json:"ngr,omitempty"`
+	Deposits    float64  `json:"deposits"`
+	Withdrawals float64  `json:"withdrawals"`
+}
+
+// HouseReport é a resposta de GET /api/admin/reports/house
+type HouseReport struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Timezone string         `json:"timezone"`
+	Game     string         `json:"game,omitempty"`
+	Totals   ReportTotals   `json:"totals"`
+	Cashflow CashflowTotals `json:"cashflow"`
+	Games    []GameReport   `json:"games"`
+	Days     []DayReport    `json:"days"`
+}
@@ -0,0 +1,147 @@
+package reports
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/utils"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRange):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_RANGE", "Use from/to as YYYY-MM-DD with from <= to.", nil)
+	case errors.Is(err, ErrRangeTooLong):
+		utils.RespondError(c, http.StatusBadRequest, "RANGE_TOO_LONG", "Date range is too long.", nil)
+	case errors.Is(err, ErrUnknownGame):
+		utils.RespondError(c, http.StatusBadRequest, "UNKNOWN_GAME", "Unknown game.", Games)
+	case errors.Is(err, ErrInvalidRTP):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_RTP", "Theoretical RTP must be a percentage between 0 and 200.", nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to build report.", err.Error())
+	}
+}
+
+// GetHouseReportHandler returns GGR, NGR, RTP, active players, cashflow and
+// average stake for a date range (?from=&to=&game=). ?format=csv exports the
+// daily rows, or the per-game rows with &view=games.
+func GetHouseReportHandler(c *gin.Context) {
+	var q ReportQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid query.", err.Error())
+		return
+	}
+	report, err := GetHouseReport(q)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		utils.RespondSuccess(c, report, "House report built")
+	case "csv":
+		view := c.DefaultQuery("view", "days")
+		if view != "days" && view != "games" {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_VIEW", "View must be days or games.", nil)
+			return
+		}
+		writeCSV(c, report, view)
+	default:
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_FORMAT", "Format must be json or csv.", nil)
+	}
+}
+
+func writeCSV(c *gin.Context, r *HouseReport, view string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="house_report_%s_%s_%s.csv"`, r.From, r.To, view))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	figures := func(f GamingFigures) []string {
+		return []string{strconv.Itoa(f.Bets), strconv.Itoa(f.ActivePlayers), money(f.Stakes), money(f.Payouts),
+			money(f.GGR), optional(f.RTPActual), money(f.AverageStake)}
+	}
+	header := []string{"bets", "active_players", "stakes", "payouts", "ggr", "rtp_actual", "average_stake"}
+	if view == "games" {
+		w.Write(append(append([]string{"game"}, header...), "rtp_theoretical"))
+		for _, g := range r.Games {
+			w.Write(append(append([]string{g.Game}, figures(g.GamingFigures)...), optional(g.RTPTheoretical)))
+		}
+	} else {
+		w.Write(append(append([]string{"date"}, header...), "bonus_cost", "ngr", "deposits", "withdrawals"))
+		for _, d := range r.Days {
+			w.Write(append(append([]string{d.Date}, figures(d.GamingFigures)...),
+				optional(d.BonusCost), optional(d.NGR), money(d.Deposits), money(d.Withdrawals)))
+		}
+	}
+	w.Flush()
+}
+
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// optional deixa a célula vazia quando o valor não se aplica
+func optional(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return money(*v)
+}
+
+// RebuildReportsHandler recomputes the daily aggregates for a date range, e.g.
+// after correcting ledger entries.
+func RebuildReportsHandler(c *gin.Context) {
+	var req RebuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "from and to are required.", err.Error())
+		return
+	}
+	days, err := RebuildRange(req.From, req.To)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "reports.rebuild",
+		TargetType: "house_report",
+		Details:    map[string]any{"from": req.From, "to": req.To, "days": days},
+	})
+	utils.RespondSuccess(c, gin.H{"days": days}, "Reports rebuilt")
+}
+
+// GetTheoreticalRTPsHandler lists the configured theoretical RTP per game.
+func GetTheoreticalRTPsHandler(c *gin.Context) {
+	list, err := GetTheoreticalRTPs()
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, list, "Theoretical RTPs found")
+}
+
+// SetTheoreticalRTPHandler sets a game's theoretical RTP, as a percentage.
+func SetTheoreticalRTPHandler(c *gin.Context) {
+	var req TheoreticalRTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_RTP", "Theoretical RTP must be a percentage between 0 and 200.", nil)
+		return
+	}
+	game := c.Param("game")
+	if err := SaveTheoreticalRTP(game, *req.RTP); err != nil {
+		respondReportError(c, err)
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "reports.theoretical_rtp_change",
+		TargetType: "game",
+		Details:    map[string]any{"game": game, "rtp": *req.RTP},
+	})
+	utils.RespondSuccess(c, gin.H{"game": game, "rtp": *req.RTP}, "Theoretical RTP saved")
+}
@@ -0,0 +1,226 @@
+package reports
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/dashboard"
+	"database/sql"
+)
+
+// gameDay são os números consolidados de um jogo em um dia
+type gameDay struct {
+	Date    string
+	Game    string
+	Bets    int
+	Stakes  float64
+	Payouts float64
+}
+
+// cashflowDay é a movimentação de caixa consolidada de um dia
+type cashflowDay struct {
+	Date              string
+	Deposits          float64
+	DepositsCount     int
+	Withdrawals       float64
+	WithdrawalsCount  int
+	WithdrawalRefunds float64
+	BonusCost         float64
+}
+
+// Rodadas da roleta: a aposta e o ganho ficam no ledger com descrições fixas
+const roletaStakeFilter = `type = 'bet' AND description LIKE 'Aposta na roleta%'`
+const roletaPayoutFilter = `type = 'win' AND description LIKE 'Ganho na roleta%'`
+
+// aggregateDayTx recalcula as tabelas diárias de uma data. start/end são os
+// limites do dia (UTC, formato do banco) no fuso dos relatórios.
+func aggregateDayTx(tx *sql.Tx, date, start, end string) error {
+	for _, table := range []string{"house_daily_games", "house_daily_players", "house_daily_cashflow"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE date = ?", date); err != nil {
+			return err
+		}
+	}
+
+	// Roleta, a partir do ledger (o valor da aposta pode estar com sinal negativo)
+	roleta := gameDay{Date: date, Game: dashboard.GameRoleta}
+	err := tx.QueryRow(`
+		SELECT COUNT(CASE WHEN `+roletaStakeFilter+` THEN 1 END),
+			COALESCE(SUM(CASE WHEN `+roletaStakeFilter+` THEN ABS(amount) END), 0),
+			COALESCE(SUM(CASE WHEN `+roletaPayoutFilter+` THEN ABS(amount) END), 0)
+		FROM transactions
+		WHERE created_at >= ? AND created_at < ?`, start, end).
+		Scan(&roleta.Bets, &roleta.Stakes, &roleta.Payouts)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO house_daily_players (date, game, user_id)
+		SELECT DISTINCT ?, ?, user_id FROM transactions
+		WHERE `+roletaStakeFilter+` AND created_at >= ? AND created_at < ?`,
+		date, dashboard.GameRoleta, start, end)
+	if err != nil {
+		return err
+	}
+
+	// Esportes, a partir das apostas liquidadas
+	sports := gameDay{Date: date, Game: dashboard.GameSports}
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(amount), 0),
+			COALESCE(SUM(CASE WHEN bet_status = 'won' THEN amount + COALESCE(profit_loss, 0) END), 0)
+		FROM bets
+		WHERE bet_status IN ('won', 'lost') AND created_at >= ? AND created_at < ?`, start, end).
+		Scan(&sports.Bets, &sports.Stakes, &sports.Payouts)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO house_daily_players (date, game, user_id)
+		SELECT DISTINCT ?, ?, user_id FROM bets
+		WHERE bet_status IN ('won', 'lost') AND created_at >= ? AND created_at < ?`,
+		date, dashboard.GameSports, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range []gameDay{roleta, sports} {
+		if g.Bets == 0 {
+			continue
+		}
+		_, err = tx.Exec(`
+			INSERT INTO house_daily_games (date, game, bets_count, stakes, payouts, players, computed_at)
+			VALUES (?, ?, ?, ?, ?, (SELECT COUNT(*) FROM house_daily_players WHERE date = ? AND game = ?), datetime('now'))`,
+			g.Date, g.Game, g.Bets, g.Stakes, g.Payouts, g.Date, g.Game)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Caixa e bônus convertidos em saldo real valem para a casa toda
+	_, err = tx.Exec(`
+		INSERT INTO house_daily_cashflow (date, deposits, deposits_count, withdrawals, withdrawals_count,
+			withdrawal_refunds, bonus_cost, computed_at)
+		SELECT ?,
+			COALESCE(SUM(CASE WHEN type = 'deposit' THEN ABS(amount) END), 0),
+			COUNT(CASE WHEN type = 'deposit' THEN 1 END),
+			COALESCE(SUM(CASE WHEN type = 'withdraw' THEN ABS(amount) END), 0),
+			COUNT(CASE WHEN type = 'withdraw' THEN 1 END),
+			COALESCE(SUM(CASE WHEN type = 'withdraw_refund' THEN ABS(amount) END), 0),
+			COALESCE(SUM(CASE WHEN type = 'bonus' THEN ABS(amount) END), 0),
+			datetime('now')
+		FROM transactions
+		WHERE created_at >= ? AND created_at < ?`, date, start, end)
+	return err
+}
+
+// lastAggregatedDate retorna o último dia consolidado ("" se nenhum)
+func lastAggregatedDate() (string, error) {
+	var date sql.NullString
+	err := config.DB.QueryRow(`SELECT MAX(date) FROM house_daily_cashflow`).Scan(&date)
+	return date.String, err
+}
+
+// firstActivity retorna o timestamp (UTC) do primeiro lançamento do ledger ou
+// aposta esportiva, "" se o banco estiver vazio
+func firstActivity() (string, error) {
+	var first sql.NullString
+	err := config.DB.QueryRow(`
+		SELECT MIN(ts) FROM (
+			SELECT MIN(created_at) AS ts FROM transactions
+			UNION ALL
+			SELECT MIN(created_at) FROM bets
+		)`).Scan(&first)
+	return first.String, err
+}
+
+func listGameDays(from, to, game string) ([]gameDay, error) {
+	query := `SELECT date, game, bets_count, stakes, payouts FROM house_daily_games WHERE date >= ? AND date <= ?`
+	args := []any{from, to}
+	if game != "" {
+		query += ` AND game = ?`
+		args = append(args, game)
+	}
+	rows, err := config.DB.Query(query+` ORDER BY date, game`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []gameDay
+	for rows.Next() {
+		var g gameDay
+		if err := rows.Scan(&g.Date, &g.Game, &g.Bets, &g.Stakes, &g.Payouts); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+func listCashflowDays(from, to string) ([]cashflowDay, error) {
+	rows, err := config.DB.Query(`
+		SELECT date, deposits, deposits_count, withdrawals, withdrawals_count, withdrawal_refunds, bonus_cost
+		FROM house_daily_cashflow WHERE date >= ? AND date <= ? ORDER BY date`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []cashflowDay
+	for rows.Next() {
+		var d cashflowDay
+		if err := rows.Scan(&d.Date, &d.Deposits, &d.DepositsCount, &d.Withdrawals, &d.WithdrawalsCount,
+			&d.WithdrawalRefunds, &d.BonusCost); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// countPlayers conta jogadores distintos no intervalo, agrupados pela
+// expressão informada ("date", "game" ou "'all'" para o total)
+func countPlayers(groupBy, from, to, game string) (map[string]int, error) {
+	query := `SELECT ` + groupBy + `, COUNT(DISTINCT user_id) FROM house_daily_players WHERE date >= ? AND date <= ?`
+	args := []any{from, to}
+	if game != "" {
+		query += ` AND game = ?`
+		args = append(args, game)
+	}
+	rows, err := config.DB.Query(query+` GROUP BY `+groupBy, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var key string
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
+
+// GetTheoreticalRTPs retorna o RTP teórico configurado de cada jogo
+func GetTheoreticalRTPs() ([]TheoreticalRTP, error) {
+	rows, err := config.DB.Query(`SELECT game, rtp, CAST(updated_at AS TEXT) FROM game_theoretical_rtp ORDER BY game`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []TheoreticalRTP{}
+	for rows.Next() {
+		var r TheoreticalRTP
+		if err := rows.Scan(&r.Game, &r.RTP, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// SetTheoreticalRTP grava o RTP teórico (em %) de um jogo
+func SetTheoreticalRTP(game string, rtp float64) error {
+	_, err := config.DB.Exec(`
+		INSERT INTO game_theoretical_rtp (game, rtp, updated_at) VALUES (?, ?, datetime('now'))
+		ON CONFLICT(game) DO UPDATE SET rtp = excluded.rtp, updated_at = excluded.updated_at`, game, rtp)
+	return err
+}
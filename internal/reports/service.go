@@ -0,0 +1,342 @@
+package reports
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/dashboard"
+	"berry_bet/internal/users"
+	"errors"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	dbTimeLayout = "2006-01-02 15:04:05"
+	dateLayout   = "2006-01-02"
+
+	// Período padrão do relatório e limites de consulta e de reprocessamento
+	defaultReportDays = 30
+	maxReportDays     = 2 * 366
+	maxRebuildDays    = 400
+)
+
+var (
+	ErrInvalidRange = errors.New("invalid date range")
+	ErrRangeTooLong = errors.New("date range too long")
+	ErrUnknownGame  = errors.New("unknown game")
+	ErrInvalidRTP   = errors.New("invalid theoretical rtp")
+)
+
+// Games são os jogos cobertos pelos relatórios
+var Games = []string{dashboard.GameRoleta, dashboard.GameSports}
+
+// Location é o fuso em que os dias dos relatórios são fechados
+// (REPORTS_TIMEZONE, padrão America/Sao_Paulo)
+func Location() *time.Location {
+	name := os.Getenv("REPORTS_TIMEZONE")
+	if name == "" || !users.ValidTimezone(name) {
+		name = users.DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func knownGame(game string) bool {
+	for _, g := range Games {
+		if g == game {
+			return true
+		}
+	}
+	return false
+}
+
+// AggregateDay consolida um dia (no fuso dos relatórios) nas tabelas diárias.
+// Pode ser chamado de novo para o mesmo dia: os números são recalculados.
+func AggregateDay(day time.Time) error {
+	loc := Location()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 1)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = aggregateDayTx(tx, start.Format(dateLayout),
+		start.UTC().Format(dbTimeLayout), end.UTC().Format(dbTimeLayout))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Rebuild reprocessa todos os dias entre from e to (inclusive)
+func Rebuild(from, to time.Time) (int, error) {
+	days := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if err := AggregateDay(d); err != nil {
+			return days, err
+		}
+		days++
+	}
+	return days, nil
+}
+
+// CatchUp consolida do último dia já processado (refeito, pois pode ter sido
+// fechado pela metade) até hoje. Na primeira execução começa no primeiro
+// lançamento do ledger.
+func CatchUp() (int, error) {
+	loc := Location()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	last, err := lastAggregatedDate()
+	if err != nil {
+		return 0, err
+	}
+	from := today
+	if last != "" {
+		if from, err = time.ParseInLocation(dateLayout, last, loc); err != nil {
+			return 0, err
+		}
+	} else {
+		first, err := firstActivity()
+		if err != nil {
+			return 0, err
+		}
+		if t, err := time.ParseInLocation(dbTimeLayout, first, time.UTC); err == nil {
+			t = t.In(loc)
+			from = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+	}
+	return Rebuild(from, today)
+}
+
+// StartWorker mantém as tabelas diárias em dia: consolida ao subir e depois
+// a cada intervalo (o dia corrente é refeito até virar)
+func StartWorker(interval time.Duration) {
+	go func() {
+		run := func() {
+			if _, err := CatchUp(); err != nil {
+				log.Printf("Erro ao consolidar relatórios da casa: %v", err)
+			}
+		}
+		run()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+}
+
+// parseRange interpreta from/to no fuso dos relatórios; sem from usa os
+// últimos defaultReportDays dias até to (ou hoje)
+func parseRange(fromParam, toParam string, loc *time.Location, maxDays int) (time.Time, time.Time, error) {
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if toParam != "" {
+		t, err := time.ParseInLocation(dateLayout, toParam, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -(defaultReportDays - 1))
+	if fromParam != "" {
+		f, err := time.ParseInLocation(dateLayout, fromParam, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		from = f
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	if int(math.Round(to.Sub(from).Hours()/24))+1 > maxDays {
+		return time.Time{}, time.Time{}, ErrRangeTooLong
+	}
+	return from, to, nil
+}
+
+// RebuildRange valida e reprocessa um intervalo pedido por um administrador
+func RebuildRange(fromParam, toParam string) (int, error) {
+	from, to, err := parseRange(fromParam, toParam, Location(), maxRebuildDays)
+	if err != nil {
+		return 0, err
+	}
+	return Rebuild(from, to)
+}
+
+// GetHouseReport monta o relatório da casa a partir das tabelas diárias
+func GetHouseReport(q ReportQuery) (*HouseReport, error) {
+	if q.Game != "" && !knownGame(q.Game) {
+		return nil, ErrUnknownGame
+	}
+	loc := Location()
+	fromDate, toDate, err := parseRange(q.From, q.To, loc, maxReportDays)
+	if err != nil {
+		return nil, err
+	}
+	from, to := fromDate.Format(dateLayout), toDate.Format(dateLayout)
+
+	gameDays, err := listGameDays(from, to, q.Game)
+	if err != nil {
+		return nil, err
+	}
+	cashflow, err := listCashflowDays(from, to)
+	if err != nil {
+		return nil, err
+	}
+	playersByGame, err := countPlayers("game", from, to, q.Game)
+	if err != nil {
+		return nil, err
+	}
+	playersByDate, err := countPlayers("date", from, to, q.Game)
+	if err != nil {
+		return nil, err
+	}
+	playersTotal, err := countPlayers("'all'", from, to, q.Game)
+	if err != nil {
+		return nil, err
+	}
+	theoretical := map[string]float64{}
+	rtps, err := GetTheoreticalRTPs()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rtps {
+		theoretical[r.Game] = r.RTP
+	}
+
+	report := &HouseReport{From: from, To: to, Timezone: loc.String(), Game: q.Game}
+	withBonus := q.Game == ""
+
+	// Um dia por data do intervalo, mesmo sem movimento
+	days := map[string]*DayReport{}
+	for d := fromDate; !d.After(toDate); d = d.AddDate(0, 0, 1) {
+		date := d.Format(dateLayout)
+		report.Days = append(report.Days, DayReport{Date: date})
+	}
+	for i := range report.Days {
+		days[report.Days[i].Date] = &report.Days[i]
+	}
+
+	games := map[string]*GameReport{}
+	for _, g := range gameDays {
+		gr, ok := games[g.Game]
+		if !ok {
+			gr = &GameReport{Game: g.Game}
+			games[g.Game] = gr
+		}
+		gr.add(g)
+		report.Totals.add(g)
+		if d, ok := days[g.Date]; ok {
+			d.add(g)
+		}
+	}
+
+	var bonusTotal float64
+	for _, c := range cashflow {
+		withdrawn := c.Withdrawals - c.WithdrawalRefunds
+		report.Cashflow.Deposits += c.Deposits
+		report.Cashflow.DepositsCount += c.DepositsCount
+		report.Cashflow.Withdrawals += withdrawn
+		report.Cashflow.WithdrawalsCount += c.WithdrawalsCount
+		bonusTotal += c.BonusCost
+		if d, ok := days[c.Date]; ok {
+			d.Deposits = round2(c.Deposits)
+			d.Withdrawals = round2(withdrawn)
+			if withBonus {
+				d.BonusCost = floatPtr(round2(c.BonusCost))
+			}
+		}
+	}
+	report.Cashflow.Deposits = round2(report.Cashflow.Deposits)
+	report.Cashflow.Withdrawals = round2(report.Cashflow.Withdrawals)
+	report.Cashflow.NetDeposits = round2(report.Cashflow.Deposits - report.Cashflow.Withdrawals)
+
+	for i := range report.Days {
+		d := &report.Days[i]
+		d.ActivePlayers = playersByDate[d.Date]
+		d.finish()
+		if withBonus {
+			if d.BonusCost == nil {
+				d.BonusCost = floatPtr(0)
+			}
+			d.NGR = floatPtr(round2(d.GGR - *d.BonusCost))
+		}
+	}
+
+	// RTP teórico total: média ponderada pelo volume, se todos os jogos
+	// com apostas tiverem RTP configurado
+	var weighted, weightedStakes float64
+	complete := true
+	report.Games = []GameReport{}
+	for _, gr := range games {
+		gr.ActivePlayers = playersByGame[gr.Game]
+		gr.finish()
+		if rtp, ok := theoretical[gr.Game]; ok {
+			gr.RTPTheoretical = floatPtr(rtp)
+			weighted += rtp * gr.Stakes
+			weightedStakes += gr.Stakes
+		} else if gr.Stakes > 0 {
+			complete = false
+		}
+		report.Games = append(report.Games, *gr)
+	}
+	sort.Slice(report.Games, func(i, j int) bool { return report.Games[i].Game < report.Games[j].Game })
+
+	report.Totals.ActivePlayers = playersTotal["all"]
+	report.Totals.finish()
+	if complete && weightedStakes > 0 {
+		report.Totals.RTPTheoretical = floatPtr(round2(weighted / weightedStakes))
+	}
+	if withBonus {
+		report.Totals.BonusCost = floatPtr(round2(bonusTotal))
+		report.Totals.NGR = floatPtr(round2(report.Totals.GGR - bonusTotal))
+	}
+	return report, nil
+}
+
+// SaveTheoreticalRTP valida e grava o RTP teórico (em %) de um jogo
+func SaveTheoreticalRTP(game string, rtp float64) error {
+	if !knownGame(game) {
+		return ErrUnknownGame
+	}
+	if rtp <= 0 || rtp > 200 {
+		return ErrInvalidRTP
+	}
+	return SetTheoreticalRTP(game, rtp)
+}
+
+func (f *GamingFigures) add(g gameDay) {
+	f.Bets += g.Bets
+	f.Stakes += g.Stakes
+	f.Payouts += g.Payouts
+}
+
+func (f *GamingFigures) finish() {
+	f.Stakes = round2(f.Stakes)
+	f.Payouts = round2(f.Payouts)
+	f.GGR = round2(f.Stakes - f.Payouts)
+	if f.Stakes > 0 {
+		f.RTPActual = floatPtr(round2(f.Payouts / f.Stakes * 100))
+	}
+	if f.Bets > 0 {
+		f.AverageStake = round2(f.Stakes / float64(f.Bets))
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,61 @@
+package responsible_gaming
+
+type SetLimitRequest struct {
+	Kind   string   `json:"kind"`
+	Period string   `json:"period"`
+	Amount *float64 `json:"amount"` // null remove o limite (após o período de espera)
+}
+
+type ExcludeRequest struct {
+	Duration string `json:"duration"` // 24h, 7d, 30d, 6m, 1y, 5y ou permanent
+}
+
+type ExclusionStatusResponse struct {
+	Excluded  bool       `json:"excluded"`
+	Exclusion *Exclusion `json:"exclusion"`
+}
+
+type RealityCheckSettingsRequest struct {
+	IntervalMinutes int `json:"interval_minutes"`
+}
+
+type RealityCheckSettingsResponse struct {
+	IntervalMinutes int           `json:"interval_minutes"`
+	Pending         *RealityCheck `json:"pending"`
+}
+
+type AllowanceResponse struct {
+	Kind               string   `json:"kind"`
+	Period             string   `json:"period"`
+	Limit              *float64 `json:"limit"`
+	Used               float64  `json:"used"`
+	Remaining          *float64 `json:"remaining"`
+	PendingLimit       *float64 `json:"pending_limit,omitempty"`
+	PendingRemoval     bool     `json:"pending_removal,omitempty"`
+	PendingEffectiveAt *string  `json:"pending_effective_at,omitempty"`
+}
+
+func ToAllowanceResponse(a Allowance) AllowanceResponse {
+	resp := AllowanceResponse{
+		Kind:               a.Kind,
+		Period:             a.Period,
+		Limit:              a.Amount,
+		Used:               a.Used,
+		PendingLimit:       a.PendingAmount,
+		PendingRemoval:     a.HasPending && a.PendingAmount == nil,
+		PendingEffectiveAt: a.PendingEffectiveAt,
+	}
+	if a.Amount != nil {
+		remaining := a.Remaining
+		resp.Remaining = &remaining
+	}
+	return resp
+}
+
+func ToAllowanceResponses(list []Allowance) []AllowanceResponse {
+	resp := make([]AllowanceResponse, 0, len(list))
+	for _, a := range list {
+		resp = append(resp, ToAllowanceResponse(a))
+	}
+	return resp
+}
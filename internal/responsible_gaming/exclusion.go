@@ -0,0 +1,184 @@
+package responsible_gaming
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ExclusionTimeout   = "timeout"
+	ExclusionLongTerm  = "exclusion"
+	DurationPermanent  = "permanent"
+	exclusionTimestamp = "2006-01-02 15:04:05"
+)
+
+// ExclusionOption é uma das durações oferecidas ao jogador.
+type ExclusionOption struct {
+	Kind     string
+	Duration time.Duration // zero para permanente
+}
+
+// ExclusionOptions são as pausas curtas e as autoexclusões de longo prazo.
+var ExclusionOptions = map[string]ExclusionOption{
+	"24h":             {ExclusionTimeout, 24 * time.Hour},
+	"7d":              {ExclusionTimeout, 7 * 24 * time.Hour},
+	"30d":             {ExclusionTimeout, 30 * 24 * time.Hour},
+	"6m":              {ExclusionLongTerm, 182 * 24 * time.Hour},
+	"1y":              {ExclusionLongTerm, 365 * 24 * time.Hour},
+	"5y":              {ExclusionLongTerm, 5 * 365 * 24 * time.Hour},
+	DurationPermanent: {ExclusionLongTerm, 0},
+}
+
+var (
+	ErrInvalidDuration  = errors.New("duration must be one of 24h, 7d, 30d, 6m, 1y, 5y or permanent")
+	ErrExclusionShorter = errors.New("an active exclusion already lasts longer; exclusions can only be extended")
+)
+
+// Exclusion é uma pausa ou autoexclusão registrada.
+type Exclusion struct {
+	ID       int64   `json:"id"`
+	UserID   int64   `json:"user_id"`
+	Kind     string  `json:"kind"`
+	Duration string  `json:"duration"`
+	StartsAt string  `json:"starts_at"`
+	EndsAt   *string `json:"ends_at"`
+}
+
+// ExclusionError indica que a conta está em pausa ou autoexcluída.
+type ExclusionError struct {
+	Kind   string  `json:"kind"`
+	EndsAt *string `json:"ends_at"`
+}
+
+func (e *ExclusionError) Error() string {
+	if e.EndsAt == nil {
+		return "this account is permanently self-excluded from betting and deposits"
+	}
+	return fmt.Sprintf("this account is self-excluded from betting and deposits until %s UTC", *e.EndsAt)
+}
+
+func scanExclusion(row interface{ Scan(dest ...any) error }) (Exclusion, error) {
+	var e Exclusion
+	var endsAt sql.NullString
+	err := row.Scan(&e.ID, &e.UserID, &e.Kind, &e.Duration, &e.StartsAt, &endsAt)
+	if endsAt.Valid {
+		e.EndsAt = &endsAt.String
+	}
+	return e, err
+}
+
+const exclusionColumns = `id, user_id, kind, duration, CAST(starts_at AS TEXT), CAST(ends_at AS TEXT)`
+
+// activeExclusion devolve a exclusão vigente que termina por último
+// (permanente primeiro), ou nil.
+func activeExclusion(db dbtx, userID int64) (*Exclusion, error) {
+	e, err := scanExclusion(db.QueryRow(`
+		SELECT `+exclusionColumns+` FROM self_exclusions
+		WHERE user_id = ? AND starts_at <= datetime('now') AND (ends_at IS NULL OR ends_at > datetime('now'))
+		ORDER BY ends_at IS NULL DESC, ends_at DESC LIMIT 1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// GetActiveExclusion devolve a exclusão vigente do jogador, ou nil.
+func GetActiveExclusion(userID int64) (*Exclusion, error) {
+	return activeExclusion(config.DB, userID)
+}
+
+// Exclude registra uma pausa ou autoexclusão a partir de agora. Uma exclusão
+// vigente nunca é encurtada: a nova precisa terminar depois dela.
+func Exclude(userID int64, duration string) (Exclusion, error) {
+	opt, ok := ExclusionOptions[duration]
+	if !ok {
+		return Exclusion{}, ErrInvalidDuration
+	}
+	now := time.Now().UTC()
+	var endsAt *string
+	if opt.Duration > 0 {
+		end := now.Add(opt.Duration).Format(exclusionTimestamp)
+		endsAt = &end
+	}
+
+	current, err := GetActiveExclusion(userID)
+	if err != nil {
+		return Exclusion{}, err
+	}
+	// As datas usam o mesmo formato, então a comparação de strings equivale à cronológica.
+	if current != nil && (current.EndsAt == nil || (endsAt != nil && *endsAt <= *current.EndsAt)) {
+		return Exclusion{}, ErrExclusionShorter
+	}
+
+	e := Exclusion{UserID: userID, Kind: opt.Kind, Duration: duration, StartsAt: now.Format(exclusionTimestamp), EndsAt: endsAt}
+	res, err := config.DB.Exec(`
+		INSERT INTO self_exclusions (user_id, kind, duration, starts_at, ends_at, created_at)
+		VALUES (?, ?, ?, ?, ?, datetime('now'))`, e.UserID, e.Kind, e.Duration, e.StartsAt, e.EndsAt)
+	if err != nil {
+		return Exclusion{}, err
+	}
+	e.ID, err = res.LastInsertId()
+	return e, err
+}
+
+// checkNotExcluded devolve um *ExclusionError se a conta estiver excluída.
+func checkNotExcluded(db dbtx, userID int64) error {
+	e, err := activeExclusion(db, userID)
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		return &ExclusionError{Kind: e.Kind, EndsAt: e.EndsAt}
+	}
+	return nil
+}
+
+// ExcludedAccount é uma linha do relatório administrativo.
+type ExcludedAccount struct {
+	Exclusion
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Active   bool   `json:"active"`
+}
+
+// ListExclusions lista as exclusões (apenas as vigentes, se activeOnly),
+// das mais recentes para as mais antigas.
+func ListExclusions(activeOnly bool, page, limit int) ([]ExcludedAccount, int, error) {
+	where := ""
+	if activeOnly {
+		where = `WHERE e.starts_at <= datetime('now') AND (e.ends_at IS NULL OR e.ends_at > datetime('now'))`
+	}
+	var total int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM self_exclusions e ` + where).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := config.DB.Query(`
+		SELECT e.id, e.user_id, e.kind, e.duration, CAST(e.starts_at AS TEXT), CAST(e.ends_at AS TEXT),
+			u.username, u.email, (e.ends_at IS NULL OR e.ends_at > datetime('now'))
+		FROM self_exclusions e JOIN users u ON u.id = e.user_id
+		`+where+`
+		ORDER BY e.created_at DESC, e.id DESC LIMIT ? OFFSET ?`, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	list := []ExcludedAccount{}
+	for rows.Next() {
+		var a ExcludedAccount
+		var endsAt sql.NullString
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Kind, &a.Duration, &a.StartsAt, &endsAt, &a.Username, &a.Email, &a.Active); err != nil {
+			return nil, 0, err
+		}
+		if endsAt.Valid {
+			a.EndsAt = &endsAt.String
+		}
+		list = append(list, a)
+	}
+	return list, total, rows.Err()
+}
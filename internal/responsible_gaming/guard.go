@@ -0,0 +1,72 @@
+package responsible_gaming
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/kyc"
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RespondPlayError escreve a resposta de conta autoexcluída, de limite atingido,
+// de lembrete de tempo de jogo pendente ou de verificação de identidade exigida
+// e devolve true quando err é um desses casos.
+func RespondPlayError(c *gin.Context, err error) bool {
+	var exclusionErr *ExclusionError
+	if errors.As(err, &exclusionErr) {
+		utils.RespondError(c, http.StatusForbidden, "SELF_EXCLUDED", exclusionErr.Error(), exclusionErr)
+		return true
+	}
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		utils.RespondError(c, http.StatusForbidden, "LIMIT_EXCEEDED", limitErr.Error(), limitErr)
+		return true
+	}
+	var checkErr *RealityCheckError
+	if errors.As(err, &checkErr) {
+		utils.RespondError(c, http.StatusPreconditionRequired, "REALITY_CHECK_REQUIRED", checkErr.Error(), gin.H{"reality_check": checkErr.RealityCheck})
+		return true
+	}
+	return kyc.RespondRequiredError(c, err)
+}
+
+func respondCheck(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	if !RespondPlayError(c, err) {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to check responsible-gaming rules.", err.Error())
+	}
+	return false
+}
+
+// CheckBet aplica as proteções de jogo responsável antes de uma aposta de
+// amount. Escreve a resposta de erro e devolve false quando a aposta deve ser
+// recusada. Todos os endpoints de aposta devem chamá-la e, depois de registrar
+// a aposta, chamar TrackPlay.
+func CheckBet(c *gin.Context, userID int64, amount float64) bool {
+	if err := CheckWager(userID, amount); err != nil {
+		return respondCheck(c, err)
+	}
+	if err := kyc.CheckStake(userID, amount); err != nil {
+		return respondCheck(c, err)
+	}
+	return respondCheck(c, checkRealityCheck(userID, isTruthy(c.GetHeader(RealityCheckHeader))))
+}
+
+func isTruthy(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// CheckNotExcluded recusa contas em pausa ou autoexcluídas em endpoints de
+// jogo que não são apostas (por exemplo, inscrição em torneios).
+func CheckNotExcluded(c *gin.Context, userID int64) bool {
+	return respondCheck(c, checkNotExcluded(config.DB, userID))
+}
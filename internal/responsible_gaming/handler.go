@@ -0,0 +1,236 @@
+package responsible_gaming
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyLimitsHandler lists the player's limits with the remaining allowance.
+func GetMyLimitsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	list, err := GetAllowances(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch limits.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, ToAllowanceResponses(list), "Limits fetched successfully.")
+}
+
+// SetMyLimitHandler creates, lowers, raises or removes one of the player's
+// limits. Raising or removing only takes effect after the cooling period.
+func SetMyLimitHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	var req SetLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	before, err := GetLimits(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch limits.", err.Error())
+		return
+	}
+	limit, pending, err := SetLimit(userID, req.Kind, req.Period, req.Amount)
+	if err != nil {
+		if errors.Is(err, ErrInvalidKind) || errors.Is(err, ErrInvalidPeriod) || errors.Is(err, ErrInvalidAmount) {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		} else {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to save limit.", err.Error())
+		}
+		return
+	}
+	var previous *Limit
+	for i := range before {
+		if before[i].Kind == req.Kind && before[i].Period == req.Period {
+			previous = &before[i]
+		}
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "user.limit_change",
+		TargetType: "user",
+		TargetID:   userID,
+		Before:     previous,
+		After:      limit,
+		Details:    gin.H{"requested_amount": req.Amount, "pending": pending},
+	})
+
+	list, err := GetAllowances(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch limits.", err.Error())
+		return
+	}
+	msg := "Limit updated."
+	if pending {
+		msg = "Limit change scheduled; increases and removals take effect after the cooling period."
+	}
+	utils.RespondSuccess(c, ToAllowanceResponses(list), msg)
+}
+
+// GetMyExclusionHandler returns the player's active time-out or self-exclusion, if any.
+func GetMyExclusionHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	e, err := GetActiveExclusion(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch exclusion.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, ExclusionStatusResponse{Excluded: e != nil, Exclusion: e}, "Exclusion status fetched successfully.")
+}
+
+// ExcludeMeHandler starts a time-out or self-exclusion. It cannot be undone
+// before it ends; a new request may only extend it.
+func ExcludeMeHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	var req ExcludeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	e, err := Exclude(userID, req.Duration)
+	switch {
+	case errors.Is(err, ErrInvalidDuration):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		return
+	case errors.Is(err, ErrExclusionShorter):
+		utils.RespondError(c, http.StatusConflict, "ALREADY_EXCLUDED", err.Error(), nil)
+		return
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to save exclusion.", err.Error())
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{Action: "user.self_exclusion", TargetType: "user", TargetID: userID, After: e})
+
+	until := "permanentemente"
+	if e.EndsAt != nil {
+		until = "até " + *e.EndsAt + " (UTC)"
+	}
+	notifications.Notify(userID, "self_exclusion", "Autoexclusão ativada",
+		"Sua conta está bloqueada para apostas e depósitos "+until+". Você ainda pode consultar seu histórico e sacar seu saldo.")
+	utils.RespondSuccess(c, ExclusionStatusResponse{Excluded: true, Exclusion: &e}, "Exclusion started.")
+}
+
+// ListExclusionsHandler is the admin report of self-excluded accounts
+// (status=active, the default, or status=all).
+func ListExclusionsHandler(c *gin.Context) {
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	list, total, err := ListExclusions(c.Query("status") != "all", page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch exclusions.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"exclusions": list, "total": total, "page": page, "limit": limit}, "Exclusions found")
+}
+
+// GetRealityCheckHandler returns the player's reality-check interval and any
+// reminder still waiting for acknowledgement.
+func GetRealityCheckHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	interval, err := GetRealityCheckInterval(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch reality-check settings.", err.Error())
+		return
+	}
+	pending, err := pendingRealityCheck(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch reality-check settings.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, RealityCheckSettingsResponse{IntervalMinutes: interval, Pending: pending}, "Reality-check settings fetched successfully.")
+}
+
+// SetRealityCheckHandler changes how often the player is reminded of time and money spent.
+func SetRealityCheckHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	var req RealityCheckSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	previous, err := GetRealityCheckInterval(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch reality-check settings.", err.Error())
+		return
+	}
+	if err := SetRealityCheckInterval(userID, req.IntervalMinutes); err != nil {
+		if errors.Is(err, ErrInvalidInterval) {
+			utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+		} else {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to save reality-check settings.", err.Error())
+		}
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "user.reality_check_change",
+		TargetType: "user",
+		TargetID:   userID,
+		Before:     gin.H{"interval_minutes": previous},
+		After:      gin.H{"interval_minutes": req.IntervalMinutes},
+	})
+	utils.RespondSuccess(c, RealityCheckSettingsResponse{IntervalMinutes: req.IntervalMinutes}, "Reality-check interval updated.")
+}
+
+// AcknowledgeRealityCheckHandler confirms the pending reality check so betting can resume.
+func AcknowledgeRealityCheckHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	acknowledged, err := AcknowledgeRealityCheck(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to acknowledge reality check.", err.Error())
+		return
+	}
+	if !acknowledged {
+		utils.RespondError(c, http.StatusConflict, "NO_PENDING_REALITY_CHECK", "There is no reality check to acknowledge.", nil)
+		return
+	}
+	utils.RespondSuccess(c, nil, "Reality check acknowledged.")
+}
+
+// GetMyPlaySessionsHandler lists the player's play sessions with duration, amount wagered and net result.
+func GetMyPlaySessionsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	limit := 20
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	list, err := GetPlaySessions(userID, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch play sessions.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, list, "Play sessions fetched successfully.")
+}
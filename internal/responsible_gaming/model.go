@@ -0,0 +1,131 @@
+package responsible_gaming
+
+import (
+	"berry_bet/config"
+	"database/sql"
+)
+
+// dbtx é satisfeita por *sql.DB e *sql.Tx, para que as verificações possam
+// rodar dentro da transação que movimenta o saldo.
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Limit é um limite do jogador, com a alteração pendente, se houver.
+type Limit struct {
+	Kind               string   `json:"kind"`
+	Period             string   `json:"period"`
+	Amount             *float64 `json:"amount"`
+	PendingAmount      *float64 `json:"pending_amount"`
+	PendingEffectiveAt *string  `json:"pending_effective_at"`
+	HasPending         bool     `json:"has_pending"`
+}
+
+// applyMatured efetiva as alterações cujo período de espera já terminou.
+func applyMatured(db dbtx, userID int64) error {
+	_, err := db.Exec(`
+		UPDATE player_limits
+		SET amount = pending_amount, pending_amount = NULL, pending_effective_at = NULL, updated_at = datetime('now')
+		WHERE user_id = ? AND pending_effective_at IS NOT NULL AND pending_effective_at <= datetime('now')`, userID)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM player_limits WHERE user_id = ? AND amount IS NULL AND pending_effective_at IS NULL`, userID)
+	return err
+}
+
+func getLimits(db dbtx, userID int64) ([]Limit, error) {
+	if err := applyMatured(db, userID); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`
+		SELECT kind, period, amount, pending_amount, CAST(pending_effective_at AS TEXT)
+		FROM player_limits WHERE user_id = ?
+		ORDER BY kind, CASE period WHEN 'daily' THEN 1 WHEN 'weekly' THEN 2 ELSE 3 END`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var limits []Limit
+	for rows.Next() {
+		var l Limit
+		var amount, pending sql.NullFloat64
+		var effectiveAt sql.NullString
+		if err := rows.Scan(&l.Kind, &l.Period, &amount, &pending, &effectiveAt); err != nil {
+			return nil, err
+		}
+		if amount.Valid {
+			l.Amount = &amount.Float64
+		}
+		if pending.Valid {
+			l.PendingAmount = &pending.Float64
+		}
+		if effectiveAt.Valid {
+			l.PendingEffectiveAt = &effectiveAt.String
+			l.HasPending = true
+		}
+		limits = append(limits, l)
+	}
+	return limits, rows.Err()
+}
+
+// GetLimits devolve os limites vigentes e pendentes do jogador.
+func GetLimits(userID int64) ([]Limit, error) {
+	return getLimits(config.DB, userID)
+}
+
+func saveLimit(l Limit, userID int64) error {
+	_, err := config.DB.Exec(`
+		INSERT INTO player_limits (user_id, kind, period, amount, pending_amount, pending_effective_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
+		ON CONFLICT(user_id, kind, period) DO UPDATE SET
+			amount = excluded.amount,
+			pending_amount = excluded.pending_amount,
+			pending_effective_at = excluded.pending_effective_at,
+			updated_at = excluded.updated_at`,
+		userID, l.Kind, l.Period, l.Amount, l.PendingAmount, l.PendingEffectiveAt)
+	return err
+}
+
+func deleteLimit(userID int64, kind, period string) error {
+	_, err := config.DB.Exec(`DELETE FROM player_limits WHERE user_id = ? AND kind = ? AND period = ?`, userID, kind, period)
+	return err
+}
+
+// usage soma o consumo de cada tipo de limite na janela informada (modificador
+// de datetime do SQLite, por exemplo "-1 day"). As apostas da roleta ficam no
+// ledger (o valor da aposta pode estar gravado com sinal negativo); as apostas
+// em jogos ficam apenas na tabela bets, onde apostas pendentes contam como
+// perda integral até serem resolvidas.
+func usage(db dbtx, userID int64, window string) (map[string]float64, error) {
+	var deposits, ledgerBets, ledgerWins float64
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN type = 'deposit' THEN ABS(amount) END), 0),
+			COALESCE(SUM(CASE WHEN type = 'bet' THEN ABS(amount) END), 0),
+			COALESCE(SUM(CASE WHEN type = 'win' THEN ABS(amount) END), 0)
+		FROM transactions
+		WHERE user_id = ? AND type IN ('deposit', 'bet', 'win') AND created_at >= datetime('now', ?)`,
+		userID, window).Scan(&deposits, &ledgerBets, &ledgerWins)
+	if err != nil {
+		return nil, err
+	}
+	var gameBets, gameLoss float64
+	err = db.QueryRow(`
+		SELECT
+			COALESCE(SUM(amount), 0),
+			COALESCE(SUM(CASE WHEN bet_status = 'pending' THEN amount ELSE -profit_loss END), 0)
+		FROM bets
+		WHERE user_id = ? AND created_at >= datetime('now', ?)`,
+		userID, window).Scan(&gameBets, &gameLoss)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{
+		KindDeposit: deposits,
+		KindWager:   ledgerBets + gameBets,
+		KindLoss:    ledgerBets - ledgerWins + gameLoss,
+	}, nil
+}
@@ -0,0 +1,263 @@
+package responsible_gaming
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// SessionIdleTimeout encerra a sessão de jogo após esse tempo sem apostas.
+	SessionIdleTimeout = 30 * time.Minute
+
+	DefaultRealityCheckInterval = 60 // minutos
+	MinRealityCheckInterval     = 10
+	MaxRealityCheckInterval     = 240
+)
+
+// RealityCheckHeader confirma, na aposta seguinte, o último lembrete exibido.
+const RealityCheckHeader = "X-Reality-Check-Ack"
+
+var ErrInvalidInterval = fmt.Errorf("interval_minutes must be between %d and %d", MinRealityCheckInterval, MaxRealityCheckInterval)
+
+// PlaySession é uma sessão de jogo, aberta ou encerrada.
+type PlaySession struct {
+	ID              int64   `json:"id"`
+	StartedAt       string  `json:"started_at"`
+	LastActivityAt  string  `json:"last_activity_at"`
+	EndedAt         *string `json:"ended_at"`
+	DurationMinutes float64 `json:"duration_minutes"`
+	Bets            int     `json:"bets"`
+	Wagered         float64 `json:"wagered"`
+	NetResult       float64 `json:"net_result"`
+	RealityChecks   int     `json:"reality_checks"`
+}
+
+// RealityCheck é o lembrete devolvido na resposta da aposta.
+type RealityCheck struct {
+	SessionID        int64   `json:"session_id"`
+	SessionStartedAt string  `json:"session_started_at"`
+	ElapsedMinutes   float64 `json:"elapsed_minutes"`
+	Bets             int     `json:"bets"`
+	Wagered          float64 `json:"wagered"`
+	NetResult        float64 `json:"net_result"`
+	IntervalMinutes  int     `json:"interval_minutes"`
+	Message          string  `json:"message"`
+	AcknowledgeWith  string  `json:"acknowledge_with"`
+}
+
+// RealityCheckError indica que o jogador precisa confirmar o último lembrete.
+type RealityCheckError struct {
+	RealityCheck
+}
+
+func (e *RealityCheckError) Error() string {
+	return "acknowledge the reality check before placing another bet"
+}
+
+const playSessionColumns = `id, CAST(started_at AS TEXT), CAST(last_activity_at AS TEXT), CAST(ended_at AS TEXT),
+	ROUND((julianday(COALESCE(ended_at, last_activity_at)) - julianday(started_at)) * 1440, 1),
+	bets_count, wagered, net_result, reality_checks`
+
+func scanPlaySession(row interface{ Scan(dest ...any) error }) (PlaySession, error) {
+	var s PlaySession
+	var endedAt sql.NullString
+	err := row.Scan(&s.ID, &s.StartedAt, &s.LastActivityAt, &endedAt, &s.DurationMinutes, &s.Bets, &s.Wagered, &s.NetResult, &s.RealityChecks)
+	if endedAt.Valid {
+		s.EndedAt = &endedAt.String
+	}
+	return s, err
+}
+
+// CloseIdleSessions encerra as sessões sem apostas há mais de
+// SessionIdleTimeout; o fim da sessão é a última aposta. userID 0 encerra as
+// de todos os jogadores.
+func CloseIdleSessions(userID int64) (int64, error) {
+	res, err := config.DB.Exec(`
+		UPDATE play_sessions SET ended_at = last_activity_at, reality_check_pending = 0
+		WHERE ended_at IS NULL AND last_activity_at <= datetime('now', ?) AND (? = 0 OR user_id = ?)`,
+		fmt.Sprintf("-%d seconds", int(SessionIdleTimeout.Seconds())), userID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetRealityCheckInterval devolve o intervalo do jogador em minutos.
+func GetRealityCheckInterval(userID int64) (int, error) {
+	var minutes int
+	err := config.DB.QueryRow(`SELECT interval_minutes FROM reality_check_settings WHERE user_id = ?`, userID).Scan(&minutes)
+	if err == sql.ErrNoRows {
+		return DefaultRealityCheckInterval, nil
+	}
+	return minutes, err
+}
+
+// SetRealityCheckInterval grava o intervalo do jogador em minutos.
+func SetRealityCheckInterval(userID int64, minutes int) error {
+	if minutes < MinRealityCheckInterval || minutes > MaxRealityCheckInterval {
+		return ErrInvalidInterval
+	}
+	_, err := config.DB.Exec(`
+		INSERT INTO reality_check_settings (user_id, interval_minutes, updated_at) VALUES (?, ?, datetime('now'))
+		ON CONFLICT(user_id) DO UPDATE SET interval_minutes = excluded.interval_minutes, updated_at = excluded.updated_at`,
+		userID, minutes)
+	return err
+}
+
+func realityCheckFor(s PlaySession, interval int) *RealityCheck {
+	return &RealityCheck{
+		SessionID:        s.ID,
+		SessionStartedAt: s.StartedAt,
+		ElapsedMinutes:   s.DurationMinutes,
+		Bets:             s.Bets,
+		Wagered:          s.Wagered,
+		NetResult:        s.NetResult,
+		IntervalMinutes:  interval,
+		Message: fmt.Sprintf("Você está jogando há %.0f minutos: %d apostas, R$ %.2f apostados, resultado de R$ %.2f.",
+			s.DurationMinutes, s.Bets, s.Wagered, s.NetResult),
+		AcknowledgeWith: "POST /api/users/me/reality_check/ack ou header " + RealityCheckHeader + ": true na próxima aposta",
+	}
+}
+
+// pendingRealityCheck devolve o lembrete ainda não confirmado da sessão aberta, se houver.
+func pendingRealityCheck(userID int64) (*RealityCheck, error) {
+	if _, err := CloseIdleSessions(userID); err != nil {
+		return nil, err
+	}
+	s, err := scanPlaySession(config.DB.QueryRow(`
+		SELECT `+playSessionColumns+` FROM play_sessions
+		WHERE user_id = ? AND ended_at IS NULL AND reality_check_pending = 1`, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	interval, err := GetRealityCheckInterval(userID)
+	if err != nil {
+		return nil, err
+	}
+	return realityCheckFor(s, interval), nil
+}
+
+// AcknowledgeRealityCheck confirma o lembrete pendente; devolve false se não havia nenhum.
+func AcknowledgeRealityCheck(userID int64) (bool, error) {
+	res, err := config.DB.Exec(`
+		UPDATE play_sessions SET reality_check_pending = 0
+		WHERE user_id = ? AND ended_at IS NULL AND reality_check_pending = 1`, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// checkRealityCheck recusa a aposta enquanto houver lembrete pendente, a menos
+// que acknowledged indique a confirmação explícita enviada com a aposta.
+func checkRealityCheck(userID int64, acknowledged bool) error {
+	rc, err := pendingRealityCheck(userID)
+	if err != nil || rc == nil {
+		return err
+	}
+	if !acknowledged {
+		return &RealityCheckError{RealityCheck: *rc}
+	}
+	_, err = AcknowledgeRealityCheck(userID)
+	return err
+}
+
+// RecordPlay soma a aposta à sessão aberta (ou abre uma nova) e devolve o
+// lembrete quando o intervalo do jogador foi atingido. netResult é o ganho
+// menos o valor apostado (negativo em perdas; zero para apostas pendentes).
+func RecordPlay(userID int64, wagered, netResult float64) (*RealityCheck, error) {
+	if _, err := CloseIdleSessions(userID); err != nil {
+		return nil, err
+	}
+	res, err := config.DB.Exec(`
+		UPDATE play_sessions
+		SET last_activity_at = datetime('now'), bets_count = bets_count + 1,
+			wagered = wagered + ?, net_result = net_result + ?
+		WHERE user_id = ? AND ended_at IS NULL`, wagered, netResult, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		_, err = config.DB.Exec(`
+			INSERT INTO play_sessions (user_id, started_at, last_activity_at, bets_count, wagered, net_result)
+			VALUES (?, datetime('now'), datetime('now'), 1, ?, ?)`, userID, wagered, netResult)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	interval, err := GetRealityCheckInterval(userID)
+	if err != nil {
+		return nil, err
+	}
+	res, err = config.DB.Exec(`
+		UPDATE play_sessions
+		SET reality_check_pending = 1, reality_checks = reality_checks + 1, last_reality_check_at = datetime('now')
+		WHERE user_id = ? AND ended_at IS NULL AND reality_check_pending = 0
+			AND (julianday('now') - julianday(COALESCE(last_reality_check_at, started_at))) * 1440 >= ?`,
+		userID, interval)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	s, err := scanPlaySession(config.DB.QueryRow(`SELECT `+playSessionColumns+` FROM play_sessions WHERE user_id = ? AND ended_at IS NULL`, userID))
+	if err != nil {
+		return nil, err
+	}
+	return realityCheckFor(s, interval), nil
+}
+
+// TrackPlay é RecordPlay para os handlers de aposta: falhas são apenas
+// logadas, pois a aposta já foi registrada.
+func TrackPlay(userID int64, wagered, netResult float64) *RealityCheck {
+	rc, err := RecordPlay(userID, wagered, netResult)
+	if err != nil {
+		log.Printf("Erro ao registrar sessão de jogo do usuário %d: %v", userID, err)
+	}
+	return rc
+}
+
+// GetPlaySessions devolve as sessões do jogador, das mais recentes para as mais antigas.
+func GetPlaySessions(userID int64, limit int) ([]PlaySession, error) {
+	if _, err := CloseIdleSessions(userID); err != nil {
+		return nil, err
+	}
+	rows, err := config.DB.Query(`
+		SELECT `+playSessionColumns+` FROM play_sessions
+		WHERE user_id = ? ORDER BY started_at DESC, id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []PlaySession{}
+	for rows.Next() {
+		s, err := scanPlaySession(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// StartWorker encerra periodicamente as sessões de jogo inativas.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := CloseIdleSessions(0); err != nil {
+				log.Printf("Erro ao encerrar sessões de jogo inativas: %v", err)
+			}
+		}
+	}()
+}
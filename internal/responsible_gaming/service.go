@@ -0,0 +1,159 @@
+package responsible_gaming
+
+import (
+	"berry_bet/config"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	KindDeposit = "deposit"
+	KindLoss    = "loss"
+	KindWager   = "wager"
+)
+
+// Periods associa cada período à janela móvel usada para medir o consumo.
+var Periods = map[string]string{
+	"daily":   "-1 day",
+	"weekly":  "-7 days",
+	"monthly": "-30 days",
+}
+
+// CoolingPeriod é a espera até que um aumento ou remoção de limite passe a valer.
+const CoolingPeriod = 24 * time.Hour
+
+var (
+	ErrInvalidKind   = errors.New("kind must be deposit, loss or wager")
+	ErrInvalidPeriod = errors.New("period must be daily, weekly or monthly")
+	ErrInvalidAmount = errors.New("amount must be zero or greater")
+)
+
+// LimitError indica que a operação ultrapassaria um limite do jogador.
+type LimitError struct {
+	Kind      string  `json:"kind"`
+	Period    string  `json:"period"`
+	Limit     float64 `json:"limit"`
+	Remaining float64 `json:"remaining"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %s limit reached: %.2f remaining of %.2f", e.Period, e.Kind, e.Remaining, e.Limit)
+}
+
+// Allowance é um limite com o consumo atual e o saldo disponível.
+type Allowance struct {
+	Limit
+	Used      float64
+	Remaining float64
+}
+
+// SetLimit define (ou remove, com amount nil) um limite. Limites novos e
+// reduções valem imediatamente e descartam aumentos pendentes; aumentos e
+// remoções só valem após CoolingPeriod. Devolve o limite resultante e se a
+// alteração ficou pendente.
+func SetLimit(userID int64, kind, period string, amount *float64) (Limit, bool, error) {
+	if kind != KindDeposit && kind != KindLoss && kind != KindWager {
+		return Limit{}, false, ErrInvalidKind
+	}
+	if _, ok := Periods[period]; !ok {
+		return Limit{}, false, ErrInvalidPeriod
+	}
+	if amount != nil && (*amount < 0 || math.IsNaN(*amount) || math.IsInf(*amount, 0)) {
+		return Limit{}, false, ErrInvalidAmount
+	}
+	limits, err := GetLimits(userID)
+	if err != nil {
+		return Limit{}, false, err
+	}
+	current := Limit{Kind: kind, Period: period}
+	for _, l := range limits {
+		if l.Kind == kind && l.Period == period {
+			current = l
+		}
+	}
+
+	next := Limit{Kind: kind, Period: period, Amount: current.Amount}
+	switch {
+	case amount != nil && (current.Amount == nil || *amount <= *current.Amount):
+		next.Amount = amount
+	case amount == nil && current.Amount == nil:
+		// Não há limite vigente: basta cancelar o pendente.
+		return Limit{Kind: kind, Period: period}, false, deleteLimit(userID, kind, period)
+	default:
+		effectiveAt := time.Now().UTC().Add(CoolingPeriod).Format("2006-01-02 15:04:05")
+		next.PendingAmount = amount
+		next.PendingEffectiveAt = &effectiveAt
+		next.HasPending = true
+	}
+	if err := saveLimit(next, userID); err != nil {
+		return Limit{}, false, err
+	}
+	return next, next.HasPending, nil
+}
+
+func allowances(db dbtx, userID int64) ([]Allowance, error) {
+	limits, err := getLimits(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]map[string]float64{}
+	result := make([]Allowance, 0, len(limits))
+	for _, l := range limits {
+		a := Allowance{Limit: l}
+		if l.Amount != nil {
+			if used[l.Period] == nil {
+				if used[l.Period], err = usage(db, userID, Periods[l.Period]); err != nil {
+					return nil, err
+				}
+			}
+			a.Used = math.Max(0, used[l.Period][l.Kind])
+			a.Remaining = math.Max(0, *l.Amount-a.Used)
+		}
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// GetAllowances devolve os limites do jogador com o consumo nas janelas atuais.
+func GetAllowances(userID int64) ([]Allowance, error) {
+	return allowances(config.DB, userID)
+}
+
+// check confere se amount cabe nos limites dos tipos informados.
+func check(db dbtx, userID int64, amount float64, kinds ...string) error {
+	list, err := allowances(db, userID)
+	if err != nil {
+		return err
+	}
+	for _, a := range list {
+		if a.Amount == nil {
+			continue
+		}
+		for _, kind := range kinds {
+			if a.Kind == kind && amount > a.Remaining {
+				return &LimitError{Kind: a.Kind, Period: a.Period, Limit: *a.Amount, Remaining: a.Remaining}
+			}
+		}
+	}
+	return nil
+}
+
+// CheckDepositTx verifica a autoexclusão e os limites de depósito dentro da
+// transação do depósito.
+func CheckDepositTx(db dbtx, userID int64, amount float64) error {
+	if err := checkNotExcluded(db, userID); err != nil {
+		return err
+	}
+	return check(db, userID, amount, KindDeposit)
+}
+
+// CheckWager verifica a autoexclusão e os limites de apostas e de perdas. A
+// aposta conta como perda integral, pois é o pior resultado possível.
+func CheckWager(userID int64, amount float64) error {
+	if err := checkNotExcluded(config.DB, userID); err != nil {
+		return err
+	}
+	return check(config.DB, userID, amount, KindWager, KindLoss)
+}
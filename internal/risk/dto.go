@@ -0,0 +1,11 @@
+package risk
+
+type ResolveReviewRequest struct {
+	Notes string `json:"notes"`
+}
+
+type PlayerRiskResponse struct {
+	UserID  int64    `json:"user_id"`
+	Scores  []Score  `json:"scores"`
+	Reviews []Review `json:"reviews"`
+}
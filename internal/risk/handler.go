@@ -0,0 +1,95 @@
+package risk
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// ListFlaggedPlayersHandler lists players in the high or severe tier, or with
+// an open review, highest risk first.
+func ListFlaggedPlayersHandler(c *gin.Context) {
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	list, total, err := ListFlagged(page, limit)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch flagged players.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"players": list, "total": total, "page": page, "limit": limit}, "Flagged players found")
+}
+
+// GetPlayerRiskHandler returns a player's score history and reviews.
+func GetPlayerRiskHandler(c *gin.Context) {
+	userID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	scores, err := GetScoreHistory(userID, 50)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch risk scores.", err.Error())
+		return
+	}
+	reviews, err := GetReviews(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch risk reviews.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, PlayerRiskResponse{UserID: userID, Scores: scores, Reviews: reviews}, "Player risk found")
+}
+
+// RescorePlayerHandler recomputes a player's score immediately.
+func RescorePlayerHandler(c *gin.Context) {
+	userID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	s, err := ScoreUser(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to compute risk score.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, s, "Risk score computed.")
+}
+
+// ResolveReviewHandler closes an open account review with the reviewer's notes.
+func ResolveReviewHandler(c *gin.Context) {
+	reviewID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req ResolveReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	err := ResolveReview(audit.ActorFromContext(c), reviewID, req.Notes)
+	switch {
+	case errors.Is(err, ErrNotesRequired):
+		utils.RespondError(c, http.StatusBadRequest, "BUSINESS_RULE", err.Error(), nil)
+	case errors.Is(err, ErrReviewNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to resolve review.", err.Error())
+	default:
+		utils.RespondSuccess(c, nil, "Review resolved.")
+	}
+}
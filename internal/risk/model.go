@@ -0,0 +1,232 @@
+package risk
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrReviewNotFound = errors.New("review not found or already resolved")
+	ErrNotesRequired  = errors.New("notes are required (at least 5 characters)")
+)
+
+// Score é um cálculo de risco gravado no histórico.
+type Score struct {
+	ID            int64           `json:"id"`
+	UserID        int64           `json:"user_id"`
+	Score         float64         `json:"score"`
+	Tier          string          `json:"tier"`
+	Indicators    json.RawMessage `json:"indicators"`
+	Interventions json.RawMessage `json:"interventions"`
+	ComputedAt    string          `json:"computed_at"`
+}
+
+// Review é uma conta encaminhada para análise manual.
+type Review struct {
+	ID              int64   `json:"id"`
+	UserID          int64   `json:"user_id"`
+	ScoreID         int64   `json:"score_id"`
+	Status          string  `json:"status"`
+	ResolutionNotes *string `json:"resolution_notes"`
+	ResolvedBy      *int64  `json:"resolved_by"`
+	OpenedAt        string  `json:"opened_at"`
+	ResolvedAt      *string `json:"resolved_at"`
+}
+
+// FlaggedPlayer é uma linha da fila de revisão do back-office.
+type FlaggedPlayer struct {
+	UserID     int64   `json:"user_id"`
+	Username   string  `json:"username"`
+	Email      string  `json:"email"`
+	Score      Score   `json:"latest_score"`
+	OpenReview *Review `json:"open_review"`
+}
+
+const scoreColumns = `id, user_id, score, tier, indicators, interventions, CAST(computed_at AS TEXT)`
+
+func scanScore(row interface{ Scan(dest ...any) error }) (Score, error) {
+	var s Score
+	var indicators, interventions string
+	err := row.Scan(&s.ID, &s.UserID, &s.Score, &s.Tier, &indicators, &interventions, &s.ComputedAt)
+	s.Indicators = json.RawMessage(indicators)
+	s.Interventions = json.RawMessage(interventions)
+	return s, err
+}
+
+const reviewColumns = `id, user_id, score_id, status, resolution_notes, resolved_by, CAST(opened_at AS TEXT), CAST(resolved_at AS TEXT)`
+
+func scanReview(row interface{ Scan(dest ...any) error }) (Review, error) {
+	var r Review
+	var notes, resolvedAt sql.NullString
+	var resolvedBy sql.NullInt64
+	err := row.Scan(&r.ID, &r.UserID, &r.ScoreID, &r.Status, &notes, &resolvedBy, &r.OpenedAt, &resolvedAt)
+	if notes.Valid {
+		r.ResolutionNotes = &notes.String
+	}
+	if resolvedBy.Valid {
+		r.ResolvedBy = &resolvedBy.Int64
+	}
+	if resolvedAt.Valid {
+		r.ResolvedAt = &resolvedAt.String
+	}
+	return r, err
+}
+
+// latestTier devolve a faixa do último cálculo do jogador ("" se nunca calculado).
+func latestTier(userID int64) (string, error) {
+	var tier string
+	err := config.DB.QueryRow(`SELECT tier FROM risk_scores WHERE user_id = ? ORDER BY computed_at DESC, id DESC LIMIT 1`, userID).Scan(&tier)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return tier, err
+}
+
+func insertScore(s *Score) error {
+	s.ComputedAt = time.Now().UTC().Format("2006-01-02 15:04:05")
+	res, err := config.DB.Exec(`
+		INSERT INTO risk_scores (user_id, score, tier, indicators, interventions, computed_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, s.UserID, s.Score, s.Tier, string(s.Indicators), string(s.Interventions), s.ComputedAt)
+	if err != nil {
+		return err
+	}
+	s.ID, err = res.LastInsertId()
+	return err
+}
+
+// openReview abre uma análise, a menos que já exista uma aberta para o jogador.
+func openReview(userID, scoreID int64) (bool, error) {
+	res, err := config.DB.Exec(`
+		INSERT INTO risk_reviews (user_id, score_id, status, opened_at) VALUES (?, ?, 'open', datetime('now'))
+		ON CONFLICT DO NOTHING`, userID, scoreID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetScoreHistory devolve os cálculos do jogador, dos mais recentes para os mais antigos.
+func GetScoreHistory(userID int64, limit int) ([]Score, error) {
+	rows, err := config.DB.Query(`SELECT `+scoreColumns+` FROM risk_scores WHERE user_id = ? ORDER BY computed_at DESC, id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Score{}
+	for rows.Next() {
+		s, err := scanScore(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// GetReviews devolve as análises do jogador, das mais recentes para as mais antigas.
+func GetReviews(userID int64) ([]Review, error) {
+	rows, err := config.DB.Query(`SELECT `+reviewColumns+` FROM risk_reviews WHERE user_id = ? ORDER BY opened_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Review{}
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// ListFlagged lista os jogadores cujo último cálculo está em faixa alta ou
+// severa, ou que têm análise aberta, dos maiores riscos para os menores.
+func ListFlagged(page, limit int) ([]FlaggedPlayer, int, error) {
+	const base = `
+		FROM risk_scores s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN risk_reviews r ON r.user_id = s.user_id AND r.status = 'open'
+		WHERE s.id = (SELECT id FROM risk_scores WHERE user_id = s.user_id ORDER BY computed_at DESC, id DESC LIMIT 1)
+			AND (s.tier IN ('high', 'severe') OR r.id IS NOT NULL)`
+	var total int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) ` + base).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := config.DB.Query(`
+		SELECT s.id, s.user_id, s.score, s.tier, s.indicators, s.interventions, CAST(s.computed_at AS TEXT),
+			u.username, u.email,
+			r.id, r.score_id, CAST(r.opened_at AS TEXT)
+		`+base+`
+		ORDER BY r.id IS NULL, s.score DESC LIMIT ? OFFSET ?`, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	list := []FlaggedPlayer{}
+	for rows.Next() {
+		var p FlaggedPlayer
+		var indicators, interventions string
+		var reviewID, reviewScoreID sql.NullInt64
+		var openedAt sql.NullString
+		err := rows.Scan(&p.Score.ID, &p.Score.UserID, &p.Score.Score, &p.Score.Tier, &indicators, &interventions, &p.Score.ComputedAt,
+			&p.Username, &p.Email, &reviewID, &reviewScoreID, &openedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		p.UserID = p.Score.UserID
+		p.Score.Indicators = json.RawMessage(indicators)
+		p.Score.Interventions = json.RawMessage(interventions)
+		if reviewID.Valid {
+			p.OpenReview = &Review{ID: reviewID.Int64, UserID: p.UserID, ScoreID: reviewScoreID.Int64, Status: "open", OpenedAt: openedAt.String}
+		}
+		list = append(list, p)
+	}
+	return list, total, rows.Err()
+}
+
+// getOpenReview devolve a análise aberta de id informado.
+func getOpenReview(id int64) (Review, error) {
+	r, err := scanReview(config.DB.QueryRow(`SELECT `+reviewColumns+` FROM risk_reviews WHERE id = ? AND status = 'open'`, id))
+	if err == sql.ErrNoRows {
+		return r, ErrReviewNotFound
+	}
+	return r, err
+}
+
+func resolveReview(id, adminID int64, notes string) (bool, error) {
+	res, err := config.DB.Exec(`
+		UPDATE risk_reviews SET status = 'resolved', resolution_notes = ?, resolved_by = ?, resolved_at = datetime('now')
+		WHERE id = ? AND status = 'open'`, notes, adminID, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// activePlayers devolve os jogadores com apostas ou depósitos na janela de análise.
+func activePlayers(window string) ([]int64, error) {
+	rows, err := config.DB.Query(`
+		SELECT user_id FROM bet_history WHERE created_at >= datetime('now', ?)
+		UNION
+		SELECT user_id FROM transactions WHERE type IN ('bet', 'deposit') AND created_at >= datetime('now', ?)`, window, window)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,337 @@
+package risk
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/responsible_gaming"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	TierLow    = "low"
+	TierMedium = "medium"
+	TierHigh   = "high"
+	TierSevere = "severe"
+)
+
+var tierRank = map[string]int{"": 0, TierLow: 1, TierMedium: 2, TierHigh: 3, TierSevere: 4}
+
+const (
+	// analysisWindow é a janela de comportamento considerada no cálculo.
+	analysisWindow = "-30 days"
+	// localTimeOffset converte UTC para o horário de Brasília, usado na
+	// definição de jogo noturno (0h às 6h).
+	localTimeOffset = "-3 hours"
+	nightStartHour  = 0
+	nightEndHour    = 6
+
+	// Limites aplicados pela intervenção da faixa alta, se o jogador não tiver limites menores.
+	forcedDailyDepositLimit = 200.0
+	forcedDailyLossLimit    = 100.0
+)
+
+// Indicators são os sinais de comportamento usados na pontuação.
+type Indicators struct {
+	Bets                  int     `json:"bets"`
+	BetsAfterLoss         int     `json:"bets_after_loss"`
+	LossChasingRatio      float64 `json:"loss_chasing_ratio"` // fração das apostas após perdas com valor maior que a anterior
+	NightPlayRatio        float64 `json:"night_play_ratio"`   // fração das apostas entre 0h e 6h (Brasília)
+	Deposits7d            int     `json:"deposits_7d"`
+	AvgSessionMinutes     float64 `json:"avg_session_minutes"`
+	LongestSessionMinutes float64 `json:"longest_session_minutes"`
+	ConsecutiveLosses     int     `json:"consecutive_losses"`
+}
+
+// collectIndicators calcula os indicadores do jogador a partir de
+// bet_history, transactions, play_sessions e user_stats.
+func collectIndicators(userID int64) (Indicators, error) {
+	var ind Indicators
+	rows, err := config.DB.Query(`
+		SELECT bet_amount, result, CAST(strftime('%H', created_at, ?) AS INTEGER)
+		FROM bet_history
+		WHERE user_id = ? AND created_at >= datetime('now', ?)
+		ORDER BY created_at, id`, localTimeOffset, userID, analysisWindow)
+	if err != nil {
+		return ind, err
+	}
+	defer rows.Close()
+	var prevAmount float64
+	var prevLoss bool
+	var raised, night int
+	for rows.Next() {
+		var amount float64
+		var result string
+		var hour int
+		if err := rows.Scan(&amount, &result, &hour); err != nil {
+			return ind, err
+		}
+		if prevLoss {
+			ind.BetsAfterLoss++
+			if amount > prevAmount {
+				raised++
+			}
+		}
+		if hour >= nightStartHour && hour < nightEndHour {
+			night++
+		}
+		ind.Bets++
+		prevAmount, prevLoss = amount, result == "loss"
+	}
+	if err := rows.Err(); err != nil {
+		return ind, err
+	}
+	if ind.BetsAfterLoss > 0 {
+		ind.LossChasingRatio = round2(float64(raised) / float64(ind.BetsAfterLoss))
+	}
+	if ind.Bets > 0 {
+		ind.NightPlayRatio = round2(float64(night) / float64(ind.Bets))
+	}
+
+	err = config.DB.QueryRow(`
+		SELECT COUNT(*) FROM transactions
+		WHERE user_id = ? AND type = 'deposit' AND created_at >= datetime('now', '-7 days')`, userID).Scan(&ind.Deposits7d)
+	if err != nil {
+		return ind, err
+	}
+	err = config.DB.QueryRow(`
+		SELECT COALESCE(AVG(minutes), 0), COALESCE(MAX(minutes), 0) FROM (
+			SELECT (julianday(COALESCE(ended_at, last_activity_at)) - julianday(started_at)) * 1440 AS minutes
+			FROM play_sessions WHERE user_id = ? AND started_at >= datetime('now', ?))`,
+		userID, analysisWindow).Scan(&ind.AvgSessionMinutes, &ind.LongestSessionMinutes)
+	if err != nil {
+		return ind, err
+	}
+	ind.AvgSessionMinutes = round2(ind.AvgSessionMinutes)
+	ind.LongestSessionMinutes = round2(ind.LongestSessionMinutes)
+	err = config.DB.QueryRow(`SELECT COALESCE(consecutive_losses, 0) FROM user_stats WHERE user_id = ?`, userID).Scan(&ind.ConsecutiveLosses)
+	if err != nil && err != sql.ErrNoRows {
+		return ind, err
+	}
+	return ind, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Evaluate converte os indicadores em uma pontuação de 0 a 100 e na faixa de
+// risco. Proporções só contam com amostra mínima, para que poucas apostas não
+// gerem alertas.
+func Evaluate(ind Indicators) (float64, string) {
+	score := 0.0
+	if ind.BetsAfterLoss >= 5 {
+		score += 30 * ind.LossChasingRatio
+	}
+	if ind.Bets >= 10 {
+		score += 20 * ind.NightPlayRatio
+	}
+	score += 20 * math.Min(float64(ind.Deposits7d)/10, 1)
+	score += 10 * math.Min(ind.AvgSessionMinutes/180, 1)
+	score += 10 * math.Min(ind.LongestSessionMinutes/360, 1)
+	score += 10 * math.Min(float64(ind.ConsecutiveLosses)/10, 1)
+	score = round2(score)
+
+	switch {
+	case score >= 70:
+		return score, TierSevere
+	case score >= 50:
+		return score, TierHigh
+	case score >= 30:
+		return score, TierMedium
+	}
+	return score, TierLow
+}
+
+// ScoreUser calcula e grava a pontuação do jogador. Quando a faixa sobe em
+// relação ao cálculo anterior, dispara as intervenções da nova faixa.
+func ScoreUser(userID int64) (Score, error) {
+	ind, err := collectIndicators(userID)
+	if err != nil {
+		return Score{}, err
+	}
+	value, tier := Evaluate(ind)
+	previous, err := latestTier(userID)
+	if err != nil {
+		return Score{}, err
+	}
+	indicators, err := json.Marshal(ind)
+	if err != nil {
+		return Score{}, err
+	}
+	s := Score{UserID: userID, Score: value, Tier: tier, Indicators: indicators, Interventions: json.RawMessage("[]")}
+
+	var applied []string
+	if tierRank[tier] > tierRank[previous] {
+		applied = plannedInterventions(tier)
+		if len(applied) > 0 {
+			s.Interventions, _ = json.Marshal(applied)
+		}
+	}
+	if err := insertScore(&s); err != nil {
+		return Score{}, err
+	}
+	if len(applied) > 0 {
+		intervene(s, applied)
+	}
+	return s, nil
+}
+
+// plannedInterventions devolve as intervenções de cada faixa.
+func plannedInterventions(tier string) []string {
+	switch tier {
+	case TierMedium:
+		return []string{"message"}
+	case TierHigh:
+		return []string{"message", "forced_limits"}
+	case TierSevere:
+		return []string{"message", "forced_limits", "account_review"}
+	}
+	return nil
+}
+
+// intervene executa as intervenções. Falhas são logadas para não impedir o
+// cálculo dos demais jogadores.
+func intervene(s Score, actions []string) {
+	for _, action := range actions {
+		var err error
+		switch action {
+		case "message":
+			notifications.Notify(s.UserID, "responsible_gaming", "Como está o seu jogo?",
+				"Notamos mudanças no seu padrão de apostas. Lembre-se de jogar apenas o que pode perder. "+
+					"Você pode definir limites, pausar ou se autoexcluir em Jogo Responsável.")
+		case "forced_limits":
+			err = forceLimits(s.UserID)
+		case "account_review":
+			_, err = openReview(s.UserID, s.ID)
+		}
+		if err != nil {
+			log.Printf("Erro na intervenção %s para o usuário %d: %v", action, s.UserID, err)
+			continue
+		}
+		if err := audit.Record(audit.Entry{
+			Actor:      audit.System,
+			Action:     "risk.intervention",
+			TargetType: "user",
+			TargetID:   s.UserID,
+			Details:    map[string]any{"intervention": action, "score_id": s.ID, "score": s.Score, "tier": s.Tier},
+		}); err != nil {
+			log.Printf("Erro ao auditar intervenção %s para o usuário %d: %v", action, s.UserID, err)
+		}
+	}
+}
+
+// forceLimits aplica limites diários de depósito e de perda, sem afrouxar
+// limites menores que o jogador já tenha.
+func forceLimits(userID int64) error {
+	current, err := responsible_gaming.GetLimits(userID)
+	if err != nil {
+		return err
+	}
+	forced := map[string]float64{
+		responsible_gaming.KindDeposit: forcedDailyDepositLimit,
+		responsible_gaming.KindLoss:    forcedDailyLossLimit,
+	}
+	for kind, amount := range forced {
+		skip := false
+		for _, l := range current {
+			if l.Kind == kind && l.Period == "daily" && l.Amount != nil && *l.Amount <= amount {
+				skip = true
+			}
+		}
+		if skip {
+			continue
+		}
+		amount := amount
+		if _, _, err := responsible_gaming.SetLimit(userID, kind, "daily", &amount); err != nil {
+			return err
+		}
+	}
+	notifications.Notify(userID, "responsible_gaming", "Limites aplicados à sua conta",
+		"Por segurança, aplicamos limites diários de depósito e de perda à sua conta. Você pode consultá-los em Jogo Responsável.")
+	return nil
+}
+
+// NeedsManualReview indica se operações sensíveis do jogador (como saques)
+// devem passar por um admin: faixa alta ou severa, ou análise de conta aberta.
+func NeedsManualReview(userID int64) (bool, string, error) {
+	tier, err := latestTier(userID)
+	if err != nil {
+		return false, "", err
+	}
+	if tierRank[tier] >= tierRank[TierHigh] {
+		return true, "risk tier " + tier, nil
+	}
+	var open bool
+	err = config.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM risk_reviews WHERE user_id = ? AND status = 'open')`, userID).Scan(&open)
+	if err != nil {
+		return false, "", err
+	}
+	if open {
+		return true, "open account review", nil
+	}
+	return false, "", nil
+}
+
+// ScoreAll recalcula a pontuação de todos os jogadores ativos na janela de análise.
+func ScoreAll() (int, error) {
+	ids, err := activePlayers(analysisWindow)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if _, err := ScoreUser(id); err != nil {
+			log.Printf("Erro ao calcular risco do usuário %d: %v", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+// ResolveReview encerra uma análise aberta com as observações do admin.
+func ResolveReview(actor audit.Actor, reviewID int64, notes string) error {
+	notes = strings.TrimSpace(notes)
+	if len(notes) < 5 {
+		return ErrNotesRequired
+	}
+	r, err := getOpenReview(reviewID)
+	if err != nil {
+		return err
+	}
+	ok, err := resolveReview(reviewID, actor.UserID, notes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReviewNotFound
+	}
+	return audit.Record(audit.Entry{
+		Actor:      actor,
+		Action:     "risk.review_resolved",
+		TargetType: "user",
+		TargetID:   r.UserID,
+		Details:    map[string]any{"review_id": reviewID, "notes": notes},
+	})
+}
+
+// StartWorker recalcula periodicamente a pontuação de risco dos jogadores ativos.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := ScoreAll()
+			if err != nil {
+				log.Printf("Erro ao calcular pontuações de risco: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Pontuação de risco recalculada para %d jogador(es)", n)
+			}
+		}
+	}()
+}
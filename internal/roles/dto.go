@@ -0,0 +1,10 @@
+package roles
+
+type AssignRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type UserRolesResponse struct {
+	UserID int64    `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
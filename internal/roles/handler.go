@@ -0,0 +1,106 @@
+package roles
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/sessions"
+	"berry_bet/internal/utils"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseUserID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// GetRolesHandler returns every role with its permissions.
+func GetRolesHandler(c *gin.Context) {
+	list, err := GetRoles()
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch roles.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, list, "Roles found")
+}
+
+// GetUserRolesHandler returns the roles assigned to a user.
+func GetUserRolesHandler(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	names, err := GetUserRoles(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user roles.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, UserRolesResponse{UserID: userID, Roles: names}, "User roles found")
+}
+
+// AssignRoleHandler grants a role to a user. It takes effect on the user's next token refresh.
+func AssignRoleHandler(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Role == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "role is required.", nil)
+		return
+	}
+	err := AssignRole(userID, strings.ToLower(req.Role))
+	switch {
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrUserNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to assign role.", err.Error())
+	default:
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "role.assign",
+			TargetType: "user",
+			TargetID:   userID,
+			Details:    gin.H{"role": strings.ToLower(req.Role)},
+		})
+		utils.RespondSuccess(c, nil, "Role assigned successfully.")
+	}
+}
+
+// RemoveRoleHandler revokes a role from a user and ends their sessions so the
+// reduced privileges apply immediately.
+func RemoveRoleHandler(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	err := RemoveRole(userID, strings.ToLower(c.Param("role")))
+	switch {
+	case errors.Is(err, ErrRoleNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", err.Error(), nil)
+		return
+	case errors.Is(err, ErrLastAdmin):
+		utils.RespondError(c, http.StatusConflict, "LAST_ADMIN", err.Error(), nil)
+		return
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to remove role.", err.Error())
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "role.remove",
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    gin.H{"role": strings.ToLower(c.Param("role"))},
+	})
+	if _, err := sessions.RevokeUserSessions(userID, "", "role_change"); err != nil {
+		log.Printf("Erro ao revogar sessões do usuário %d: %v", userID, err)
+	}
+	utils.RespondSuccess(c, nil, "Role removed successfully.")
+}
@@ -0,0 +1,156 @@
+package roles
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrUserNotFound = errors.New("user not found")
+	ErrLastAdmin    = errors.New("cannot remove the last admin")
+)
+
+type Role struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// GetRoles retorna todos os papéis com suas permissões.
+func GetRoles() ([]Role, error) {
+	rows, err := config.DB.Query(`SELECT id, name, COALESCE(description, '') FROM roles ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Role, 0)
+	for rows.Next() {
+		var r Role
+		if err := rows.Scan(&r.ID, &r.Name, &r.Description); err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	perms, err := loadRolePermissions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Permissions = make([]string, 0, len(perms[list[i].Name]))
+		for p := range perms[list[i].Name] {
+			list[i].Permissions = append(list[i].Permissions, p)
+		}
+	}
+	return list, nil
+}
+
+// GetUserRoles retorna os nomes dos papéis atribuídos ao usuário.
+func GetUserRoles(userID int64) ([]string, error) {
+	rows, err := config.DB.Query(`
+		SELECT r.name FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = ?
+		ORDER BY r.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// AssignRole atribui um papel ao usuário (sem efeito se já atribuído).
+func AssignRole(userID int64, role string) error {
+	roleID, err := roleIDByName(role)
+	if err != nil {
+		return err
+	}
+	var exists int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM users WHERE id = ?`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrUserNotFound
+	}
+	_, err = config.DB.Exec(`INSERT OR IGNORE INTO user_roles (user_id, role_id, created_at) VALUES (?, ?, datetime('now'))`, userID, roleID)
+	return err
+}
+
+// RemoveRole remove um papel do usuário. O último admin não pode ser removido.
+func RemoveRole(userID int64, role string) error {
+	roleID, err := roleIDByName(role)
+	if err != nil {
+		return err
+	}
+	if role == "admin" {
+		var admins int
+		err := config.DB.QueryRow(`SELECT COUNT(*) FROM user_roles WHERE role_id = ? AND user_id <> ?`, roleID, userID).Scan(&admins)
+		if err != nil {
+			return err
+		}
+		if admins == 0 {
+			return ErrLastAdmin
+		}
+	}
+	_, err = config.DB.Exec(`DELETE FROM user_roles WHERE user_id = ? AND role_id = ?`, userID, roleID)
+	return err
+}
+
+// CountUsersWithRole retorna quantos usuários possuem o papel informado.
+func CountUsersWithRole(role string) (int, error) {
+	var count int
+	err := config.DB.QueryRow(`
+		SELECT COUNT(*) FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = ?`, role).Scan(&count)
+	return count, err
+}
+
+func roleIDByName(role string) (int64, error) {
+	var id int64
+	err := config.DB.QueryRow(`SELECT id FROM roles WHERE name = ?`, role).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrRoleNotFound
+	}
+	return id, err
+}
+
+func loadRolePermissions() (map[string]map[string]bool, error) {
+	rows, err := config.DB.Query(`
+		SELECT r.name, p.name FROM role_permissions rp
+		JOIN roles r ON r.id = rp.role_id
+		JOIN permissions p ON p.id = rp.permission_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := make(map[string]map[string]bool)
+	for rows.Next() {
+		var role, perm string
+		if err := rows.Scan(&role, &perm); err != nil {
+			return nil, err
+		}
+		if perms[role] == nil {
+			perms[role] = make(map[string]bool)
+		}
+		perms[role][perm] = true
+	}
+	return perms, rows.Err()
+}
@@ -0,0 +1,69 @@
+package roles
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// permissionCacheTTL define por quanto tempo o mapa papel → permissões fica em memória.
+const permissionCacheTTL = time.Minute
+
+var permissionCache = struct {
+	sync.Mutex
+	perms    map[string]map[string]bool
+	loadedAt time.Time
+}{}
+
+// HasPermission informa se algum dos papéis concede a permissão.
+func HasPermission(userRoles []string, permission string) (bool, error) {
+	permissionCache.Lock()
+	defer permissionCache.Unlock()
+	if permissionCache.perms == nil || time.Since(permissionCache.loadedAt) > permissionCacheTTL {
+		perms, err := loadRolePermissions()
+		if err != nil {
+			return false, err
+		}
+		permissionCache.perms = perms
+		permissionCache.loadedAt = time.Now()
+	}
+	for _, role := range userRoles {
+		if permissionCache.perms[role][permission] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// BootstrapAdmin promove a admin o usuário informado (username ou e-mail) caso
+// ainda não exista nenhum admin. Usado na inicialização via BOOTSTRAP_ADMIN.
+func BootstrapAdmin(identifier string) {
+	if identifier == "" {
+		return
+	}
+	admins, err := CountUsersWithRole("admin")
+	if err != nil {
+		log.Printf("Erro ao verificar administradores: %v", err)
+		return
+	}
+	if admins > 0 {
+		return
+	}
+	var userID int64
+	err = config.DB.QueryRow(`SELECT id FROM users WHERE username = ? OR email = ?`, identifier, identifier).Scan(&userID)
+	if err == sql.ErrNoRows {
+		log.Printf("BOOTSTRAP_ADMIN: usuário %q não encontrado", identifier)
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário para BOOTSTRAP_ADMIN: %v", err)
+		return
+	}
+	if err := AssignRole(userID, "admin"); err != nil {
+		log.Printf("Erro ao promover %q a admin: %v", identifier, err)
+		return
+	}
+	log.Printf("Usuário %q promovido a admin", identifier)
+}
@@ -0,0 +1,61 @@
+package sessions
+
+import (
+	"sync"
+	"time"
+
+	"berry_bet/config"
+)
+
+// activeCacheTTL limita por quanto tempo uma revogação feita fora deste processo
+// pode passar despercebida pelo middleware.
+const activeCacheTTL = 30 * time.Second
+
+type activeEntry struct {
+	userID    int64
+	active    bool
+	checkedAt time.Time
+}
+
+var activeCache = struct {
+	sync.RWMutex
+	entries map[string]activeEntry
+}{entries: make(map[string]activeEntry)}
+
+// IsSessionActive informa se a família de sessões ainda tem um refresh token
+// válido para o usuário. O resultado fica em cache por activeCacheTTL.
+func IsSessionActive(familyID string, userID int64) (bool, error) {
+	activeCache.RLock()
+	entry, ok := activeCache.entries[familyID]
+	activeCache.RUnlock()
+	if ok && entry.userID == userID && time.Since(entry.checkedAt) < activeCacheTTL {
+		return entry.active, nil
+	}
+
+	var count int
+	err := config.DB.QueryRow(`
+		SELECT COUNT(*) FROM sessions
+		WHERE family_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > datetime('now')`,
+		familyID, userID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	activeCache.Lock()
+	activeCache.entries[familyID] = activeEntry{userID: userID, active: count > 0, checkedAt: time.Now()}
+	activeCache.Unlock()
+	return count > 0, nil
+}
+
+// forgetFamilies remove as famílias informadas do cache; sem argumentos, limpa o cache inteiro.
+func forgetFamilies(familyIDs ...string) {
+	activeCache.Lock()
+	defer activeCache.Unlock()
+	if len(familyIDs) == 0 {
+		activeCache.entries = make(map[string]activeEntry)
+		return
+	}
+	for _, id := range familyIDs {
+		delete(activeCache.entries, id)
+	}
+}
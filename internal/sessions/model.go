@@ -99,6 +99,7 @@ func UpdateSession(session Session, id int64) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	forgetFamilies()
 	return true, nil
 }
 
@@ -113,5 +114,6 @@ func DeleteSession(sessionId int) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	forgetFamilies()
 	return true, nil
 }
@@ -0,0 +1,231 @@
+package sessions
+
+import (
+	"berry_bet/config"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RefreshTokenTTL é a validade de cada refresh token emitido.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrSessionRevoked      = errors.New("session revoked")
+)
+
+// DeviceIDHeader é o header com o identificador do aparelho enviado pelo app.
+const DeviceIDHeader = "X-Device-ID"
+
+// DeviceInfo descreve o cliente que abriu ou renovou a sessão.
+type DeviceInfo struct {
+	UserAgent string
+	IPAddress string
+	DeviceID  string
+}
+
+// RefreshSession é a sessão associada a um refresh token válido.
+type RefreshSession struct {
+	ID       int64
+	UserID   int64
+	FamilyID string
+}
+
+// HashToken devolve o hash SHA-256 (hex) armazenado no lugar do refresh token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func ttlModifier() string {
+	return fmt.Sprintf("+%d seconds", int(RefreshTokenTTL.Seconds()))
+}
+
+func insertRefreshSession(tx *sql.Tx, userID int64, familyID string, device DeviceInfo) (int64, string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return 0, "", err
+	}
+	res, err := tx.Exec(`
+		INSERT INTO sessions (user_id, token, family_id, user_agent, ip_address, device_id, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), datetime('now'), datetime('now'), datetime('now', ?))`,
+		userID, HashToken(token), familyID, device.UserAgent, device.IPAddress, device.DeviceID, ttlModifier())
+	if err != nil {
+		return 0, "", err
+	}
+	id, err := res.LastInsertId()
+	return id, token, err
+}
+
+// CreateRefreshSession abre uma nova família de sessões para o usuário e
+// devolve a sessão criada com o refresh token em texto puro.
+func CreateRefreshSession(userID int64, device DeviceInfo) (RefreshSession, string, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	defer tx.Rollback()
+
+	id, token, err := insertRefreshSession(tx, userID, familyID, device)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return RefreshSession{}, "", err
+	}
+	return RefreshSession{ID: id, UserID: userID, FamilyID: familyID}, token, nil
+}
+
+// RotateRefreshToken troca um refresh token válido por um novo da mesma família.
+// A reutilização de um token já rotacionado revoga a família inteira.
+func RotateRefreshToken(token string, device DeviceInfo) (RefreshSession, string, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	defer tx.Rollback()
+
+	var s RefreshSession
+	var familyID, revokedReason sql.NullString
+	var revoked, expired bool
+	err = tx.QueryRow(`
+		SELECT id, user_id, family_id, revoked_at IS NOT NULL, revoked_reason,
+			expires_at IS NULL OR expires_at <= datetime('now')
+		FROM sessions WHERE token = ? AND family_id IS NOT NULL`, HashToken(token)).
+		Scan(&s.ID, &s.UserID, &familyID, &revoked, &revokedReason, &expired)
+	if err == sql.ErrNoRows {
+		return RefreshSession{}, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	s.FamilyID = familyID.String
+
+	if revoked {
+		if revokedReason.String != "rotated" {
+			return RefreshSession{}, "", ErrSessionRevoked
+		}
+		if err := revokeFamilyTx(tx, s.FamilyID, "reuse_detected"); err != nil {
+			return RefreshSession{}, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return RefreshSession{}, "", err
+		}
+		forgetFamilies(s.FamilyID)
+		return RefreshSession{}, "", ErrRefreshTokenReused
+	}
+	if expired {
+		return RefreshSession{}, "", ErrRefreshTokenExpired
+	}
+
+	newID, newToken, err := insertRefreshSession(tx, s.UserID, s.FamilyID, device)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	res, err := tx.Exec(`
+		UPDATE sessions
+		SET revoked_at = datetime('now'), revoked_reason = 'rotated', replaced_by = ?, last_used_at = datetime('now')
+		WHERE id = ? AND revoked_at IS NULL`, newID, s.ID)
+	if err != nil {
+		return RefreshSession{}, "", err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// Outra requisição rotacionou o mesmo token ao mesmo tempo.
+		return RefreshSession{}, "", ErrRefreshTokenReused
+	}
+	if err := tx.Commit(); err != nil {
+		return RefreshSession{}, "", err
+	}
+	s.ID = newID
+	return s, newToken, nil
+}
+
+// RevokeRefreshToken revoga a família de sessões do refresh token informado (logout).
+func RevokeRefreshToken(token string) error {
+	var familyID sql.NullString
+	err := config.DB.QueryRow(`SELECT family_id FROM sessions WHERE token = ? AND family_id IS NOT NULL`, HashToken(token)).Scan(&familyID)
+	if err == sql.ErrNoRows {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := revokeFamilyTx(tx, familyID.String, "logout"); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	forgetFamilies(familyID.String)
+	return nil
+}
+
+// RevokeUserSessions revoga todas as sessões ativas do usuário, exceto a família
+// informada em keepFamilyID (use "" para revogar todas). Retorna quantas
+// famílias foram revogadas.
+func RevokeUserSessions(userID int64, keepFamilyID, reason string) (int, error) {
+	rows, err := config.DB.Query(`
+		SELECT DISTINCT family_id FROM sessions
+		WHERE user_id = ? AND family_id IS NOT NULL AND family_id <> ? AND revoked_at IS NULL`,
+		userID, keepFamilyID)
+	if err != nil {
+		return 0, err
+	}
+	var families []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		families = append(families, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	_, err = config.DB.Exec(`
+		UPDATE sessions SET revoked_at = datetime('now'), revoked_reason = ?
+		WHERE user_id = ? AND family_id IS NOT NULL AND family_id <> ? AND revoked_at IS NULL`,
+		reason, userID, keepFamilyID)
+	if err != nil {
+		return 0, err
+	}
+	if len(families) > 0 {
+		forgetFamilies(families...)
+	}
+	return len(families), nil
+}
+
+func revokeFamilyTx(tx *sql.Tx, familyID, reason string) error {
+	_, err := tx.Exec(`
+		UPDATE sessions SET revoked_at = datetime('now'), revoked_reason = ?
+		WHERE family_id = ? AND revoked_at IS NULL`, reason, familyID)
+	return err
+}
@@ -0,0 +1,104 @@
+package sessions
+
+import (
+	"berry_bet/internal/testutil"
+	"errors"
+	"testing"
+)
+
+var device = DeviceInfo{UserAgent: "test", IPAddress: "127.0.0.1"}
+
+func TestRotateIssuesNewTokenInSameFamily(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	first, token, err := CreateRefreshSession(user, device)
+	if err != nil {
+		t.Fatalf("CreateRefreshSession: %v", err)
+	}
+	next, newToken, err := RotateRefreshToken(token, device)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if newToken == token || next.ID == first.ID {
+		t.Fatal("rotation did not issue a new token")
+	}
+	if next.FamilyID != first.FamilyID || next.UserID != user {
+		t.Fatalf("rotated session = %+v, want family %s of user %d", next, first.FamilyID, user)
+	}
+	if active, err := IsSessionActive(first.FamilyID, user); err != nil || !active {
+		t.Fatalf("IsSessionActive = %v, %v; want true", active, err)
+	}
+}
+
+func TestReusedRefreshTokenRevokesFamily(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	s, stolen, err := CreateRefreshSession(user, device)
+	if err != nil {
+		t.Fatalf("CreateRefreshSession: %v", err)
+	}
+	_, current, err := RotateRefreshToken(stolen, device)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	// Deixa o estado "ativo" em cache, para conferir que a revogação o limpa
+	if active, _ := IsSessionActive(s.FamilyID, user); !active {
+		t.Fatal("family should be active before the reuse")
+	}
+
+	if _, _, err := RotateRefreshToken(stolen, device); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a rotated token: error = %v, want ErrRefreshTokenReused", err)
+	}
+	// O token legítimo mais recente também deixa de valer
+	if _, _, err := RotateRefreshToken(current, device); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("rotating the latest token after reuse: error = %v, want ErrSessionRevoked", err)
+	}
+	if active, err := IsSessionActive(s.FamilyID, user); err != nil || active {
+		t.Fatalf("IsSessionActive = %v, %v; want false", active, err)
+	}
+}
+
+func TestReuseDoesNotAffectOtherFamilies(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	_, stolen, err := CreateRefreshSession(user, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, otherToken, err := CreateRefreshSession(user, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := RotateRefreshToken(stolen, device); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := RotateRefreshToken(stolen, device); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := RotateRefreshToken(otherToken, device); err != nil {
+		t.Fatalf("other family (%s) was affected: %v", other.FamilyID, err)
+	}
+}
+
+func TestRotateUnknownAndRevokedTokens(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	if _, _, err := RotateRefreshToken("nao-existe", device); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("unknown token: error = %v, want ErrInvalidRefreshToken", err)
+	}
+	_, token, err := CreateRefreshSession(user, device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RevokeRefreshToken(token); err != nil {
+		t.Fatalf("RevokeRefreshToken: %v", err)
+	}
+	// Token revogado por logout não é tratado como roubo
+	if _, _, err := RotateRefreshToken(token, device); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("logged out token: error = %v, want ErrSessionRevoked", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package testutil reúne ajudantes usados pelos testes dos pacotes internos.
+package testutil
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DB cria um banco SQLite temporário com todas as migrações aplicadas e o
+// instala em config.DB enquanto o teste roda.
+func DB(t testing.TB) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "berry_bet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Migrate(db, rootDir()); err != nil {
+		db.Close()
+		t.Fatal(err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		db.Close()
+	})
+	return db
+}
+
+// User cadastra um usuário verificado com o saldo informado e devolve o ID.
+func User(t testing.TB, username string, balance float64) int64 {
+	t.Helper()
+	res, err := config.DB.Exec(`
+		INSERT INTO users (username, name, email, password_hash, cpf, phone, date_birth, email_verified_at, kyc_status)
+		VALUES (?, ?, ?, 'x', ?, ?, '1990-01-01', datetime('now'), 'verified')`,
+		username, "Test "+username, username+"@example.com", nextDigits(11), nextDigits(11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.DB.Exec(`INSERT INTO user_stats (user_id, balance) VALUES (?, ?)`, id, balance); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// Balance devolve o saldo gravado do usuário.
+func Balance(t testing.TB, userID int64) float64 {
+	t.Helper()
+	var balance float64
+	if err := config.DB.QueryRow(`SELECT balance FROM user_stats WHERE user_id = ?`, userID).Scan(&balance); err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+var sequence int
+
+func nextDigits(n int) string {
+	sequence++
+	return fmt.Sprintf("%0*d", n, sequence)
+}
+
+func rootDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
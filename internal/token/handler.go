@@ -0,0 +1,19 @@
+package token
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public verification keys (RFC 7517) so other
+// services can validate access tokens.
+func JWKSHandler(c *gin.Context) {
+	keys, err := JWKS()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"keys": []JWK{}})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
@@ -1,27 +1,213 @@
 package token
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
+// AccessTokenTTL é a validade do access token; a sessão é mantida pelo refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
+const (
+	defaultIssuer   = "berry_bet"
+	defaultAudience = "berry_bet-api"
+)
+
+var ErrNotConfigured = errors.New("token service is not configured")
 
 type Claims struct {
-	Username string `json:"username"`
+	UserID   int64    `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(username string) (string, error) {
-	expirationTime := time.Now().Add(5 * time.Minute)
+// Config é o conjunto de chaves ativo. Signing assina os novos tokens; Keys
+// contém todas as chaves aceitas na verificação, incluindo Signing.
+type Config struct {
+	Signing  *Key
+	Keys     map[string]*Key
+	Issuer   string
+	Audience string
+}
+
+var (
+	mu     sync.RWMutex
+	active *Config
+)
+
+// Configure carrega as chaves do ambiente e precisa ser chamado na
+// inicialização; sem uma chave válida o servidor não deve subir.
+//
+//   - JWT_ALG: HS256 (padrão), RS256 ou EdDSA.
+//   - HS256: JWT_SECRET (mínimo de 32 caracteres). Segredos antigos ainda
+//     aceitos na verificação vão em JWT_PREVIOUS_SECRETS, separados por vírgula.
+//   - RS256/EdDSA: JWT_PRIVATE_KEY com o PEM ou o caminho do arquivo. Chaves
+//     públicas antigas ainda aceitas vão em JWT_VERIFY_KEYS (caminhos ou PEMs,
+//     separados por vírgula).
+//   - JWT_ISSUER e JWT_AUDIENCE definem as claims iss e aud exigidas.
+func Configure() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	active = cfg
+	mu.Unlock()
+	return nil
+}
+
+func loadConfig() (*Config, error) {
+	cfg := &Config{
+		Keys:     map[string]*Key{},
+		Issuer:   envOr("JWT_ISSUER", defaultIssuer),
+		Audience: envOr("JWT_AUDIENCE", defaultAudience),
+	}
+	var err error
+	switch alg := strings.ToUpper(envOr("JWT_ALG", "HS256")); alg {
+	case "HS256":
+		if cfg.Signing, err = newHMACKey(os.Getenv("JWT_SECRET")); err != nil {
+			return nil, fmt.Errorf("JWT_SECRET: %w", err)
+		}
+		for _, secret := range splitList(os.Getenv("JWT_PREVIOUS_SECRETS")) {
+			k, err := newHMACKey(secret)
+			if err != nil {
+				return nil, fmt.Errorf("JWT_PREVIOUS_SECRETS: %w", err)
+			}
+			cfg.Keys[k.ID] = k
+		}
+	case "RS256", "EDDSA":
+		if os.Getenv("JWT_PRIVATE_KEY") == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY is required for %s", alg)
+		}
+		if cfg.Signing, err = loadPEMKey(os.Getenv("JWT_PRIVATE_KEY")); err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY: %w", err)
+		}
+		if cfg.Signing.signing == nil {
+			return nil, errors.New("JWT_PRIVATE_KEY must be a private key")
+		}
+		if !strings.EqualFold(cfg.Signing.Method.Alg(), alg) {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY is a %s key, but JWT_ALG is %s", cfg.Signing.Method.Alg(), alg)
+		}
+		for _, value := range splitList(os.Getenv("JWT_VERIFY_KEYS")) {
+			k, err := loadPEMKey(value)
+			if err != nil {
+				return nil, fmt.Errorf("JWT_VERIFY_KEYS: %w", err)
+			}
+			k.signing = nil
+			cfg.Keys[k.ID] = k
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALG %q (use HS256, RS256 or EdDSA)", alg)
+	}
+	cfg.Keys[cfg.Signing.ID] = cfg.Signing
+	return cfg, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func current() (*Config, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if active == nil {
+		return nil, ErrNotConfigured
+	}
+	return active, nil
+}
+
+// GenerateJWT emite um access token vinculado à família de sessões (claim jti).
+func GenerateJWT(userID int64, username, sessionID string, roles []string) (string, error) {
+	cfg, err := current()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := &Claims{
+		UserID:   userID,
 		Username: username,
+		Roles:    roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        sessionID,
+			Issuer:    cfg.Issuer,
+			Audience:  jwt.ClaimStrings{cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	t := jwt.NewWithClaims(cfg.Signing.Method, claims)
+	t.Header["kid"] = cfg.Signing.ID
+	return t.SignedString(cfg.Signing.signing)
+}
+
+// ParseJWT valida assinatura, kid, algoritmo, emissor, audiência e validade.
+func ParseJWT(tokenStr string) (*Claims, error) {
+	cfg, err := current()
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, ok := cfg.Keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// O algoritmo precisa ser o da chave, e não o que o token declara.
+		if t.Method.Alg() != k.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return k.verify, nil
+	},
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWKS devolve as chaves públicas aceitas na verificação. Com HS256 a lista é
+// vazia, pois segredos simétricos não podem ser publicados.
+func JWKS() ([]JWK, error) {
+	cfg, err := current()
+	if err != nil {
+		return nil, err
+	}
+	keys := []JWK{}
+	if jwk, ok := cfg.Signing.JWK(); ok {
+		keys = append(keys, jwk)
+	}
+	for id, k := range cfg.Keys {
+		if id == cfg.Signing.ID {
+			continue
+		}
+		if jwk, ok := k.JWK(); ok {
+			keys = append(keys, jwk)
+		}
+	}
+	return keys, nil
 }
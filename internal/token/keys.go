@@ -0,0 +1,169 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minSecretLength é o tamanho mínimo aceito para segredos HS256.
+const minSecretLength = 32
+
+// Key é uma chave de assinatura ou de verificação identificada pelo kid.
+// Chaves simétricas não têm parte pública e nunca aparecem no JWKS.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	signing any // nil para chaves apenas de verificação
+	verify  any
+}
+
+// newHMACKey cria uma chave HS256. O kid é derivado do segredo, para que
+// rotacionar o segredo também troque o kid.
+func newHMACKey(secret string) (*Key, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("HS256 secret must have at least %d characters", minSecretLength)
+	}
+	sum := sha256.Sum256([]byte("kid:" + secret))
+	return &Key{
+		ID:      "hs-" + base64.RawURLEncoding.EncodeToString(sum[:9]),
+		Method:  jwt.SigningMethodHS256,
+		signing: []byte(secret),
+		verify:  []byte(secret),
+	}, nil
+}
+
+// parsePEMKey lê uma chave RSA ou Ed25519 em PEM (privada PKCS#1/PKCS#8 ou
+// pública PKIX). Chaves públicas servem apenas para verificação.
+func parsePEMKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Key{}
+	switch v := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.Method, k.signing, k.verify = jwt.SigningMethodRS256, v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.Method, k.verify = jwt.SigningMethodRS256, v
+	case ed25519.PrivateKey:
+		k.Method, k.signing, k.verify = jwt.SigningMethodEdDSA, v, v.Public()
+	case ed25519.PublicKey:
+		k.Method, k.verify = jwt.SigningMethodEdDSA, v
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if rsaKey, ok := k.verify.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must have at least 2048 bits")
+	}
+	if k.ID, err = thumbprint(k.verify); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// loadPEMKey aceita o PEM em si ou o caminho de um arquivo PEM.
+func loadPEMKey(value string) (*Key, error) {
+	value = strings.TrimSpace(value)
+	data := []byte(value)
+	if !strings.HasPrefix(value, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, err
+		}
+	}
+	return parsePEMKey(data)
+}
+
+// JWK é a representação pública de uma chave (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func publicJWK(pub any) (JWK, bool) {
+	switch v := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(v.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(v.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(v)}, true
+	}
+	return JWK{}, false
+}
+
+// thumbprint calcula o kid pela impressão digital da chave pública (RFC 7638).
+func thumbprint(pub any) (string, error) {
+	jwk, ok := publicJWK(pub)
+	if !ok {
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+	var members any
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := crypto.SHA256.New()
+	sum.Write(data)
+	return base64.RawURLEncoding.EncodeToString(sum.Sum(nil)), nil
+}
+
+// JWK devolve a chave pública no formato JWKS; false para chaves simétricas.
+func (k *Key) JWK() (JWK, bool) {
+	jwk, ok := publicJWK(k.verify)
+	if !ok {
+		return JWK{}, false
+	}
+	jwk.Kid, jwk.Use, jwk.Alg = k.ID, "sig", k.Method.Alg()
+	return jwk, true
+}
@@ -0,0 +1,114 @@
+package tournaments
+
+import "strings"
+
+type PrizeRequest struct {
+	RankFrom int     `json:"rank_from"`
+	RankTo   int     `json:"rank_to"`
+	Amount   float64 `json:"amount"`
+}
+
+type TournamentRequest struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	StartTime     string         `json:"start_time"`
+	EndTime       string         `json:"end_time"`
+	EligibleGames []string       `json:"eligible_games"`
+	Scoring       string         `json:"scoring"`
+	EntryFee      float64        `json:"entry_fee"`
+	Prizes        []PrizeRequest `json:"prizes"`
+}
+
+type TournamentResponse struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	StartTime     string   `json:"start_time"`
+	EndTime       string   `json:"end_time"`
+	EligibleGames []string `json:"eligible_games"`
+	Scoring       string   `json:"scoring"`
+	EntryFee      float64  `json:"entry_fee"`
+	Status        string   `json:"status"`
+	FinishedAt    string   `json:"finished_at,omitempty"`
+	Prizes        []Prize  `json:"prizes,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+type StandingResponse struct {
+	Rank           int     `json:"rank"`
+	UserID         int64   `json:"user_id"`
+	Username       string  `json:"username"`
+	AvatarURL      string  `json:"avatar_url"`
+	Score          float64 `json:"score"`
+	BetsCount      int64   `json:"bets_count"`
+	TotalWagered   float64 `json:"total_wagered"`
+	TotalProfit    float64 `json:"total_profit"`
+	BestMultiplier float64 `json:"best_multiplier"`
+	PrizeAmount    float64 `json:"prize_amount"`
+}
+
+type StandingsResponse struct {
+	TournamentID int64              `json:"tournament_id"`
+	Scoring      string             `json:"scoring"`
+	Status       string             `json:"status"`
+	Page         int                `json:"page"`
+	Limit        int                `json:"limit"`
+	Standings    []StandingResponse `json:"standings"`
+	Me           *StandingResponse  `json:"me,omitempty"`
+}
+
+func (r *TournamentRequest) ToTournament() (Tournament, []Prize) {
+	t := Tournament{
+		Name:          r.Name,
+		Description:   r.Description,
+		StartTime:     r.StartTime,
+		EndTime:       r.EndTime,
+		EligibleGames: strings.Join(r.EligibleGames, ","),
+		Scoring:       r.Scoring,
+		EntryFee:      r.EntryFee,
+	}
+	prizes := make([]Prize, 0, len(r.Prizes))
+	for _, p := range r.Prizes {
+		prizes = append(prizes, Prize{RankFrom: p.RankFrom, RankTo: p.RankTo, Amount: p.Amount})
+	}
+	return t, prizes
+}
+
+func ToTournamentResponse(t *Tournament, prizes []Prize) TournamentResponse {
+	finishedAt := ""
+	if t.FinishedAt != nil {
+		finishedAt = *t.FinishedAt
+	}
+	return TournamentResponse{
+		ID:            t.ID,
+		Name:          t.Name,
+		Description:   t.Description,
+		StartTime:     t.StartTime,
+		EndTime:       t.EndTime,
+		EligibleGames: strings.Split(t.EligibleGames, ","),
+		Scoring:       t.Scoring,
+		EntryFee:      t.EntryFee,
+		Status:        t.Status,
+		FinishedAt:    finishedAt,
+		Prizes:        prizes,
+		CreatedAt:     t.CreatedAt,
+	}
+}
+
+func ToStandingResponse(e *Entry, rank int) StandingResponse {
+	if e.FinalRank != nil {
+		rank = int(*e.FinalRank)
+	}
+	return StandingResponse{
+		Rank:           rank,
+		UserID:         e.UserID,
+		Username:       e.Username,
+		AvatarURL:      e.AvatarURL,
+		Score:          e.Score,
+		BetsCount:      e.BetsCount,
+		TotalWagered:   e.TotalWagered,
+		TotalProfit:    e.TotalProfit,
+		BestMultiplier: e.BestMultiplier,
+		PrizeAmount:    e.PrizeAmount,
+	}
+}
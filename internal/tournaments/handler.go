@@ -0,0 +1,228 @@
+package tournaments
+
+import (
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/utils"
+	"berry_bet/internal/wallet"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseTournamentID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// GetTournamentsHandler returns every tournament, including finished ones (DTO response).
+func GetTournamentsHandler(c *gin.Context) {
+	respondTournamentList(c, false)
+}
+
+// GetOpenTournamentsHandler returns scheduled and running tournaments (DTO response).
+func GetOpenTournamentsHandler(c *gin.Context) {
+	respondTournamentList(c, true)
+}
+
+func respondTournamentList(c *gin.Context, onlyOpen bool) {
+	list, err := GetTournaments(onlyOpen, 50)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch tournaments.", err.Error())
+		return
+	}
+	responses := make([]TournamentResponse, 0, len(list))
+	for _, t := range list {
+		responses = append(responses, ToTournamentResponse(&t, nil))
+	}
+	utils.RespondSuccess(c, responses, "Tournaments found")
+}
+
+// GetTournamentByIDHandler returns a tournament with its prize table (DTO response).
+func GetTournamentByIDHandler(c *gin.Context) {
+	id, ok := parseTournamentID(c)
+	if !ok {
+		return
+	}
+	t, err := GetTournamentByID(id)
+	if errors.Is(err, ErrNotFound) {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Tournament not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch tournament.", err.Error())
+		return
+	}
+	prizes, err := GetPrizes(id)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch prizes.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, ToTournamentResponse(&t, prizes), "Tournament found")
+}
+
+// AddTournamentHandler creates a tournament with its prize table (DTO request/response).
+func AddTournamentHandler(c *gin.Context) {
+	var req TournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	t, prizes := req.ToTournament()
+	if err := ValidateTournament(&t, prizes); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "BUSINESS_RULE", err.Error(), nil)
+		return
+	}
+	id, err := AddTournament(t, prizes)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to create tournament.", err.Error())
+		return
+	}
+	created, err := GetTournamentByID(id)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch tournament.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, ToTournamentResponse(&created, prizes), "Tournament created successfully")
+}
+
+// UpdateTournamentHandler updates a tournament that has not started yet.
+func UpdateTournamentHandler(c *gin.Context) {
+	id, ok := parseTournamentID(c)
+	if !ok {
+		return
+	}
+	var req TournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	t, prizes := req.ToTournament()
+	if err := ValidateTournament(&t, prizes); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "BUSINESS_RULE", err.Error(), nil)
+		return
+	}
+	err := UpdateTournament(t, prizes, id)
+	if errors.Is(err, ErrNotEditable) {
+		utils.RespondError(c, http.StatusConflict, "NOT_EDITABLE", err.Error(), nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update tournament.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, nil, "Tournament updated successfully")
+}
+
+// DeleteTournamentHandler cancels a tournament and refunds paid entry fees.
+func DeleteTournamentHandler(c *gin.Context) {
+	id, ok := parseTournamentID(c)
+	if !ok {
+		return
+	}
+	err := CancelTournament(id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Tournament not found.", nil)
+	case errors.Is(err, ErrNotFinalizable):
+		utils.RespondError(c, http.StatusConflict, "NOT_CANCELLABLE", err.Error(), nil)
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to cancel tournament.", err.Error())
+	default:
+		utils.RespondSuccess(c, nil, "Tournament cancelled successfully.")
+	}
+}
+
+// JoinTournamentHandler enrolls the authenticated user, charging the entry fee.
+func JoinTournamentHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseTournamentID(c)
+	if !ok {
+		return
+	}
+	if !responsible_gaming.CheckNotExcluded(c, userID) {
+		return
+	}
+	err := JoinTournament(id, userID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Tournament not found.", nil)
+	case errors.Is(err, ErrNotOpen):
+		utils.RespondError(c, http.StatusBadRequest, "TOURNAMENT_CLOSED", err.Error(), nil)
+	case errors.Is(err, ErrAlreadyJoined):
+		utils.RespondError(c, http.StatusConflict, "ALREADY_JOINED", err.Error(), nil)
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		utils.RespondError(c, http.StatusBadRequest, "INSUFFICIENT_FUNDS", "User does not have enough balance to pay the entry fee.", nil)
+	case err != nil:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to join tournament.", err.Error())
+	default:
+		utils.RespondSuccess(c, nil, "Joined tournament successfully.")
+	}
+}
+
+// GetStandingsHandler returns the live standings page plus the caller's own position.
+func GetStandingsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseTournamentID(c)
+	if !ok {
+		return
+	}
+	t, err := GetTournamentByID(id)
+	if errors.Is(err, ErrNotFound) {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Tournament not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch tournament.", err.Error())
+		return
+	}
+
+	page := 1
+	limit := 20
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	offset := (page - 1) * limit
+
+	entries, err := GetStandings(id, limit, offset)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch standings.", err.Error())
+		return
+	}
+	resp := StandingsResponse{
+		TournamentID: t.ID,
+		Scoring:      t.Scoring,
+		Status:       t.Status,
+		Page:         page,
+		Limit:        limit,
+		Standings:    make([]StandingResponse, 0, len(entries)),
+	}
+	for i, e := range entries {
+		resp.Standings = append(resp.Standings, ToStandingResponse(&e, offset+i+1))
+	}
+
+	me, rank, err := GetUserPosition(id, userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user position.", err.Error())
+		return
+	}
+	if me != nil {
+		standing := ToStandingResponse(me, rank)
+		resp.Me = &standing
+	}
+	utils.RespondSuccess(c, resp, "Standings found")
+}
@@ -0,0 +1,526 @@
+package tournaments
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/wallet"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrNotFound        = errors.New("tournament not found")
+	ErrNotOpen         = errors.New("tournament is not open for entries")
+	ErrAlreadyJoined   = errors.New("user already joined this tournament")
+	ErrNotEditable     = errors.New("only scheduled tournaments can be edited")
+	ErrNotFinalizable  = errors.New("tournament already finished or cancelled")
+	errInvalidScoring  = errors.New("scoring must be total_profit, biggest_multiplier or wager_volume")
+	errInvalidSchedule = errors.New("end_time must be after start_time")
+)
+
+type Tournament struct {
+	ID            int64   `json:"id"`
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	StartTime     string  `json:"start_time"`
+	EndTime       string  `json:"end_time"`
+	EligibleGames string  `json:"eligible_games"`
+	Scoring       string  `json:"scoring"`
+	EntryFee      float64 `json:"entry_fee"`
+	Status        string  `json:"status"`
+	FinishedAt    *string `json:"finished_at"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type Prize struct {
+	RankFrom int     `json:"rank_from"`
+	RankTo   int     `json:"rank_to"`
+	Amount   float64 `json:"amount"`
+}
+
+type Entry struct {
+	TournamentID   int64   `json:"tournament_id"`
+	UserID         int64   `json:"user_id"`
+	Username       string  `json:"username"`
+	AvatarURL      string  `json:"avatar_url"`
+	Score          float64 `json:"score"`
+	BetsCount      int64   `json:"bets_count"`
+	TotalWagered   float64 `json:"total_wagered"`
+	TotalProfit    float64 `json:"total_profit"`
+	BestMultiplier float64 `json:"best_multiplier"`
+	FinalRank      *int64  `json:"final_rank"`
+	PrizeAmount    float64 `json:"prize_amount"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+const tournamentColumns = "id, name, COALESCE(description, ''), start_time, end_time, eligible_games, scoring, entry_fee, status, finished_at, created_at"
+
+func scanTournament(row interface{ Scan(dest ...any) error }) (Tournament, error) {
+	var t Tournament
+	var finished sql.NullString
+	err := row.Scan(&t.ID, &t.Name, &t.Description, &t.StartTime, &t.EndTime, &t.EligibleGames, &t.Scoring, &t.EntryFee, &t.Status, &finished, &t.CreatedAt)
+	if finished.Valid {
+		t.FinishedAt = &finished.String
+	}
+	return t, err
+}
+
+func scanEntry(row interface{ Scan(dest ...any) error }) (Entry, error) {
+	var e Entry
+	var rank sql.NullInt64
+	err := row.Scan(&e.TournamentID, &e.UserID, &e.Username, &e.AvatarURL, &e.Score, &e.BetsCount,
+		&e.TotalWagered, &e.TotalProfit, &e.BestMultiplier, &rank, &e.PrizeAmount, &e.UpdatedAt)
+	if rank.Valid {
+		e.FinalRank = &rank.Int64
+	}
+	return e, err
+}
+
+// GetTournaments busca torneios, opcionalmente filtrando apenas os abertos (agendados ou em andamento).
+func GetTournaments(onlyOpen bool, count int) ([]Tournament, error) {
+	query := "SELECT " + tournamentColumns + " FROM tournaments"
+	if onlyOpen {
+		query += " WHERE status IN ('scheduled', 'running') AND end_time > datetime('now')"
+	}
+	query += " ORDER BY start_time DESC LIMIT ?"
+
+	rows, err := config.DB.Query(query, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Tournament, 0)
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// GetTournamentByID busca um torneio pelo ID. Retorna ErrNotFound se não existir.
+func GetTournamentByID(id int64) (Tournament, error) {
+	row := config.DB.QueryRow("SELECT "+tournamentColumns+" FROM tournaments WHERE id = ?", id)
+	t, err := scanTournament(row)
+	if err == sql.ErrNoRows {
+		return Tournament{}, ErrNotFound
+	}
+	return t, err
+}
+
+// GetPrizes retorna a tabela de prêmios de um torneio, ordenada por colocação.
+func GetPrizes(tournamentID int64) ([]Prize, error) {
+	rows, err := config.DB.Query(`
+		SELECT rank_from, rank_to, amount
+		FROM tournament_prizes
+		WHERE tournament_id = ?
+		ORDER BY rank_from ASC`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prizes := make([]Prize, 0)
+	for rows.Next() {
+		var p Prize
+		if err := rows.Scan(&p.RankFrom, &p.RankTo, &p.Amount); err != nil {
+			return nil, err
+		}
+		prizes = append(prizes, p)
+	}
+	return prizes, rows.Err()
+}
+
+// AddTournament cria um torneio e sua tabela de prêmios em uma única transação.
+func AddTournament(t Tournament, prizes []Prize) (int64, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO tournaments (name, description, start_time, end_time, eligible_games, scoring, entry_fee, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'scheduled', datetime('now'))`,
+		t.Name, t.Description, t.StartTime, t.EndTime, t.EligibleGames, t.Scoring, t.EntryFee)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := insertPrizesTx(tx, id, prizes); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UpdateTournament atualiza um torneio que ainda não começou, substituindo a tabela de prêmios.
+func UpdateTournament(t Tournament, prizes []Prize, id int64) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE tournaments
+		SET name = ?, description = ?, start_time = ?, end_time = ?, eligible_games = ?, scoring = ?, entry_fee = ?
+		WHERE id = ? AND status = 'scheduled' AND start_time > datetime('now')`,
+		t.Name, t.Description, t.StartTime, t.EndTime, t.EligibleGames, t.Scoring, t.EntryFee, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotEditable
+	}
+	if _, err := tx.Exec(`DELETE FROM tournament_prizes WHERE tournament_id = ?`, id); err != nil {
+		return err
+	}
+	if err := insertPrizesTx(tx, id, prizes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertPrizesTx(tx *sql.Tx, tournamentID int64, prizes []Prize) error {
+	for _, p := range prizes {
+		_, err := tx.Exec(`INSERT INTO tournament_prizes (tournament_id, rank_from, rank_to, amount) VALUES (?, ?, ?, ?)`,
+			tournamentID, p.RankFrom, p.RankTo, p.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelTournament cancela um torneio ainda não finalizado e devolve as taxas de inscrição pagas.
+func CancelTournament(id int64) error {
+	t, err := GetTournamentByID(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE tournaments SET status = 'cancelled', finished_at = datetime('now') WHERE id = ? AND status IN ('scheduled', 'running')`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFinalizable
+	}
+
+	if t.EntryFee > 0 {
+		userIDs, err := entryUserIDsTx(tx, id)
+		if err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			desc := fmt.Sprintf("Reembolso da inscrição - torneio %s", t.Name)
+			if _, err := wallet.Credit(tx, userID, t.EntryFee, "tournament_refund", desc); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func entryUserIDsTx(tx *sql.Tx, tournamentID int64) ([]int64, error) {
+	rows, err := tx.Query(`SELECT user_id FROM tournament_entries WHERE tournament_id = ?`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// JoinTournament inscreve o usuário no torneio, debitando a taxa de inscrição pela carteira.
+func JoinTournament(id, userID int64) error {
+	t, err := GetTournamentByID(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var open bool
+	err = tx.QueryRow(`SELECT status IN ('scheduled', 'running') AND end_time > datetime('now') FROM tournaments WHERE id = ?`, id).Scan(&open)
+	if err != nil {
+		return err
+	}
+	if !open {
+		return ErrNotOpen
+	}
+
+	res, err := tx.Exec(`
+		INSERT OR IGNORE INTO tournament_entries (tournament_id, user_id, joined_at, updated_at)
+		VALUES (?, ?, datetime('now'), datetime('now'))`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlreadyJoined
+	}
+
+	if t.EntryFee > 0 {
+		desc := fmt.Sprintf("Inscrição no torneio %s", t.Name)
+		if _, err := wallet.Debit(tx, userID, t.EntryFee, "tournament_fee", desc); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RecordPlay contabiliza uma aposta liquidada em todos os torneios em andamento
+// que aceitam o jogo. Torneios gratuitos inscrevem o jogador automaticamente.
+func RecordPlay(userID int64, gameType string, betAmount, winAmount float64) error {
+	if betAmount <= 0 {
+		return nil
+	}
+	rows, err := config.DB.Query(`
+		SELECT id, eligible_games, scoring, entry_fee
+		FROM tournaments
+		WHERE status IN ('scheduled', 'running')
+		AND start_time <= datetime('now') AND end_time > datetime('now')`)
+	if err != nil {
+		return err
+	}
+	type running struct {
+		id       int64
+		scoring  string
+		entryFee float64
+	}
+	var active []running
+	for rows.Next() {
+		var r running
+		var games string
+		if err := rows.Scan(&r.id, &games, &r.scoring, &r.entryFee); err != nil {
+			rows.Close()
+			return err
+		}
+		if acceptsGame(games, gameType) {
+			active = append(active, r)
+		}
+	}
+	rows.Close()
+	if len(active) == 0 {
+		return nil
+	}
+
+	profit := winAmount - betAmount
+	multiplier := winAmount / betAmount
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, r := range active {
+		if r.entryFee == 0 {
+			_, err = tx.Exec(`
+				INSERT OR IGNORE INTO tournament_entries (tournament_id, user_id, joined_at, updated_at)
+				VALUES (?, ?, datetime('now'), datetime('now'))`, r.id, userID)
+			if err != nil {
+				return err
+			}
+		}
+		res, err := tx.Exec(`
+			UPDATE tournament_entries SET
+				bets_count = bets_count + 1,
+				total_wagered = total_wagered + ?,
+				total_profit = total_profit + ?,
+				best_multiplier = MAX(best_multiplier, ?),
+				updated_at = datetime('now')
+			WHERE tournament_id = ? AND user_id = ?`,
+			betAmount, profit, multiplier, r.id, userID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		_, err = tx.Exec(`UPDATE tournament_entries SET score = `+scoreColumn(r.scoring)+` WHERE tournament_id = ? AND user_id = ?`, r.id, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetStandings retorna a classificação atual do torneio, paginada.
+func GetStandings(tournamentID int64, limit, offset int) ([]Entry, error) {
+	rows, err := config.DB.Query(`
+		SELECT e.tournament_id, e.user_id, u.username, COALESCE(u.avatar_url, ''), e.score, e.bets_count,
+			e.total_wagered, e.total_profit, e.best_multiplier, e.final_rank, e.prize_amount, e.updated_at
+		FROM tournament_entries e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.tournament_id = ?
+		ORDER BY e.score DESC, e.updated_at ASC, e.user_id ASC
+		LIMIT ? OFFSET ?`, tournamentID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]Entry, 0)
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetUserPosition retorna a inscrição do usuário e sua posição atual na classificação.
+func GetUserPosition(tournamentID, userID int64) (*Entry, int, error) {
+	e, err := scanEntry(config.DB.QueryRow(`
+		SELECT e.tournament_id, e.user_id, u.username, COALESCE(u.avatar_url, ''), e.score, e.bets_count,
+			e.total_wagered, e.total_profit, e.best_multiplier, e.final_rank, e.prize_amount, e.updated_at
+		FROM tournament_entries e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.tournament_id = ? AND e.user_id = ?`, tournamentID, userID))
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ahead int
+	err = config.DB.QueryRow(`
+		SELECT COUNT(*)
+		FROM tournament_entries o
+		JOIN tournament_entries me ON me.tournament_id = o.tournament_id
+		WHERE me.tournament_id = ? AND me.user_id = ?
+		AND (o.score > me.score OR (o.score = me.score AND (o.updated_at < me.updated_at
+			OR (o.updated_at = me.updated_at AND o.user_id < me.user_id))))`,
+		tournamentID, userID).Scan(&ahead)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &e, ahead + 1, nil
+}
+
+// FinalizeTournament encerra o torneio, grava a colocação final e paga os prêmios pela carteira.
+// A troca de status é condicional, garantindo que os prêmios sejam pagos uma única vez.
+func FinalizeTournament(id int64) error {
+	t, err := GetTournamentByID(id)
+	if err != nil {
+		return err
+	}
+	prizes, err := GetPrizes(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE tournaments SET status = 'finished', finished_at = datetime('now') WHERE id = ? AND status IN ('scheduled', 'running')`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFinalizable
+	}
+
+	// Apenas quem jogou ao menos uma vez concorre aos prêmios
+	rows, err := tx.Query(`
+		SELECT user_id FROM tournament_entries
+		WHERE tournament_id = ? AND bets_count > 0
+		ORDER BY score DESC, updated_at ASC, user_id ASC`, id)
+	if err != nil {
+		return err
+	}
+	var ranking []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return err
+		}
+		ranking = append(ranking, userID)
+	}
+	rows.Close()
+
+	for i, userID := range ranking {
+		rank := i + 1
+		amount := prizeForRank(prizes, rank)
+		_, err = tx.Exec(`UPDATE tournament_entries SET final_rank = ?, prize_amount = ? WHERE tournament_id = ? AND user_id = ?`,
+			rank, amount, id, userID)
+		if err != nil {
+			return err
+		}
+		if amount > 0 {
+			desc := fmt.Sprintf("Prêmio do torneio %s - %dº lugar", t.Name, rank)
+			if _, err := wallet.Credit(tx, userID, amount, "tournament_prize", desc); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// FinalizeDueTournaments marca como em andamento os torneios que começaram e
+// finaliza os que já terminaram. Retorna quantos torneios foram finalizados.
+func FinalizeDueTournaments() (int, error) {
+	_, err := config.DB.Exec(`
+		UPDATE tournaments SET status = 'running'
+		WHERE status = 'scheduled' AND start_time <= datetime('now') AND end_time > datetime('now')`)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := config.DB.Query(`SELECT id FROM tournaments WHERE status IN ('scheduled', 'running') AND end_time <= datetime('now')`)
+	if err != nil {
+		return 0, err
+	}
+	var due []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, id)
+	}
+	rows.Close()
+
+	finalized := 0
+	for _, id := range due {
+		if err := FinalizeTournament(id); err != nil {
+			log.Printf("Erro ao finalizar torneio %d: %v", id, err)
+			continue
+		}
+		finalized++
+	}
+	return finalized, nil
+}
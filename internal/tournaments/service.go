@@ -0,0 +1,124 @@
+package tournaments
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const dbTimeLayout = "2006-01-02 15:04:05"
+
+// ValidateTournament valida os dados do torneio e normaliza horários e jogos elegíveis.
+func ValidateTournament(t *Tournament, prizes []Prize) error {
+	if len(t.Name) < 3 {
+		return errors.New("tournament name must have at least 3 characters")
+	}
+	switch t.Scoring {
+	case "":
+		t.Scoring = "total_profit"
+	case "total_profit", "biggest_multiplier", "wager_volume":
+	default:
+		return errInvalidScoring
+	}
+	if t.EntryFee < 0 {
+		return errors.New("entry fee cannot be negative")
+	}
+
+	start, err := parseTime(t.StartTime)
+	if err != nil {
+		return errors.New("invalid start_time")
+	}
+	end, err := parseTime(t.EndTime)
+	if err != nil {
+		return errors.New("invalid end_time")
+	}
+	if !end.After(start) {
+		return errInvalidSchedule
+	}
+	t.StartTime = start.UTC().Format(dbTimeLayout)
+	t.EndTime = end.UTC().Format(dbTimeLayout)
+
+	t.EligibleGames = normalizeGames(t.EligibleGames)
+	if t.EligibleGames == "" {
+		return errors.New("at least one eligible game is required")
+	}
+
+	lastRank := 0
+	for _, p := range prizes {
+		if p.RankFrom <= lastRank || p.RankTo < p.RankFrom {
+			return errors.New("prize ranks must be ascending and must not overlap")
+		}
+		if p.Amount <= 0 {
+			return errors.New("prize amount must be greater than zero")
+		}
+		lastRank = p.RankTo
+	}
+	return nil
+}
+
+// parseTime aceita "2006-01-02 15:04:05" (UTC) ou RFC3339.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(dbTimeLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func normalizeGames(games string) string {
+	var list []string
+	for _, g := range strings.Split(games, ",") {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if g != "" {
+			list = append(list, g)
+		}
+	}
+	return strings.Join(list, ",")
+}
+
+func acceptsGame(eligibleGames, gameType string) bool {
+	for _, g := range strings.Split(eligibleGames, ",") {
+		if g == gameType {
+			return true
+		}
+	}
+	return false
+}
+
+// scoreColumn devolve a coluna de tournament_entries usada como pontuação.
+func scoreColumn(scoring string) string {
+	switch scoring {
+	case "biggest_multiplier":
+		return "best_multiplier"
+	case "wager_volume":
+		return "total_wagered"
+	}
+	return "total_profit"
+}
+
+func prizeForRank(prizes []Prize, rank int) float64 {
+	for _, p := range prizes {
+		if rank >= p.RankFrom && rank <= p.RankTo {
+			return p.Amount
+		}
+	}
+	return 0
+}
+
+// StartWorker executa periodicamente a finalização dos torneios encerrados.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := FinalizeDueTournaments()
+			if err != nil {
+				log.Printf("Erro ao processar torneios: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d torneio(s) finalizado(s) e prêmios distribuídos", n)
+			}
+		}
+	}()
+}
@@ -1,8 +1,11 @@
 package transactions
 
 import (
+	"berry_bet/internal/audit"
 	"berry_bet/internal/common"
+	"berry_bet/internal/responsible_gaming"
 	"berry_bet/internal/utils"
+	"database/sql"
 	"net/http"
 	"strconv"
 
@@ -52,9 +55,18 @@ func AddTransactionHandler(c *gin.Context) {
 	if req.Type == "deposit" {
 		err := CreateDepositTransaction(req.UserID, req.Amount, req.Description)
 		if err != nil {
+			if responsible_gaming.RespondPlayError(c, err) {
+				return
+			}
 			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to register deposit.", err.Error())
 			return
 		}
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "transaction.create",
+			TargetType: "user",
+			TargetID:   req.UserID,
+			After:      req,
+		})
 		utils.RespondSuccess(c, nil, "Deposit registered successfully")
 		return
 	}
@@ -70,6 +82,12 @@ func AddTransactionHandler(c *gin.Context) {
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "transaction.create",
+			TargetType: "user",
+			TargetID:   req.UserID,
+			After:      req,
+		})
 		utils.RespondSuccess(c, nil, "Transaction registered successfully")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "INSERT_FAIL", "Could not register transaction.", nil)
@@ -95,12 +113,28 @@ func UpdateTransactionHandler(c *gin.Context) {
 		Amount:      req.Amount,
 		Description: req.Description,
 	}
+	previous, err := GetTransactionByID(c.Param("id"))
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Transaction not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch transaction.", err.Error())
+		return
+	}
 	success, err := UpdateTransaction(transaction, int64(transactionId))
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update transaction.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "transaction.update",
+			TargetType: "transaction",
+			TargetID:   transaction.ID,
+			Before:     ToTransactionResponse(&previous),
+			After:      req,
+		})
 		utils.RespondSuccess(c, nil, "Transaction updated successfully")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "UPDATE_FAIL", "Could not update transaction.", nil)
@@ -114,12 +148,27 @@ func DeleteTransactionHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
 		return
 	}
+	previous, err := GetTransactionByID(c.Param("id"))
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Transaction not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch transaction.", err.Error())
+		return
+	}
 	success, err := DeleteTransaction(transactionId)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DELETE_FAIL", "Could not delete transaction.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "transaction.delete",
+			TargetType: "transaction",
+			TargetID:   int64(transactionId),
+			Before:     ToTransactionResponse(&previous),
+		})
 		utils.RespondSuccess(c, nil, "Transaction deleted successfully.")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "DELETE_FAIL", "Could not delete transaction.", nil)
@@ -2,6 +2,8 @@ package transactions
 
 import (
 	"berry_bet/config"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/wallet"
 	"errors"
 )
 
@@ -263,50 +265,16 @@ func CreateDepositTransaction(userID int64, amount float64, description string)
 		}
 	}()
 
-	_, err = tx.Exec(`
-		INSERT INTO transactions (user_id, type, amount, description, created_at) 
-		VALUES (?, 'deposit', ?, ?, datetime('now'))`, userID, amount, description)
-	if err != nil {
+	// Limites de depósito definidos pelo jogador (jogo responsável)
+	if err = responsible_gaming.CheckDepositTx(tx, userID, amount); err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// Atualiza o saldo do usuário em user_stats
-	var currentBalance float64
-	err = tx.QueryRow(`SELECT balance FROM user_stats WHERE user_id = ?`, userID).Scan(&currentBalance)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-	newBalance := currentBalance + amount
-	_, err = tx.Exec(`UPDATE user_stats SET balance = ?, updated_at = datetime('now') WHERE user_id = ?`, newBalance, userID)
-	if err != nil {
+	if _, err = wallet.Credit(tx, userID, amount, "deposit", description); err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	return tx.Commit()
 }
-
-// CreateWithdrawTransaction cria uma transação de saque
-func CreateWithdrawTransaction(userID int64, amount float64, description string) error {
-	tx, err := config.DB.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	_, err = tx.Exec(`
-		INSERT INTO transactions (user_id, type, amount, description, created_at) 
-		VALUES (?, 'withdraw', ?, ?, datetime('now'))`, userID, amount, description)
-
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
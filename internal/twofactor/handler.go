@@ -0,0 +1,129 @@
+package twofactor
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/utils"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondTwoFactorError maps two-factor errors to API responses.
+func respondTwoFactorError(c *gin.Context, err error, fallback string) {
+	var locked *LockedError
+	switch {
+	case errors.As(err, &locked):
+		retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		utils.RespondError(c, http.StatusTooManyRequests, "TOTP_LOCKED", err.Error(), gin.H{"retry_after": retryAfter})
+	case errors.Is(err, ErrInvalidCode):
+		utils.RespondError(c, http.StatusUnauthorized, "INVALID_TOTP", err.Error(), nil)
+	case errors.Is(err, ErrNotEnabled), errors.Is(err, ErrAlreadyEnabled), errors.Is(err, ErrNoPendingEnrollment):
+		utils.RespondError(c, http.StatusConflict, "INVALID_STATUS", err.Error(), nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", fallback, err.Error())
+	}
+}
+
+func bindCode(c *gin.Context) (string, bool) {
+	var req CodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "code is required.", nil)
+		return "", false
+	}
+	return req.Code, true
+}
+
+// GetStatusHandler tells whether the authenticated user has 2FA enabled.
+func GetStatusHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	enabled, err := IsEnabled(userID)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to fetch two-factor status.")
+		return
+	}
+	remaining := 0
+	if enabled {
+		if remaining, err = CountRecoveryCodes(userID); err != nil {
+			respondTwoFactorError(c, err, "Failed to fetch two-factor status.")
+			return
+		}
+	}
+	utils.RespondSuccess(c, StatusResponse{Enabled: enabled, RecoveryCodesRemaining: remaining}, "Two-factor status found")
+}
+
+// EnrollHandler starts 2FA enrollment and returns the secret and otpauth URI.
+func EnrollHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	secret, uri, err := BeginEnrollment(userID, c.GetString("username"))
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to start two-factor enrollment.")
+		return
+	}
+	utils.RespondSuccess(c, EnrollmentResponse{Secret: secret, OTPAuthURI: uri}, "Scan the URI with an authenticator app and confirm with a code.")
+}
+
+// ConfirmEnrollmentHandler enables 2FA after checking the first code and returns the recovery codes.
+func ConfirmEnrollmentHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	codes, err := ConfirmEnrollment(userID, code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to enable two-factor authentication.")
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{Action: "user.2fa_enable", TargetType: "user", TargetID: userID})
+	utils.RespondSuccess(c, RecoveryCodesResponse{RecoveryCodes: codes}, "Two-factor authentication enabled.")
+}
+
+// DisableHandler turns 2FA off after checking a TOTP or recovery code.
+func DisableHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	if err := Disable(userID, code); err != nil {
+		respondTwoFactorError(c, err, "Failed to disable two-factor authentication.")
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{Action: "user.2fa_disable", TargetType: "user", TargetID: userID})
+	utils.RespondSuccess(c, nil, "Two-factor authentication disabled.")
+}
+
+// RegenerateRecoveryCodesHandler replaces the recovery codes after checking a code.
+func RegenerateRecoveryCodesHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+	codes, err := RegenerateRecoveryCodes(userID, code)
+	if err != nil {
+		respondTwoFactorError(c, err, "Failed to regenerate recovery codes.")
+		return
+	}
+	audit.RecordRequest(c, audit.Entry{Action: "user.2fa_recovery_codes", TargetType: "user", TargetID: userID})
+	utils.RespondSuccess(c, RecoveryCodesResponse{RecoveryCodes: codes}, "Recovery codes regenerated.")
+}
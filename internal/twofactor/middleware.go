@@ -0,0 +1,53 @@
+package twofactor
+
+import (
+	"berry_bet/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CodeHeader carrega o código TOTP (ou de recuperação) exigido em ações sensíveis.
+const CodeHeader = "X-TOTP-Code"
+
+// CheckFreshCode exige, de usuários com 2FA ativo, um código válido enviado no
+// header X-TOTP-Code da própria requisição. Códigos inválidos contam para o
+// bloqueio de VerifyFresh. Escreve a resposta de erro e devolve false quando a
+// ação deve ser recusada.
+func CheckFreshCode(c *gin.Context, userID int64) bool {
+	enabled, err := IsEnabled(userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch two-factor status.", err.Error())
+		return false
+	}
+	if !enabled {
+		return true
+	}
+	code := c.GetHeader(CodeHeader)
+	if code == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "TOTP_REQUIRED", "This action requires a two-factor code in the "+CodeHeader+" header.", nil)
+		return false
+	}
+	if err := VerifyFresh(userID, code); err != nil {
+		respondTwoFactorError(c, err, "Failed to verify two-factor code.")
+		return false
+	}
+	return true
+}
+
+// RequireFreshCode é o middleware equivalente a CheckFreshCode. Deve vir depois
+// do middleware JWT.
+func RequireFreshCode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := utils.CurrentUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if !CheckFreshCode(c, userID) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
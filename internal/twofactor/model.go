@@ -0,0 +1,179 @@
+package twofactor
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"time"
+)
+
+// TOTP é o cadastro de 2FA de um usuário.
+type TOTP struct {
+	UserID       int64
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+}
+
+func getTOTP(userID int64) (TOTP, error) {
+	t := TOTP{UserID: userID}
+	err := config.DB.QueryRow(`SELECT secret, enabled_at IS NOT NULL, last_used_step FROM user_totp WHERE user_id = ?`, userID).
+		Scan(&t.Secret, &t.Enabled, &t.LastUsedStep)
+	return t, err
+}
+
+// savePendingSecret grava (ou substitui) um segredo ainda não confirmado.
+func savePendingSecret(userID int64, secret string) error {
+	_, err := config.DB.Exec(`
+		INSERT INTO user_totp (user_id, secret, enabled_at, last_used_step, created_at)
+		VALUES (?, ?, NULL, 0, datetime('now'))
+		ON CONFLICT(user_id) DO UPDATE SET secret = excluded.secret, enabled_at = NULL, last_used_step = 0, created_at = excluded.created_at
+		WHERE user_totp.enabled_at IS NULL`, userID, secret)
+	return err
+}
+
+// useStep registra o intervalo aceito; devolve false se ele (ou um posterior)
+// já tinha sido usado, o que indica reuso do código.
+func useStep(userID, step int64) (bool, error) {
+	res, err := config.DB.Exec(`UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// codeLockRemaining devolve quanto falta para o fim do bloqueio de códigos do
+// usuário (zero se não houver bloqueio).
+func codeLockRemaining(userID int64) (time.Duration, error) {
+	var seconds int64
+	err := config.DB.QueryRow(`
+		SELECT COALESCE(MAX(0, CAST(strftime('%s', code_locked_until) AS INTEGER) - CAST(strftime('%s', 'now') AS INTEGER)), 0)
+		FROM user_totp WHERE user_id = ?`, userID).Scan(&seconds)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return time.Duration(seconds) * time.Second, err
+}
+
+// countFailedCode soma um código inválido. Ao chegar em maxAttempts o contador
+// zera e os códigos ficam bloqueados por lockout; devolve true nesse caso.
+func countFailedCode(userID int64, maxAttempts int, lockout time.Duration) (bool, error) {
+	var locked bool
+	err := config.DB.QueryRow(`
+		UPDATE user_totp SET
+			failed_code_attempts = CASE WHEN failed_code_attempts + 1 >= ? THEN 0 ELSE failed_code_attempts + 1 END,
+			code_locked_until = CASE WHEN failed_code_attempts + 1 >= ? THEN datetime('now', '+' || ? || ' seconds') ELSE code_locked_until END
+		WHERE user_id = ?
+		RETURNING failed_code_attempts = 0`, maxAttempts, maxAttempts, int(lockout.Seconds()), userID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return locked, err
+}
+
+// resetFailedCodes zera os códigos inválidos após um código aceito.
+func resetFailedCodes(userID int64) error {
+	_, err := config.DB.Exec(`UPDATE user_totp SET failed_code_attempts = 0, code_locked_until = NULL WHERE user_id = ?`, userID)
+	return err
+}
+
+func enableTx(tx *sql.Tx, userID int64) error {
+	_, err := tx.Exec(`UPDATE user_totp SET enabled_at = datetime('now') WHERE user_id = ?`, userID)
+	return err
+}
+
+func deleteTOTP(userID int64) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM two_factor_challenges WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceRecoveryCodesTx descarta os códigos de recuperação atuais e grava os novos hashes.
+func replaceRecoveryCodesTx(tx *sql.Tx, userID int64, hashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.Exec(`INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, datetime('now'))`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// useRecoveryCode marca o código como usado; devolve false se ele não existe ou já foi usado.
+func useRecoveryCode(userID int64, hash string) (bool, error) {
+	res, err := config.DB.Exec(`
+		UPDATE user_recovery_codes SET used_at = datetime('now')
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`, userID, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CountRecoveryCodes retorna quantos códigos de recuperação ainda podem ser usados.
+func CountRecoveryCodes(userID int64) (int, error) {
+	var count int
+	err := config.DB.QueryRow(`SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// challenge é um desafio de login pendente.
+type challenge struct {
+	UserID   int64
+	Attempts int
+	Expired  bool
+	Used     bool
+}
+
+func insertChallenge(hash string, userID int64, ttlSeconds int) error {
+	_, err := config.DB.Exec(`
+		INSERT INTO two_factor_challenges (token_hash, user_id, expires_at, created_at)
+		VALUES (?, ?, datetime('now', '+' || ? || ' seconds'), datetime('now'))`, hash, userID, ttlSeconds)
+	return err
+}
+
+func getChallenge(hash string) (challenge, error) {
+	var ch challenge
+	err := config.DB.QueryRow(`
+		SELECT user_id, attempts, expires_at <= datetime('now'), used_at IS NOT NULL
+		FROM two_factor_challenges WHERE token_hash = ?`, hash).
+		Scan(&ch.UserID, &ch.Attempts, &ch.Expired, &ch.Used)
+	return ch, err
+}
+
+// countAttempt soma uma tentativa ao desafio; devolve false se o limite já foi atingido.
+func countAttempt(hash string, maxAttempts int) (bool, error) {
+	res, err := config.DB.Exec(`
+		UPDATE two_factor_challenges SET attempts = attempts + 1
+		WHERE token_hash = ? AND used_at IS NULL AND attempts < ?`, hash, maxAttempts)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// markChallengeUsed consome o desafio; devolve false se ele já tinha sido usado.
+func markChallengeUsed(hash string) (bool, error) {
+	res, err := config.DB.Exec(`UPDATE two_factor_challenges SET used_at = datetime('now') WHERE token_hash = ? AND used_at IS NULL`, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,304 @@
+package twofactor
+
+import (
+	"berry_bet/config"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// ChallengeTTL é a validade do desafio emitido pelo login.
+const ChallengeTTL = 5 * time.Minute
+
+// CodeLockout é por quanto tempo os códigos de ações sensíveis são recusados
+// depois de maxCodeAttempts códigos inválidos seguidos.
+const CodeLockout = 15 * time.Minute
+
+const (
+	recoveryCodeCount     = 10
+	maxChallengeAttempts  = 5
+	maxCodeAttempts       = 5
+	recoveryCodeAlphabet  = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeGroupSize = 5
+)
+
+var (
+	ErrNotEnabled          = errors.New("two-factor authentication is not enabled")
+	ErrAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrNoPendingEnrollment = errors.New("no pending two-factor enrollment, start a new one")
+	ErrInvalidCode         = errors.New("invalid two-factor code")
+	ErrInvalidChallenge    = errors.New("invalid login challenge")
+	ErrChallengeExpired    = errors.New("login challenge expired, please log in again")
+	ErrTooManyAttempts     = errors.New("too many invalid two-factor codes, try again later")
+)
+
+// LockedError indica que os códigos do usuário estão bloqueados por excesso de
+// tentativas inválidas.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string { return ErrTooManyAttempts.Error() }
+
+func (e *LockedError) Unwrap() error { return ErrTooManyAttempts }
+
+// Issuer é o nome exibido no app autenticador (TOTP_ISSUER, padrão "Berry Bet").
+func Issuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Berry Bet"
+}
+
+func hashValue(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeRecoveryCode ignora maiúsculas, espaços e hífens digitados pelo usuário.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, 2*recoveryCodeGroupSize)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == recoveryCodeGroupSize {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+		codes = append(codes, sb.String())
+		hashes = append(hashes, hashValue(normalizeRecoveryCode(sb.String())))
+	}
+	return codes, hashes, nil
+}
+
+// IsEnabled indica se o usuário tem 2FA ativo.
+func IsEnabled(userID int64) (bool, error) {
+	t, err := getTOTP(userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return t.Enabled, err
+}
+
+// BeginEnrollment gera um novo segredo pendente e devolve o segredo e a URI
+// otpauth:// para o app autenticador.
+func BeginEnrollment(userID int64, account string) (string, string, error) {
+	enabled, err := IsEnabled(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled {
+		return "", "", ErrAlreadyEnabled
+	}
+	secret, err := GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := savePendingSecret(userID, secret); err != nil {
+		return "", "", err
+	}
+	return secret, OTPAuthURI(Issuer(), account, secret), nil
+}
+
+// ConfirmEnrollment ativa o 2FA após validar o primeiro código e devolve os
+// códigos de recuperação, exibidos uma única vez.
+func ConfirmEnrollment(userID int64, code string) ([]string, error) {
+	t, err := getTOTP(userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoPendingEnrollment
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+	step := matchStep(t.Secret, strings.TrimSpace(code), time.Now())
+	if step == 0 {
+		return nil, ErrInvalidCode
+	}
+	if ok, err := useStep(userID, step); err != nil || !ok {
+		return nil, firstErr(err, ErrInvalidCode)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := enableTx(tx, userID); err != nil {
+		return nil, err
+	}
+	if err := replaceRecoveryCodesTx(tx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, tx.Commit()
+}
+
+func firstErr(err, fallback error) error {
+	if err != nil {
+		return err
+	}
+	return fallback
+}
+
+// Verify confere um código TOTP (que não pode ser reutilizado) ou um código de
+// recuperação (consumido ao ser aceito).
+func Verify(userID int64, code string) error {
+	t, err := getTOTP(userID)
+	if err == sql.ErrNoRows || (err == nil && !t.Enabled) {
+		return ErrNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	code = strings.TrimSpace(code)
+	if step := matchStep(t.Secret, code, time.Now()); step != 0 {
+		ok, err := useStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+	if normalized := normalizeRecoveryCode(code); len(normalized) == 2*recoveryCodeGroupSize {
+		ok, err := useRecoveryCode(userID, hashValue(normalized))
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return ErrInvalidCode
+}
+
+// VerifyFresh confere o código de uma ação sensível. Como em ResolveChallenge,
+// os erros são contados: depois de maxCodeAttempts códigos inválidos seguidos,
+// novos códigos são recusados por CodeLockout.
+func VerifyFresh(userID int64, code string) error {
+	retryAfter, err := codeLockRemaining(userID)
+	if err != nil {
+		return err
+	}
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	err = Verify(userID, code)
+	if errors.Is(err, ErrInvalidCode) {
+		locked, countErr := countFailedCode(userID, maxCodeAttempts, CodeLockout)
+		if countErr != nil {
+			return countErr
+		}
+		if locked {
+			return &LockedError{RetryAfter: CodeLockout}
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return resetFailedCodes(userID)
+}
+
+// Disable desativa o 2FA após confirmar um código válido.
+func Disable(userID int64, code string) error {
+	if err := VerifyFresh(userID, code); err != nil {
+		return err
+	}
+	return deleteTOTP(userID)
+}
+
+// RegenerateRecoveryCodes substitui os códigos de recuperação após confirmar um código válido.
+func RegenerateRecoveryCodes(userID int64, code string) ([]string, error) {
+	if err := VerifyFresh(userID, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := replaceRecoveryCodesTx(tx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, tx.Commit()
+}
+
+// CreateChallenge emite o desafio que substitui o JWT no login de contas com 2FA.
+func CreateChallenge(userID int64) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	if err := insertChallenge(hashValue(token), userID, int(ChallengeTTL.Seconds())); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ChallengeUser devolve o usuário de um desafio ainda utilizável, sem consumi-lo.
+func ChallengeUser(token string) (int64, error) {
+	ch, err := getChallenge(hashValue(token))
+	if err == sql.ErrNoRows {
+		return 0, ErrInvalidChallenge
+	}
+	if err != nil {
+		return 0, err
+	}
+	if ch.Used || ch.Attempts >= maxChallengeAttempts {
+		return 0, ErrInvalidChallenge
+	}
+	if ch.Expired {
+		return 0, ErrChallengeExpired
+	}
+	return ch.UserID, nil
+}
+
+// ResolveChallenge troca o desafio e um código válido pelo usuário autenticado.
+// Cada desafio aceita poucas tentativas e só pode ser usado uma vez.
+func ResolveChallenge(token, code string) (int64, error) {
+	userID, err := ChallengeUser(token)
+	if err != nil {
+		return 0, err
+	}
+	hash := hashValue(token)
+	if ok, err := countAttempt(hash, maxChallengeAttempts); err != nil || !ok {
+		return 0, firstErr(err, ErrInvalidChallenge)
+	}
+	if err := Verify(userID, code); err != nil {
+		return userID, err
+	}
+	if ok, err := markChallengeUsed(hash); err != nil || !ok {
+		return 0, firstErr(err, ErrInvalidChallenge)
+	}
+	return userID, nil
+}
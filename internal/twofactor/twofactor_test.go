@@ -0,0 +1,231 @@
+package twofactor
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/testutil"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rfcSecret é o segredo ASCII "12345678901234567890" dos vetores da RFC 6238, em base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtMatchesRFC6238(t *testing.T) {
+	// Os vetores da RFC têm 8 dígitos; aqui valem os 6 últimos.
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := codeAt(rfcSecret, unix/totpPeriod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("codeAt(T=%d) = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestMatchStepSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+	for _, delta := range []int64{-1, 0, 1} {
+		code, _ := codeAt(rfcSecret, current+delta)
+		if got := matchStep(rfcSecret, code, now); got != current+delta {
+			t.Errorf("step %+d: matchStep = %d, want %d", delta, got, current+delta)
+		}
+	}
+	for _, delta := range []int64{-2, 2} {
+		code, _ := codeAt(rfcSecret, current+delta)
+		if got := matchStep(rfcSecret, code, now); got != 0 {
+			t.Errorf("step %+d outside the skew was accepted", delta)
+		}
+	}
+	if matchStep(rfcSecret, "12345", now) != 0 || matchStep(rfcSecret, "", now) != 0 {
+		t.Error("malformed code was accepted")
+	}
+}
+
+// enroll ativa o 2FA do usuário e devolve o segredo, os códigos de
+// recuperação e o intervalo usado na confirmação.
+func enroll(t *testing.T, userID int64) (string, []string, int64) {
+	t.Helper()
+	secret, uri, err := BeginEnrollment(userID, "ana@example.com")
+	if err != nil {
+		t.Fatalf("BeginEnrollment: %v", err)
+	}
+	if uri == "" {
+		t.Fatal("empty otpauth URI")
+	}
+	step := time.Now().Unix() / totpPeriod
+	code, _ := codeAt(secret, step)
+	codes, err := ConfirmEnrollment(userID, code)
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment: %v", err)
+	}
+	return secret, codes, step
+}
+
+func TestVerifyRejectsReplayedCode(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	if err := Verify(user, "000000"); !errors.Is(err, ErrNotEnabled) {
+		t.Fatalf("Verify before enrollment: error = %v, want ErrNotEnabled", err)
+	}
+	secret, _, step := enroll(t, user)
+	if enabled, err := IsEnabled(user); err != nil || !enabled {
+		t.Fatalf("IsEnabled = %v, %v; want true", enabled, err)
+	}
+
+	// O código usado na confirmação não vale de novo
+	code, _ := codeAt(secret, step)
+	if err := Verify(user, code); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("replayed code: error = %v, want ErrInvalidCode", err)
+	}
+	next, _ := codeAt(secret, step+1)
+	if err := Verify(user, " "+next+" "); err != nil {
+		t.Fatalf("Verify next code: %v", err)
+	}
+	if err := Verify(user, next); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("reused code: error = %v, want ErrInvalidCode", err)
+	}
+	if err := Verify(user, "abcdef"); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("garbage code: error = %v, want ErrInvalidCode", err)
+	}
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	_, codes, _ := enroll(t, user)
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("%d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	if err := Verify(user, codes[0]); err != nil {
+		t.Fatalf("Verify recovery code: %v", err)
+	}
+	if err := Verify(user, codes[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("reused recovery code: error = %v, want ErrInvalidCode", err)
+	}
+	// Maiúsculas, espaços e hífens digitados pelo usuário são ignorados
+	typed := []byte(codes[1])
+	for i := range typed {
+		if typed[i] >= 'a' && typed[i] <= 'z' {
+			typed[i] -= 'a' - 'A'
+		}
+	}
+	if err := Verify(user, string(typed[:5])+" "+string(typed[6:])); err != nil {
+		t.Fatalf("Verify recovery code typed differently: %v", err)
+	}
+	if n, err := CountRecoveryCodes(user); err != nil || n != recoveryCodeCount-2 {
+		t.Fatalf("CountRecoveryCodes = %d, %v; want %d", n, err, recoveryCodeCount-2)
+	}
+}
+
+func TestChallengeLimitsAttempts(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	secret, _, step := enroll(t, user)
+
+	token, err := CreateChallenge(user)
+	if err != nil {
+		t.Fatalf("CreateChallenge: %v", err)
+	}
+	for i := 0; i < maxChallengeAttempts; i++ {
+		if _, err := ResolveChallenge(token, "000000"); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("attempt %d: error = %v, want ErrInvalidCode", i+1, err)
+		}
+	}
+	// Esgotadas as tentativas, nem o código certo é aceito
+	valid, _ := codeAt(secret, step+1)
+	if _, err := ResolveChallenge(token, valid); !errors.Is(err, ErrInvalidChallenge) {
+		t.Fatalf("after max attempts: error = %v, want ErrInvalidChallenge", err)
+	}
+
+	token, err = CreateChallenge(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ResolveChallenge(token, valid)
+	if err != nil || got != user {
+		t.Fatalf("ResolveChallenge = %d, %v; want %d", got, err, user)
+	}
+	if _, err := ResolveChallenge(token, valid); !errors.Is(err, ErrInvalidChallenge) {
+		t.Fatalf("reused challenge: error = %v, want ErrInvalidChallenge", err)
+	}
+}
+
+func TestFreshCodeLocksAfterMaxAttempts(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	secret, codes, step := enroll(t, user)
+
+	// Um código aceito zera a contagem
+	for i := 0; i < maxCodeAttempts-1; i++ {
+		if err := VerifyFresh(user, "000000"); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("attempt %d: error = %v, want ErrInvalidCode", i+1, err)
+		}
+	}
+	valid, _ := codeAt(secret, step+1)
+	if err := VerifyFresh(user, valid); err != nil {
+		t.Fatalf("VerifyFresh with a valid code: %v", err)
+	}
+
+	for i := 0; i < maxCodeAttempts-1; i++ {
+		if err := VerifyFresh(user, "000000"); !errors.Is(err, ErrInvalidCode) {
+			t.Fatalf("attempt %d after reset: error = %v, want ErrInvalidCode", i+1, err)
+		}
+	}
+	var locked *LockedError
+	if err := VerifyFresh(user, "000000"); !errors.As(err, &locked) || locked.RetryAfter != CodeLockout {
+		t.Fatalf("last attempt: error = %v, want a LockedError for %v", err, CodeLockout)
+	}
+	// Bloqueado, nem o código certo é aceito
+	if err := VerifyFresh(user, codes[0]); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("valid code while locked: error = %v, want ErrTooManyAttempts", err)
+	}
+
+	if _, err := config.DB.Exec(`UPDATE user_totp SET code_locked_until = datetime('now', '-1 second') WHERE user_id = ?`, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFresh(user, codes[0]); err != nil {
+		t.Fatalf("VerifyFresh after the lockout: %v", err)
+	}
+}
+
+func TestRequireFreshCodeCountsFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+	enroll(t, user)
+
+	r := gin.New()
+	r.POST("/withdraw", func(c *gin.Context) { c.Set("userID", user) }, RequireFreshCode(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	send := func(code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/withdraw", nil)
+		req.Header.Set(CodeHeader, code)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	for i := 0; i < maxCodeAttempts-1; i++ {
+		if w := send("000000"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status %d, want 401", i+1, w.Code)
+		}
+	}
+	w := send("000000")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("last attempt: status %d, headers %v; want 429 with Retry-After", w.Code, w.Header())
+	}
+}
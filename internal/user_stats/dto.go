@@ -1,14 +1,14 @@
 package user_stats
 
 type UserStatsRequest struct {
-	UserID         int64   `json:"user_id"`
-	TotalBets      int64   `json:"total_bets"`
-	TotalWins      int64   `json:"total_wins"`
-	TotalLosses    int64   `json:"total_losses"`
-	TotalAmountBet float64 `json:"total_amount_bet"`
-	TotalProfit    float64 `json:"total_profit"`
-	Balance        float64 `json:"balance"`
-	LastBetAt      string  `json:"last_bet_at"`
+	UserID         int64    `json:"user_id"`
+	TotalBets      int64    `json:"total_bets"`
+	TotalWins      int64    `json:"total_wins"`
+	TotalLosses    int64    `json:"total_losses"`
+	TotalAmountBet float64  `json:"total_amount_bet"`
+	TotalProfit    float64  `json:"total_profit"`
+	Balance        *float64 `json:"balance,omitempty"` // read-only: balance only changes through the wallet
+	LastBetAt      string   `json:"last_bet_at"`
 }
 
 type UserStatsResponse struct {
@@ -2,6 +2,7 @@ package user_stats
 
 import (
 	"berry_bet/config"
+	"berry_bet/internal/audit"
 	"berry_bet/internal/common"
 	"berry_bet/internal/utils"
 	"database/sql"
@@ -44,6 +45,12 @@ func GetUserStatsByIDHandler(c *gin.Context) {
 	utils.RespondSuccess(c, ToUserStatsResponse(&stats), "User stats found")
 }
 
+// respondBalanceReadOnly recusa mudanças de saldo pelo CRUD de estatísticas:
+// o saldo só muda pela carteira, com lançamento no ledger
+func respondBalanceReadOnly(c *gin.Context) {
+	utils.RespondError(c, http.StatusBadRequest, "BALANCE_READ_ONLY", "Balance cannot be edited here; use a balance adjustment.", nil)
+}
+
 // AddUserStatsHandler creates new user stats (DTO request/response).
 func AddUserStatsHandler(c *gin.Context) {
 	var req UserStatsRequest
@@ -51,6 +58,10 @@ func AddUserStatsHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
 		return
 	}
+	if req.Balance != nil && *req.Balance != 0 {
+		respondBalanceReadOnly(c)
+		return
+	}
 	stats := UserStats{
 		UserID:         req.UserID,
 		TotalBets:      req.TotalBets,
@@ -58,7 +69,6 @@ func AddUserStatsHandler(c *gin.Context) {
 		TotalLosses:    req.TotalLosses,
 		TotalAmountBet: req.TotalAmountBet,
 		TotalProfit:    req.TotalProfit,
-		Balance:        req.Balance,
 		LastBetAt:      sql.NullString{String: req.LastBetAt, Valid: req.LastBetAt != ""},
 	}
 	success, err := AddUserStats(stats)
@@ -67,6 +77,12 @@ func AddUserStatsHandler(c *gin.Context) {
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user_stats.create",
+			TargetType: "user",
+			TargetID:   req.UserID,
+			After:      req,
+		})
 		utils.RespondSuccess(c, nil, "User stats registered successfully")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "INSERT_FAIL", "Could not register user stats.", nil)
@@ -93,15 +109,34 @@ func UpdateUserStatsHandler(c *gin.Context) {
 		TotalLosses:    req.TotalLosses,
 		TotalAmountBet: req.TotalAmountBet,
 		TotalProfit:    req.TotalProfit,
-		Balance:        req.Balance,
 		LastBetAt:      sql.NullString{String: req.LastBetAt, Valid: req.LastBetAt != ""},
 	}
+	previous, err := getUserStatsByRowID(int64(statsId))
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User stats not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user stats.", err.Error())
+		return
+	}
+	if req.Balance != nil && *req.Balance != previous.Balance {
+		respondBalanceReadOnly(c)
+		return
+	}
 	success, err := UpdateUserStats(stats, int64(statsId))
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to update user stats.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user_stats.update",
+			TargetType: "user_stats",
+			TargetID:   stats.ID,
+			Before:     ToUserStatsResponse(&previous),
+			After:      req,
+		})
 		utils.RespondSuccess(c, nil, "User stats updated successfully")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "UPDATE_FAIL", "Could not update user stats.", nil)
@@ -115,12 +150,27 @@ func DeleteUserStatsHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
 		return
 	}
+	previous, err := getUserStatsByRowID(int64(statsId))
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User stats not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user stats.", err.Error())
+		return
+	}
 	success, err := DeleteUserStats(statsId)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DELETE_FAIL", "Could not delete user stats.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user_stats.delete",
+			TargetType: "user_stats",
+			TargetID:   int64(statsId),
+			Before:     ToUserStatsResponse(&previous),
+		})
 		utils.RespondSuccess(c, nil, "User stats deleted successfully.")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "DELETE_FAIL", "Could not delete user stats.", nil)
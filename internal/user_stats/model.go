@@ -2,6 +2,7 @@ package user_stats
 
 import (
 	"berry_bet/config"
+	"berry_bet/internal/wallet"
 	"database/sql"
 	"errors"
 )
@@ -53,6 +54,14 @@ func GetUserStatsByID(id string) (UserStats, error) {
 	return s, nil
 }
 
+// getUserStatsByRowID busca as estatísticas pelo ID do registro (e não do usuário).
+func getUserStatsByRowID(id int64) (UserStats, error) {
+	var s UserStats
+	err := config.DB.QueryRow("SELECT id, user_id, total_bets, total_wins, total_losses, total_amount_bet, total_profit, balance, consecutive_losses, last_bet_at, created_at, updated_at FROM user_stats WHERE id = ?", id).
+		Scan(&s.ID, &s.UserID, &s.TotalBets, &s.TotalWins, &s.TotalLosses, &s.TotalAmountBet, &s.TotalProfit, &s.Balance, &s.ConsecutiveLosses, &s.LastBetAt, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
 // AddUserStats adiciona estatísticas de usuário ao banco de dados após validação dos dados.
 func AddUserStats(newStats UserStats) (bool, error) {
 	if newStats.UserID <= 0 {
@@ -71,16 +80,17 @@ func AddUserStats(newStats UserStats) (bool, error) {
 }
 
 // UpdateUserStats atualiza estatísticas de usuário após validação dos dados.
+// O saldo não é gravado aqui: ele só muda pela carteira (wallet.Credit/Debit).
 func UpdateUserStats(stats UserStats, id int64) (bool, error) {
 	if stats.UserID <= 0 {
 		return false, errors.New("user_id inválido")
 	}
-	stmt, err := config.DB.Prepare("UPDATE user_stats SET total_bets = ?, total_wins = ?, total_losses = ?, total_amount_bet = ?, total_profit = ?, balance = ?, consecutive_losses = ?, last_bet_at = ?, updated_at = datetime('now') WHERE id = ?")
+	stmt, err := config.DB.Prepare("UPDATE user_stats SET total_bets = ?, total_wins = ?, total_losses = ?, total_amount_bet = ?, total_profit = ?, consecutive_losses = ?, last_bet_at = ?, updated_at = datetime('now') WHERE id = ?")
 	if err != nil {
 		return false, err
 	}
 	defer stmt.Close()
-	_, err = stmt.Exec(stats.TotalBets, stats.TotalWins, stats.TotalLosses, stats.TotalAmountBet, stats.TotalProfit, stats.Balance, stats.ConsecutiveLosses, stats.LastBetAt, id)
+	_, err = stmt.Exec(stats.TotalBets, stats.TotalWins, stats.TotalLosses, stats.TotalAmountBet, stats.TotalProfit, stats.ConsecutiveLosses, stats.LastBetAt, id)
 	if err != nil {
 		return false, err
 	}
@@ -101,128 +111,33 @@ func DeleteUserStats(statsId int) (bool, error) {
 	return true, nil
 }
 
-// UpdateUserStatsAfterBet atualiza estatísticas do usuário após uma aposta
-func UpdateUserStatsAfterBet(userID int64, betAmount float64, isWin bool, profitLoss float64) error {
-	tx, err := config.DB.Begin()
-	if err != nil {
-		return err
+// RecordBetTx soma uma aposta às estatísticas do usuário dentro da transação
+// informada. Os contadores são incrementados no próprio UPDATE; o saldo não é
+// tocado aqui, pois só muda pela carteira (wallet.Credit/Debit).
+func RecordBetTx(tx *sql.Tx, userID int64, betAmount float64, isWin bool, profit float64) error {
+	wins, losses := 0, 1
+	if isWin {
+		wins, losses = 1, 0
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Calcular o balance atual do usuário baseado nas transações
-	var balance float64
-	rows, err := tx.Query(`SELECT type, amount FROM transactions WHERE user_id = ?`, userID)
+	res, err := tx.Exec(`
+		UPDATE user_stats
+		SET total_bets = total_bets + 1,
+			total_wins = total_wins + ?,
+			total_losses = total_losses + ?,
+			total_amount_bet = total_amount_bet + ?,
+			total_profit = total_profit + ?,
+			consecutive_losses = CASE WHEN ? THEN 0 ELSE consecutive_losses + 1 END,
+			last_bet_at = datetime('now'),
+			updated_at = datetime('now')
+		WHERE user_id = ?`,
+		wins, losses, betAmount, profit, isWin, userID)
 	if err != nil {
 		return err
 	}
-	for rows.Next() {
-		var ttype string
-		var amount float64
-		if err := rows.Scan(&ttype, &amount); err != nil {
-			rows.Close()
-			return err
-		}
-		switch ttype {
-		case "deposit", "win", "bonus":
-			balance += amount
-		case "bet", "withdraw":
-			balance -= amount
-		}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("user stats not found")
 	}
-	rows.Close()
-
-	// Verificar se já existem estatísticas para o usuário
-	var exists bool
-	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM user_stats WHERE user_id = ?)", userID).Scan(&exists)
-	if err != nil {
-		return err
-	}
-
-	if !exists {
-		// Criar registro inicial
-		_, err = tx.Exec(`
-			INSERT INTO user_stats (user_id, total_bets, total_wins, total_losses, total_amount_bet, total_profit, balance, last_bet_at, created_at, updated_at) 
-			VALUES (?, 1, ?, ?, ?, ?, ?, datetime('now'), datetime('now'), datetime('now'))`,
-			userID,
-			map[bool]int{true: 1, false: 0}[isWin],
-			map[bool]int{true: 0, false: 1}[isWin],
-			betAmount,
-			profitLoss,
-			balance)
-	} else {
-		// Atualizar registro existente
-		winsIncrement := 0
-		lossesIncrement := 0
-		if isWin {
-			winsIncrement = 1
-		} else {
-			lossesIncrement = 1
-		}
-
-		_, err = tx.Exec(`
-			UPDATE user_stats 
-			SET total_bets = total_bets + 1,
-				total_wins = total_wins + ?,
-				total_losses = total_losses + ?,
-				total_amount_bet = total_amount_bet + ?,
-				total_profit = total_profit + ?,
-				balance = ?,
-				last_bet_at = datetime('now'),
-				updated_at = datetime('now')
-			WHERE user_id = ?`,
-			winsIncrement, lossesIncrement, betAmount, profitLoss, balance, userID)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
-// UpdateUserBalance atualiza o balance do usuário para o valor informado
-func UpdateUserBalance(userID int64, balance float64) error {
-	tx, err := config.DB.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Verificar se já existem estatísticas para o usuário
-	var exists bool
-	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM user_stats WHERE user_id = ?)", userID).Scan(&exists)
-	if err != nil {
-		return err
-	}
-
-	if !exists {
-		// Criar registro inicial se não existir
-		_, err = tx.Exec(`
-			INSERT INTO user_stats (user_id, total_bets, total_wins, total_losses, total_amount_bet, total_profit, balance, created_at, updated_at) 
-			VALUES (?, 0, 0, 0, 0.0, 0.0, ?, datetime('now'), datetime('now'))`,
-			userID, balance)
-	} else {
-		// Atualizar apenas o balance
-		_, err = tx.Exec(`
-			UPDATE user_stats 
-			SET balance = ?, updated_at = datetime('now')
-			WHERE user_id = ?`,
-			balance, userID)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	return nil
 }
 
 // GetTopPlayersByProfit retorna os jogadores com maior lucro
@@ -302,12 +217,7 @@ func GetUserBalance(userID int64) (float64, error) {
 				if err := rows.Scan(&ttype, &amount); err != nil {
 					return 0, err
 				}
-				switch ttype {
-				case "deposit", "win", "bonus":
-					balance += amount
-				case "bet", "withdraw":
-					balance -= amount
-				}
+				balance += wallet.ApplySign(ttype, amount)
 			}
 			return balance, rows.Err()
 		}
@@ -337,12 +247,7 @@ func GetUserBalanceByID(userIDStr string) (float64, error) {
 				if err := rows.Scan(&ttype, &amount); err != nil {
 					return 0, err
 				}
-				switch ttype {
-				case "deposit", "win", "bonus":
-					balance += amount
-				case "bet", "withdraw":
-					balance -= amount
-				}
+				balance += wallet.ApplySign(ttype, amount)
 			}
 			return balance, rows.Err()
 		}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"berry_bet/config"
+	"berry_bet/internal/wallet"
 )
 
 func CalculateUserBalance(userID int64) (float64, error) {
@@ -17,12 +18,7 @@ func CalculateUserBalance(userID int64) (float64, error) {
 		if err := rows.Scan(&ttype, &amount); err != nil {
 			return 0, err
 		}
-		switch ttype {
-		case "deposit", "win", "bonus":
-			balance += amount
-		case "bet", "withdraw":
-			balance -= amount
-		}
+		balance += wallet.ApplySign(ttype, amount)
 	}
 	return balance, nil
 }
@@ -15,6 +15,20 @@ func ToUserResponseWithBalance(u *User, balance float64) UserResponse {
 	}
 }
 
+// profileSnapshot devolve os dados cadastrais registrados na auditoria (sem o
+// hash da senha).
+func profileSnapshot(u *User) map[string]any {
+	return map[string]any{
+		"username":   u.Username,
+		"name":       u.Name,
+		"email":      u.Email,
+		"cpf":        u.CPF,
+		"phone":      u.Phone,
+		"date_birth": u.DateBirth,
+		"avatar_url": u.AvatarURL,
+	}
+}
+
 type UserRequest struct {
 	Username  string `json:"username"`
 	Name      string `json:"name"`
@@ -37,6 +51,14 @@ type UserResponse struct {
 	Balance   float64 `json:"balance"`
 }
 
+// MeResponse é o UserResponse do próprio usuário, com o status de verificação
+// do e-mail e o fuso horário configurado.
+type MeResponse struct {
+	UserResponse
+	EmailVerified bool   `json:"email_verified"`
+	Timezone      string `json:"timezone"`
+}
+
 // ToUserResponse monta o UserResponse buscando o saldo em user_stats
 
 // REMOVED: ToUserResponse. Use ToUserResponseWithBalance instead.
@@ -1,12 +1,18 @@
 package users
 
 import (
+	"berry_bet/internal/audit"
 	"berry_bet/internal/common"
+	"berry_bet/internal/sessions"
 	"berry_bet/internal/token"
+	"berry_bet/internal/twofactor"
 	"berry_bet/internal/user_stats"
 	"berry_bet/internal/utils"
+	"database/sql"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -47,6 +53,20 @@ func GetUserByIDHandler(c *gin.Context) {
 	utils.RespondSuccess(c, ToUserResponseWithBalance(&user, balance), "User found")
 }
 
+// checkBirthDate requires a valid date of birth of someone at least 18 years
+// old, writing the error response itself.
+func checkBirthDate(c *gin.Context, date string) bool {
+	if !utils.IsValidDate(date) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_DATE_BIRTH", "date_birth must be a valid YYYY-MM-DD date.", nil)
+		return false
+	}
+	if !utils.IsAdult(date) {
+		utils.RespondError(c, http.StatusForbidden, "UNDERAGE", "Users must be at least 18 years old.", nil)
+		return false
+	}
+	return true
+}
+
 // AddUserHandler creates a new user (DTO request/response).
 func AddUserHandler(c *gin.Context) {
 	var req UserRequest
@@ -66,6 +86,9 @@ func AddUserHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_PHONE", "Invalid phone number.", nil)
 		return
 	}
+	if !checkBirthDate(c, req.DateBirth) {
+		return
+	}
 	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "HASH_ERROR", "Failed to hash password.", err.Error())
@@ -86,6 +109,12 @@ func AddUserHandler(c *gin.Context) {
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user.create",
+			TargetType: "user",
+			TargetID:   user.ID,
+			After:      profileSnapshot(&user),
+		})
 		balance, _ := user_stats.GetUserBalance(user.ID)
 		utils.RespondSuccess(c, ToUserResponseWithBalance(&user, balance), "User registered successfully.")
 	} else {
@@ -105,6 +134,9 @@ func UpdateUserHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
 		return
 	}
+	if req.DateBirth != "" && !checkBirthDate(c, req.DateBirth) {
+		return
+	}
 	user := User{
 		ID:        int64(userId),
 		Username:  req.Username,
@@ -122,12 +154,29 @@ func UpdateUserHandler(c *gin.Context) {
 		}
 		user.PasswordHash = string(hashed)
 	}
+	previous, err := GetUserByID(strconv.Itoa(userId))
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
 	success, err := UpdateUser(user, int64(userId))
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "UPDATE_FAIL", "Could not update user.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user.update",
+			TargetType: "user",
+			TargetID:   user.ID,
+			Before:     profileSnapshot(&previous),
+			After:      profileSnapshot(&user),
+			Details:    gin.H{"password_changed": req.Password != ""},
+		})
 		balance, _ := user_stats.GetUserBalance(user.ID)
 		utils.RespondSuccess(c, ToUserResponseWithBalance(&user, balance), "User updated successfully.")
 	} else {
@@ -142,12 +191,27 @@ func DeleteUserHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
 		return
 	}
+	previous, err := GetUserByID(strconv.Itoa(userId))
+	if err == sql.ErrNoRows {
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "User not found.", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
 	success, err := DeleteUser(userId)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "DELETE_FAIL", "Could not delete user.", err.Error())
 		return
 	}
 	if success {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user.delete",
+			TargetType: "user",
+			TargetID:   int64(userId),
+			Before:     profileSnapshot(&previous),
+		})
 		utils.RespondSuccess(c, nil, "User deleted successfully.")
 	} else {
 		utils.RespondError(c, http.StatusBadRequest, "DELETE_FAIL", "Could not delete user.", nil)
@@ -212,7 +276,17 @@ func GetMeHandler(c *gin.Context) {
 	}
 	balance, _ := user_stats.GetUserBalance(user.ID)
 	user.PasswordHash = ""
-	utils.RespondSuccess(c, ToUserResponseWithBalance(user, balance), "User data fetched successfully.")
+	verified, err := IsEmailVerified(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
+	timezone, err := GetTimezone(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
+	utils.RespondSuccess(c, MeResponse{UserResponse: ToUserResponseWithBalance(user, balance), EmailVerified: verified, Timezone: timezone}, "User data fetched successfully.")
 }
 
 // UpdateMeHandler updates the authenticated user's data.
@@ -244,6 +318,13 @@ func UpdateMeHandler(c *gin.Context) {
 		CreatedAt:    userCommon.CreatedAt,
 		UpdatedAt:    userCommon.UpdatedAt,
 	}
+	timezone, err := GetTimezone(user.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+		return
+	}
+	before := profileSnapshot(user)
+	before["timezone"] = timezone
 	var req struct {
 		Username  string `json:"username"`
 		Name      string `json:"name"`
@@ -252,11 +333,28 @@ func UpdateMeHandler(c *gin.Context) {
 		CPF       string `json:"cpf"`
 		Phone     string `json:"phone"`
 		DateBirth string `json:"date_birth"`
+		Timezone  string `json:"timezone"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
 		return
 	}
+	if req.Timezone != "" && !ValidTimezone(req.Timezone) {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_TIMEZONE", "Unknown timezone, use an IANA name such as America/Sao_Paulo.", nil)
+		return
+	}
+	if (req.Name != "" && req.Name != user.Name) || (req.CPF != "" && req.CPF != user.CPF) ||
+		(req.DateBirth != "" && !strings.HasPrefix(user.DateBirth, req.DateBirth)) {
+		locked, err := IsIdentityLocked(user.ID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to fetch user.", err.Error())
+			return
+		}
+		if locked {
+			utils.RespondError(c, http.StatusConflict, "IDENTITY_LOCKED", "Name, CPF and date of birth cannot be changed after identity verification.", nil)
+			return
+		}
+	}
 	if req.Username != "" {
 		user.Username = req.Username
 	}
@@ -273,9 +371,16 @@ func UpdateMeHandler(c *gin.Context) {
 		user.Phone = req.Phone
 	}
 	if req.DateBirth != "" {
+		if !checkBirthDate(c, req.DateBirth) {
+			return
+		}
 		user.DateBirth = req.DateBirth
 	}
 	if req.Password != "" {
+		// Trocar a senha exige um código 2FA novo, como em change_password.
+		if !twofactor.CheckFreshCode(c, user.ID) {
+			return
+		}
 		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
 			utils.RespondError(c, http.StatusInternalServerError, "HASH_ERROR", "Failed to hash password.", err.Error())
@@ -288,9 +393,36 @@ func UpdateMeHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusInternalServerError, "UPDATE_FAIL", "Could not update user.", err.Error())
 		return
 	}
+	if req.Timezone != "" && req.Timezone != timezone {
+		if err := SetTimezone(user.ID, req.Timezone); err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "UPDATE_FAIL", "Could not update user.", err.Error())
+			return
+		}
+		timezone = req.Timezone
+	}
+	sessionID := c.GetString("sessionID")
+	after := profileSnapshot(user)
+	after["timezone"] = timezone
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "user.profile_update",
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     before,
+		After:      after,
+	})
+	if req.Password != "" {
+		audit.RecordRequest(c, audit.Entry{
+			Action:     "user.password_change",
+			TargetType: "user",
+			TargetID:   user.ID,
+		})
+		if _, err := sessions.RevokeUserSessions(user.ID, sessionID, "password_change"); err != nil {
+			log.Printf("Erro ao revogar sessões do usuário %d: %v", user.ID, err)
+		}
+	}
 
 	// Gerar novo token JWT com o username atualizado
-	tokenStr, err := token.GenerateJWT(user.Username)
+	tokenStr, err := token.GenerateJWT(user.ID, user.Username, sessionID, c.GetStringSlice("roles"))
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "TOKEN_ERROR", "Failed to generate new token.", err.Error())
 		return
@@ -318,6 +450,14 @@ func GetMeBalanceHandler(c *gin.Context) {
 	utils.RespondSuccess(c, gin.H{"balance": balance}, "Balance fetched successfully.")
 }
 
+// avatarUpload fica em uploads/, servido publicamente em /uploads.
+var avatarUpload = utils.UploadRule{
+	Field:        "avatar",
+	Dir:          "uploads/avatars/",
+	MaxBytes:     5 << 20,
+	AllowedTypes: utils.ImageTypes,
+}
+
 // UploadAvatarHandler faz upload da foto de perfil do usuário autenticado
 func UploadAvatarHandler(c *gin.Context) {
 	username, exists := c.Get("username")
@@ -341,26 +481,26 @@ func UploadAvatarHandler(c *gin.Context) {
 		CreatedAt:    userCommon.CreatedAt,
 		UpdatedAt:    userCommon.UpdatedAt,
 	}
-	file, err := c.FormFile("avatar")
+	saved, err := utils.SaveUpload(c, avatarUpload, strconv.FormatInt(user.ID, 10))
 	if err != nil {
-		utils.RespondError(c, http.StatusBadRequest, "NO_FILE", "No file uploaded.", err.Error())
+		utils.RespondUploadError(c, err)
 		return
 	}
-	// Cria pasta se não existir
-	dir := "uploads/avatars/"
-	_ = utils.EnsureDir(dir)
-	filename := dir + username.(string) + "_" + file.Filename
-	if err := c.SaveUploadedFile(file, filename); err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "UPLOAD_FAIL", "Failed to save file.", err.Error())
-		return
-	}
-	avatarURL := "/" + filename
+	avatarURL := "/" + saved.Path
+	previousAvatar := user.AvatarURL
 	user.AvatarURL = avatarURL
 	_, err = UpdateUser(*user, user.ID)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "UPDATE_FAIL", "Failed to update user avatar.", err.Error())
 		return
 	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "user.avatar_change",
+		TargetType: "user",
+		TargetID:   user.ID,
+		Before:     gin.H{"avatar_url": previousAvatar},
+		After:      gin.H{"avatar_url": avatarURL},
+	})
 	utils.RespondSuccess(c, gin.H{
 		"avatarUrl": avatarURL,
 		"user": ToUserResponseWithBalance(user, 0), // Retorna os dados atualizados do usuário
@@ -422,5 +562,14 @@ func ChangePasswordHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusInternalServerError, "UPDATE_FAIL", "Could not update password.", err.Error())
 		return
 	}
+	audit.RecordRequest(c, audit.Entry{
+		Action:     "user.password_change",
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
+	// Encerra as sessões dos outros dispositivos; a sessão atual continua válida.
+	if _, err := sessions.RevokeUserSessions(user.ID, c.GetString("sessionID"), "password_change"); err != nil {
+		log.Printf("Erro ao revogar sessões do usuário %d: %v", user.ID, err)
+	}
 	utils.RespondSuccess(c, gin.H{"message": "Senha alterada com sucesso!"}, "Password changed successfully.")
 }
@@ -124,6 +124,13 @@ func AddUser(newUser User) (bool, error) {
 		return false, err
 	}
 
+	// Todo novo usuário começa com o papel player
+	_, err = tx.Exec(`INSERT INTO user_roles (user_id, role_id, created_at) SELECT ?, id, datetime('now') FROM roles WHERE name = 'player'`, userID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return false, err
@@ -146,13 +153,14 @@ func UpdateUser(ourUser User, id int64) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	stmt, err := config.DB.Prepare("UPDATE users SET username = ?, name = ?, email = ?, password_hash = ?, cpf = ?, phone = ?, date_birth = ?, avatar_url = ?, updated_at = datetime('now') WHERE id = ?")
+	// Trocar o e-mail desfaz a verificação do endereço anterior.
+	stmt, err := config.DB.Prepare("UPDATE users SET username = ?, name = ?, email = ?, password_hash = ?, cpf = ?, phone = ?, date_birth = ?, avatar_url = ?, email_verified_at = CASE WHEN email = ? THEN email_verified_at END, updated_at = datetime('now') WHERE id = ?")
 	if err != nil {
 		tx.Rollback()
 		return false, err
 	}
 	defer stmt.Close()
-	_, err = stmt.Exec(ourUser.Username, ourUser.Name, ourUser.Email, ourUser.PasswordHash, ourUser.CPF, ourUser.Phone, ourUser.DateBirth, ourUser.AvatarURL, id)
+	_, err = stmt.Exec(ourUser.Username, ourUser.Name, ourUser.Email, ourUser.PasswordHash, ourUser.CPF, ourUser.Phone, ourUser.DateBirth, ourUser.AvatarURL, ourUser.Email, id)
 	if err != nil {
 		tx.Rollback()
 		return false, err
@@ -231,30 +239,6 @@ func UpdateUserPassword(userID int64, newPasswordHash string) error {
 	return tx.Commit()
 }
 
-// UpdateUserBalance atualiza apenas o saldo do usuário em user_stats
-func UpdateUserBalance(userID int64, newBalance float64) error {
-	tx, err := config.DB.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
-
-	_, err = tx.Exec(`
-			   UPDATE user_stats 
-			   SET balance = ?, updated_at = datetime('now') 
-			   WHERE user_id = ?`, newBalance, userID)
-
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
 // CheckUserExists verifica se um usuário existe por username ou email
 func CheckUserExists(username, email string) (bool, error) {
 	var count int
@@ -269,3 +253,38 @@ func CheckUserExists(username, email string) (bool, error) {
 
 	return count > 0, nil
 }
+
+// GetUserStatus retorna o status da conta ('active' ou 'frozen').
+func GetUserStatus(userID int64) (string, error) {
+	var status string
+	err := config.DB.QueryRow("SELECT status FROM users WHERE id = ?", userID).Scan(&status)
+	return status, err
+}
+
+// IsEmailVerified indica se o e-mail atual do usuário já foi confirmado.
+func IsEmailVerified(userID int64) (bool, error) {
+	var verified bool
+	err := config.DB.QueryRow("SELECT email_verified_at IS NOT NULL FROM users WHERE id = ?", userID).Scan(&verified)
+	return verified, err
+}
+
+// IsIdentityLocked indica se nome, CPF e data de nascimento estão travados
+// por uma verificação de identidade em análise ou aprovada.
+func IsIdentityLocked(userID int64) (bool, error) {
+	var locked bool
+	err := config.DB.QueryRow("SELECT kyc_status IN ('pending', 'verified') FROM users WHERE id = ?", userID).Scan(&locked)
+	return locked, err
+}
+
+// MarkEmailVerified confirma o e-mail do usuário, desde que ele ainda seja o
+// endereço para o qual a verificação foi enviada.
+func MarkEmailVerified(userID int64, email string) (bool, error) {
+	res, err := config.DB.Exec(`
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, datetime('now')), updated_at = datetime('now')
+		WHERE id = ? AND email = ?`, userID, email)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
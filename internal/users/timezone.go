@@ -0,0 +1,54 @@
+package users
+
+import (
+	"berry_bet/config"
+	"time"
+
+	// Embute a base de fusos para não depender do zoneinfo do sistema
+	_ "time/tzdata"
+)
+
+// DefaultTimezone é o fuso de quem ainda não configurou o seu
+const DefaultTimezone = "America/Sao_Paulo"
+
+// ValidTimezone indica se o nome é um fuso IANA conhecido (ex.: "America/Manaus")
+func ValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// GetTimezone retorna o fuso configurado pelo usuário
+func GetTimezone(userID int64) (string, error) {
+	var tz string
+	err := config.DB.QueryRow("SELECT COALESCE(timezone, '') FROM users WHERE id = ?", userID).Scan(&tz)
+	if err != nil {
+		return "", err
+	}
+	if tz == "" {
+		tz = DefaultTimezone
+	}
+	return tz, nil
+}
+
+// GetLocation retorna o *time.Location do usuário, caindo no fuso padrão
+// quando o valor salvo não é reconhecido
+func GetLocation(userID int64) (*time.Location, error) {
+	tz, err := GetTimezone(userID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.LoadLocation(DefaultTimezone)
+	}
+	return loc, nil
+}
+
+// SetTimezone grava o fuso do usuário (já validado com ValidTimezone)
+func SetTimezone(userID int64, tz string) error {
+	_, err := config.DB.Exec("UPDATE users SET timezone = ?, updated_at = datetime('now') WHERE id = ?", tz, userID)
+	return err
+}
@@ -20,7 +20,7 @@ type APIResponse struct {
 }
 
 func RespondError(c *gin.Context, status int, code, message string, details any) {
-	log.Printf("API error [%s]: %s | details: %v", code, message, details)
+	log.Printf("API error [%s]: %s | details: %v | request_id: %s", code, message, details, c.GetString("requestID"))
 	c.JSON(status, APIResponse{
 		Success: false,
 		Error: &APIError{
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUserID returns the authenticated user's ID set by the JWT middleware.
+// When it is missing it writes the error response and returns false.
+func CurrentUserID(c *gin.Context) (int64, bool) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		RespondError(c, http.StatusUnauthorized, "NO_AUTH", "User not authenticated.", nil)
+		return 0, false
+	}
+	userID, ok := userIDInterface.(int64)
+	if !ok {
+		RespondError(c, http.StatusInternalServerError, "SERVER_ERROR", "Failed to read user ID.", nil)
+		return 0, false
+	}
+	return userID, true
+}
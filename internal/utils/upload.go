@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrNoFile       = errors.New("no file uploaded")
+	ErrFileTooLarge = errors.New("file is too large")
+	ErrFileType     = errors.New("file type not allowed")
+)
+
+// UploadRule define onde e como um campo de upload é salvo. O tipo do arquivo
+// é detectado pelo conteúdo, não pelo nome enviado pelo cliente.
+type UploadRule struct {
+	Field        string
+	Dir          string
+	MaxBytes     int64
+	AllowedTypes map[string]string // tipo MIME -> extensão gravada
+}
+
+var ImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// SavedFile é o arquivo gravado por SaveUpload.
+type SavedFile struct {
+	Path        string
+	ContentType string
+	Size        int64
+}
+
+// SaveUpload valida e grava o arquivo do campo rule.Field em rule.Dir, com
+// nome aleatório prefixado por prefix.
+func SaveUpload(c *gin.Context, rule UploadRule, prefix string) (SavedFile, error) {
+	file, err := c.FormFile(rule.Field)
+	if err != nil {
+		return SavedFile{}, fmt.Errorf("%w: %v", ErrNoFile, err)
+	}
+	if rule.MaxBytes > 0 && file.Size > rule.MaxBytes {
+		return SavedFile{}, ErrFileTooLarge
+	}
+	f, err := file.Open()
+	if err != nil {
+		return SavedFile{}, err
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	f.Close()
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return SavedFile{}, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := rule.AllowedTypes[contentType]
+	if !ok {
+		return SavedFile{}, ErrFileType
+	}
+
+	if err := EnsureDir(rule.Dir); err != nil {
+		return SavedFile{}, err
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return SavedFile{}, err
+	}
+	path := filepath.ToSlash(filepath.Join(rule.Dir, prefix+"_"+hex.EncodeToString(suffix)+ext))
+	if err := c.SaveUploadedFile(file, path); err != nil {
+		return SavedFile{}, err
+	}
+	return SavedFile{Path: path, ContentType: contentType, Size: file.Size}, nil
+}
+
+// RespondUploadError escreve a resposta adequada a um erro de SaveUpload.
+func RespondUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNoFile):
+		RespondError(c, http.StatusBadRequest, "NO_FILE", "No file uploaded.", err.Error())
+	case errors.Is(err, ErrFileTooLarge):
+		RespondError(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "File is too large.", nil)
+	case errors.Is(err, ErrFileType):
+		RespondError(c, http.StatusUnsupportedMediaType, "INVALID_FILE_TYPE", "File type not allowed.", nil)
+	default:
+		RespondError(c, http.StatusInternalServerError, "UPLOAD_FAIL", "Failed to save file.", err.Error())
+	}
+}
@@ -54,3 +54,20 @@ func IsValidDate(dateStr string) bool {
 	_, err := time.Parse("2006-01-02", dateStr)
 	return err == nil
 }
+
+// MinimumAge é a idade mínima para apostar.
+const MinimumAge = 18
+
+// IsAdult checks that the YYYY-MM-DD birth date is valid, not in the future
+// and at least MinimumAge years ago.
+func IsAdult(dateStr string) bool {
+	birth, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return false
+	}
+	now := time.Now().UTC()
+	if birth.After(now) {
+		return false
+	}
+	return !birth.AddDate(MinimumAge, 0, 0).After(now)
+}
@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrInsufficientFunds é retornado quando o saldo não cobre o débito solicitado.
+var ErrInsufficientFunds = errors.New("insufficient balance")
+
+// ApplySign devolve o valor com o sinal correspondente ao efeito do tipo de
+// transação no saldo do usuário (positivo para créditos, negativo para débitos).
+func ApplySign(ttype string, amount float64) float64 {
+	switch ttype {
+	case "deposit", "win", "bonus", "tournament_prize", "tournament_refund", "adjustment_credit", "withdraw_refund":
+		return amount
+	case "bet", "withdraw", "tournament_fee", "adjustment_debit":
+		return -amount
+	}
+	return 0
+}
+
+// Credit adiciona o valor ao saldo do usuário e registra a transação no ledger,
+// dentro da transação SQL informada. Retorna o novo saldo.
+func Credit(tx *sql.Tx, userID int64, amount float64, ttype, description string) (float64, error) {
+	if amount <= 0 {
+		return 0, errors.New("amount must be greater than zero")
+	}
+	return move(tx, userID, amount, ttype, description)
+}
+
+// Debit retira o valor do saldo do usuário e registra a transação no ledger,
+// dentro da transação SQL informada. Falha com ErrInsufficientFunds se o saldo
+// for menor que o valor. Retorna o novo saldo.
+func Debit(tx *sql.Tx, userID int64, amount float64, ttype, description string) (float64, error) {
+	if amount <= 0 {
+		return 0, errors.New("amount must be greater than zero")
+	}
+	balance, err := balanceTx(tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if balance < amount {
+		return 0, ErrInsufficientFunds
+	}
+	return move(tx, userID, -amount, ttype, description)
+}
+
+func move(tx *sql.Tx, userID int64, delta float64, ttype, description string) (float64, error) {
+	amount := delta
+	if amount < 0 {
+		amount = -amount
+	}
+	_, err := tx.Exec(`
+		INSERT INTO transactions (user_id, type, amount, description, created_at)
+		VALUES (?, ?, ?, ?, datetime('now'))`, userID, ttype, amount, description)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`UPDATE user_stats SET balance = balance + ?, updated_at = datetime('now') WHERE user_id = ?`, delta, userID)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, errors.New("user stats not found")
+	}
+	return balanceTx(tx, userID)
+}
+
+func balanceTx(tx *sql.Tx, userID int64) (float64, error) {
+	var balance float64
+	err := tx.QueryRow(`SELECT balance FROM user_stats WHERE user_id = ?`, userID).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("user stats not found")
+	}
+	return balance, err
+}
@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/testutil"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func ledger(t *testing.T, userID int64) (count int, sum float64) {
+	t.Helper()
+	err := config.DB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM transactions WHERE user_id = ?`, userID).
+		Scan(&count, &sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count, sum
+}
+
+func TestCreditAndDebit(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 0)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if balance, err := Credit(tx, user, 100, "deposit", "Depósito"); err != nil || balance != 100 {
+		t.Fatalf("Credit = %v, %v; want 100", balance, err)
+	}
+	if balance, err := Debit(tx, user, 30, "bet", "Aposta"); err != nil || balance != 70 {
+		t.Fatalf("Debit = %v, %v; want 70", balance, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.Balance(t, user); got != 70 {
+		t.Fatalf("balance = %v, want 70", got)
+	}
+	count, sum := ledger(t, user)
+	if count != 2 || sum != 130 {
+		t.Fatalf("ledger = %d rows summing %v, want 2 rows with positive amounts summing 130", count, sum)
+	}
+}
+
+func TestDebitInsufficientFunds(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 20)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := Debit(tx, user, 20.01, "bet", "Aposta"); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("Debit error = %v, want ErrInsufficientFunds", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.Balance(t, user); got != 20 {
+		t.Fatalf("balance = %v, want 20", got)
+	}
+	if count, _ := ledger(t, user); count != 0 {
+		t.Fatalf("ledger has %d rows, want none", count)
+	}
+}
+
+func TestRejectsNonPositiveAmounts(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 20)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	for _, amount := range []float64{0, -5} {
+		if _, err := Credit(tx, user, amount, "deposit", ""); err == nil {
+			t.Errorf("Credit(%v) succeeded", amount)
+		}
+		if _, err := Debit(tx, user, amount, "bet", ""); err == nil {
+			t.Errorf("Debit(%v) succeeded", amount)
+		}
+	}
+}
+
+// Ledger e saldo são gravados na transação do chamador: o rollback desfaz os dois.
+func TestRollbackUndoesLedgerAndBalance(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 50)
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Debit(tx, user, 50, "withdraw", "Saque"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.Balance(t, user); got != 50 {
+		t.Fatalf("balance = %v, want 50", got)
+	}
+	if count, _ := ledger(t, user); count != 0 {
+		t.Fatalf("ledger has %d rows, want none", count)
+	}
+}
+
+// Débitos concorrentes não podem gastar o mesmo saldo duas vezes.
+func TestConcurrentDebitsNeverOverdraw(t *testing.T) {
+	testutil.DB(t)
+	user := testutil.User(t, "ana", 100)
+
+	const workers = 10
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := config.DB.Begin()
+			if err != nil {
+				return
+			}
+			defer tx.Rollback()
+			if _, err := Debit(tx, user, 30, "bet", "Aposta"); err != nil {
+				return
+			}
+			if tx.Commit() == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	balance := testutil.Balance(t, user)
+	if balance < 0 {
+		t.Fatalf("balance went negative: %v", balance)
+	}
+	count, sum := ledger(t, user)
+	if count != succeeded || balance != 100-sum {
+		t.Fatalf("%d debits committed, ledger has %d rows summing %v and balance is %v", succeeded, count, sum, balance)
+	}
+	if succeeded == 0 || succeeded > 3 {
+		t.Fatalf("%d debits of 30 committed against a balance of 100", succeeded)
+	}
+}
+
+func TestApplySign(t *testing.T) {
+	cases := map[string]float64{
+		"deposit": 10, "win": 10, "bonus": 10, "tournament_prize": 10, "tournament_refund": 10,
+		"adjustment_credit": 10, "withdraw_refund": 10,
+		"bet": -10, "withdraw": -10, "tournament_fee": -10, "adjustment_debit": -10,
+		"unknown": 0,
+	}
+	for ttype, want := range cases {
+		if got := ApplySign(ttype, 10); got != want {
+			t.Errorf("ApplySign(%q, 10) = %v, want %v", ttype, got, want)
+		}
+	}
+}
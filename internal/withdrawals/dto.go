@@ -0,0 +1,13 @@
+package withdrawals
+
+type WithdrawRequest struct {
+	Amount float64 `json:"amount"`
+	// Destination é a chave PIX; vazia usa o CPF do jogador.
+	Destination string `json:"destination"`
+	// ForfeitBonus confirma que os bônus em andamento serão perdidos.
+	ForfeitBonus bool `json:"forfeit_bonus"`
+}
+
+type RejectRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
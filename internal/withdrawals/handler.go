@@ -0,0 +1,174 @@
+package withdrawals
+
+import (
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/utils"
+	"berry_bet/internal/wallet"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondWithdrawalError(c *gin.Context, err error) {
+	var rejected *RejectedError
+	switch {
+	case errors.As(err, &rejected):
+		utils.RespondError(c, http.StatusUnprocessableEntity, "WITHDRAWAL_REJECTED", "Withdrawal rejected by automatic checks.", rejected.Withdrawal)
+	case errors.Is(err, ErrInvalidAmount):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_AMOUNT", "Amount must be greater than zero, in cents.", nil)
+	case errors.Is(err, ErrInvalidDestination):
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_DESTINATION", "Destination must be your own CPF, email or phone PIX key.", nil)
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		utils.RespondError(c, http.StatusBadRequest, "INSUFFICIENT_FUNDS", "Insufficient balance.", nil)
+	case errors.Is(err, ErrWithdrawalNotFound):
+		utils.RespondError(c, http.StatusNotFound, "NOT_FOUND", "Withdrawal not found.", nil)
+	case errors.Is(err, ErrInvalidState):
+		utils.RespondError(c, http.StatusConflict, "INVALID_WITHDRAWAL_STATUS", "Operation not allowed in the current withdrawal status.", nil)
+	case errors.Is(err, ErrReasonRequired):
+		utils.RespondError(c, http.StatusBadRequest, "REASON_REQUIRED", "A rejection reason of at least 5 characters is required.", nil)
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, "DB_ERROR", "Failed to process withdrawal.", err.Error())
+	}
+}
+
+func parsePaging(c *gin.Context, defLimit int) (int, int) {
+	page, limit := 1, defLimit
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	return page, limit
+}
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ID.", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// RequestWithdrawalHandler requests a withdrawal for the authenticated user.
+// The amount is held from the balance while the request is reviewed and paid,
+// and returned if it is rejected or the payout fails. Accounts with 2FA must
+// send a fresh code (see twofactor.RequireFreshCode on the route).
+func RequestWithdrawalHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	var req WithdrawRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid data.", err.Error())
+		return
+	}
+	w, err := Request(audit.ActorFromContext(c), req.Amount, req.Destination, req.ForfeitBonus)
+	if errors.Is(err, ErrBonusActive) {
+		summary, _ := bonus.GetSummary(userID)
+		utils.RespondError(c, http.StatusConflict, "BONUS_ACTIVE", "Withdrawing now forfeits your active bonuses. Send forfeit_bonus to confirm.", summary)
+		return
+	}
+	if err != nil {
+		respondWithdrawalError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, w, "Withdrawal requested")
+}
+
+// GetMyWithdrawalsHandler lists the caller's withdrawals, newest first.
+func GetMyWithdrawalsHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	page, limit := parsePaging(c, 20)
+	list, err := GetWithdrawalsByUserID(userID, page, limit)
+	if err != nil {
+		respondWithdrawalError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, list, "Withdrawals found")
+}
+
+// GetMyWithdrawalHandler returns one of the caller's withdrawals.
+func GetMyWithdrawalHandler(c *gin.Context) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	w, err := GetWithdrawal(id)
+	if err == nil && w.UserID != userID {
+		err = ErrWithdrawalNotFound
+	}
+	if err != nil {
+		respondWithdrawalError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, w, "Withdrawal found")
+}
+
+// ListWithdrawalsHandler lists withdrawals for admins, oldest first.
+// ?status defaults to pending_approval; "all" lists every withdrawal.
+func ListWithdrawalsHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", StatusPendingApproval)
+	switch status {
+	case "all":
+		status = ""
+	case StatusPendingApproval, StatusApproved, StatusProcessing, StatusPaid, StatusRejected, StatusFailed:
+	default:
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_STATUS", "Invalid status.", nil)
+		return
+	}
+	page, limit := parsePaging(c, 50)
+	list, total, err := ListWithdrawals(status, page, limit)
+	if err != nil {
+		respondWithdrawalError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, gin.H{"withdrawals": list, "total": total, "page": page, "limit": limit}, "Withdrawals found")
+}
+
+// ApproveWithdrawalHandler approves a withdrawal waiting for review and sends
+// it to the payout provider.
+func ApproveWithdrawalHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	w, err := Approve(audit.ActorFromContext(c), id)
+	if err != nil {
+		respondWithdrawalError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, w, "Withdrawal approved.")
+}
+
+// RejectWithdrawalHandler rejects a withdrawal waiting for review and
+// releases the held amount.
+func RejectWithdrawalHandler(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "INVALID_INPUT", "Invalid input.", err.Error())
+		return
+	}
+	w, err := Reject(audit.ActorFromContext(c), id, req.Reason)
+	if err != nil {
+		respondWithdrawalError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, w, "Withdrawal rejected.")
+}
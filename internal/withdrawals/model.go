@@ -0,0 +1,190 @@
+package withdrawals
+
+import (
+	"berry_bet/config"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Withdrawal é um pedido de saque.
+type Withdrawal struct {
+	ID              int64   `json:"id"`
+	UserID          int64   `json:"user_id"`
+	Amount          float64 `json:"amount"`
+	Status          string  `json:"status"`
+	Destination     string  `json:"destination"`
+	Checks          []Check `json:"checks"`
+	Provider        *string `json:"provider"`
+	ProviderRef     *string `json:"provider_ref"`
+	RejectionReason *string `json:"rejection_reason"`
+	ReviewedBy      *int64  `json:"reviewed_by"`
+	ReviewedAt      *string `json:"reviewed_at"`
+	PaidAt          *string `json:"paid_at"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// Check é o resultado de uma verificação automática do pedido.
+type Check struct {
+	Name   string `json:"name"`
+	Result string `json:"result"` // pass, review ou fail
+	Detail string `json:"detail,omitempty"`
+}
+
+const withdrawalColumns = `id, user_id, amount, status, destination, checks, provider, provider_ref, rejection_reason,
+	reviewed_by, CAST(reviewed_at AS TEXT), CAST(paid_at AS TEXT), CAST(created_at AS TEXT), CAST(updated_at AS TEXT)`
+
+func scanWithdrawal(row interface{ Scan(dest ...any) error }) (Withdrawal, error) {
+	var w Withdrawal
+	var checks string
+	var provider, ref, reason, reviewedAt, paidAt sql.NullString
+	var reviewedBy sql.NullInt64
+	err := row.Scan(&w.ID, &w.UserID, &w.Amount, &w.Status, &w.Destination, &checks, &provider, &ref, &reason,
+		&reviewedBy, &reviewedAt, &paidAt, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return w, err
+	}
+	w.Provider = nullString(provider)
+	w.ProviderRef = nullString(ref)
+	w.RejectionReason = nullString(reason)
+	w.ReviewedAt = nullString(reviewedAt)
+	w.PaidAt = nullString(paidAt)
+	if reviewedBy.Valid {
+		w.ReviewedBy = &reviewedBy.Int64
+	}
+	if err := json.Unmarshal([]byte(checks), &w.Checks); err != nil {
+		w.Checks = []Check{}
+	}
+	return w, nil
+}
+
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// GetWithdrawal devolve o saque pelo id.
+func GetWithdrawal(id int64) (Withdrawal, error) {
+	w, err := scanWithdrawal(config.DB.QueryRow(`SELECT `+withdrawalColumns+` FROM withdrawals WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return w, ErrWithdrawalNotFound
+	}
+	return w, err
+}
+
+func listWithdrawals(query string, args ...any) ([]Withdrawal, error) {
+	rows, err := config.DB.Query(`SELECT `+withdrawalColumns+` FROM withdrawals `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []Withdrawal{}
+	for rows.Next() {
+		w, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
+
+// GetWithdrawalsByUserID lista os saques do jogador, os mais recentes primeiro.
+func GetWithdrawalsByUserID(userID int64, page, limit int) ([]Withdrawal, error) {
+	return listWithdrawals(`WHERE user_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, userID, limit, (page-1)*limit)
+}
+
+// ListWithdrawals lista os saques com o status informado ("" para todos), os
+// mais antigos primeiro, para a fila de aprovação.
+func ListWithdrawals(status string, page, limit int) ([]Withdrawal, int, error) {
+	var total int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM withdrawals WHERE ? = '' OR status = ?`, status, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	list, err := listWithdrawals(`WHERE ? = '' OR status = ? ORDER BY created_at, id LIMIT ? OFFSET ?`, status, status, limit, (page-1)*limit)
+	return list, total, err
+}
+
+// approvedIDs devolve os saques aprovados que aguardam pagamento.
+func approvedIDs() ([]int64, error) {
+	return withdrawalIDs(`WHERE status = 'approved' ORDER BY id`)
+}
+
+// stuckProcessingIDs devolve os saques em processing sem atualização há mais
+// de age (o processo caiu durante o envio ao provedor, por exemplo).
+func stuckProcessingIDs(age time.Duration) ([]int64, error) {
+	return withdrawalIDs(`WHERE status = 'processing' AND updated_at <= datetime('now', ?) ORDER BY id`,
+		fmt.Sprintf("-%d seconds", int64(age.Seconds())))
+}
+
+func withdrawalIDs(query string, args ...any) ([]int64, error) {
+	rows, err := config.DB.Query(`SELECT id FROM withdrawals `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// transition muda o status do saque apenas se ele ainda estiver em from,
+// atualizando também as colunas de set (com os valores de args).
+func transition(db execer, id int64, from, to string, set string, args ...any) (bool, error) {
+	query := `UPDATE withdrawals SET status = ?, updated_at = datetime('now')`
+	if set != "" {
+		query += `, ` + set
+	}
+	query += ` WHERE id = ? AND status = ?`
+	params := append([]any{to}, args...)
+	params = append(params, id, from)
+	res, err := db.Exec(query, params...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// rolloverUsage soma depósitos e apostas desde o último saque pago.
+func rolloverUsage(userID int64) (deposited, wagered float64, err error) {
+	err = config.DB.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN type = 'deposit' THEN ABS(amount) END), 0),
+			COALESCE(SUM(CASE WHEN type = 'bet' THEN ABS(amount) END), 0)
+		FROM transactions
+		WHERE user_id = ? AND created_at > COALESCE(
+			(SELECT MAX(paid_at) FROM withdrawals WHERE user_id = ? AND status = 'paid'), '')`,
+		userID, userID).Scan(&deposited, &wagered)
+	return
+}
+
+// destinationKeys devolve as chaves PIX aceitas como destino: CPF, e-mail e
+// telefone do próprio jogador.
+func destinationKeys(userID int64) ([]string, error) {
+	var cpf, email string
+	var phone sql.NullString
+	err := config.DB.QueryRow(`SELECT cpf, email, phone FROM users WHERE id = ?`, userID).Scan(&cpf, &email, &phone)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{cpf, email}
+	if phone.Valid && phone.String != "" {
+		keys = append(keys, phone.String)
+	}
+	return keys, nil
+}
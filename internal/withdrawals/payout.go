@@ -0,0 +1,66 @@
+package withdrawals
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// PayoutProvider envia o dinheiro de um saque aprovado ao jogador.
+type PayoutProvider interface {
+	Name() string
+	// Payout transfere o valor ao destino e devolve a referência do provedor.
+	// Recusas definitivas devem embrulhar ErrPayoutDeclined; outros erros são
+	// tratados como temporários e o pagamento é tentado de novo. O mesmo saque
+	// pode ser reenviado (falha temporária ou retomada de processing), então o
+	// envio deve ser idempotente pelo w.ID: repetir devolve a mesma referência.
+	Payout(w Withdrawal) (string, error)
+}
+
+var ErrPayoutDeclined = errors.New("payout declined by provider")
+
+var payoutProvider PayoutProvider
+
+// Configure escolhe o provedor de pagamentos de saque pela variável
+// PAYOUT_PROVIDER. A variável é obrigatória: sem ela o servidor não sobe, para
+// que o stub nunca seja usado por acidente.
+func Configure() error {
+	switch name := os.Getenv("PAYOUT_PROVIDER"); name {
+	case "":
+		return errors.New("PAYOUT_PROVIDER is not set")
+	case "pix_stub":
+		payoutProvider = &PixPayoutStub{Decline: os.Getenv("PAYOUT_STUB_MODE") == "decline"}
+	default:
+		return fmt.Errorf("PAYOUT_PROVIDER %q: unknown payout provider", name)
+	}
+	return nil
+}
+
+// PixPayoutStub emula transferências PIX sem movimentar dinheiro. Com
+// Decline (PAYOUT_STUB_MODE=decline), recusa todos os pagamentos, para
+// exercitar a devolução do valor retido.
+type PixPayoutStub struct {
+	Decline bool
+
+	refs sync.Map // ID do saque -> referência já emitida
+}
+
+func (p *PixPayoutStub) Name() string { return "pix_stub" }
+
+func (p *PixPayoutStub) Payout(w Withdrawal) (string, error) {
+	if p.Decline {
+		return "", fmt.Errorf("%w: destination account rejected the transfer", ErrPayoutDeclined)
+	}
+	if ref, ok := p.refs.Load(w.ID); ok {
+		return ref.(string), nil
+	}
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	ref, _ := p.refs.LoadOrStore(w.ID, "E2E"+hex.EncodeToString(b))
+	return ref.(string), nil
+}
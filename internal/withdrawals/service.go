@@ -0,0 +1,470 @@
+package withdrawals
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/kyc"
+	"berry_bet/internal/notifications"
+	"berry_bet/internal/risk"
+	"berry_bet/internal/wallet"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusPendingApproval = "pending_approval"
+	StatusApproved        = "approved"
+	StatusProcessing      = "processing"
+	StatusPaid            = "paid"
+	StatusRejected        = "rejected"
+	StatusFailed          = "failed"
+)
+
+const (
+	CheckPass   = "pass"
+	CheckReview = "review"
+	CheckFail   = "fail"
+)
+
+const (
+	// DefaultApprovalThreshold é o valor a partir do qual o saque precisa de
+	// aprovação manual, quando WITHDRAWAL_APPROVAL_THRESHOLD não está definido.
+	DefaultApprovalThreshold = 1000.0
+	// DefaultRollover é quantas vezes o valor depositado precisa ser apostado
+	// antes do saque, quando WITHDRAWAL_ROLLOVER não está definido.
+	DefaultRollover = 1.0
+	// DefaultProcessingTimeout é quanto um saque pode ficar em processing
+	// antes de voltar para a fila, quando WITHDRAWAL_PROCESSING_TIMEOUT não
+	// está definido.
+	DefaultProcessingTimeout = 15 * time.Minute
+)
+
+var (
+	ErrWithdrawalNotFound = errors.New("withdrawal not found")
+	ErrInvalidAmount      = errors.New("invalid withdrawal amount")
+	ErrInvalidDestination = errors.New("destination must be the player's own CPF, email or phone")
+	ErrInvalidState       = errors.New("operation not allowed in the current withdrawal status")
+	ErrReasonRequired     = errors.New("a rejection reason is required")
+	ErrBonusActive        = errors.New("player has an active bonus")
+)
+
+// RejectedError indica que o pedido foi registrado, mas recusado pelas
+// verificações automáticas.
+type RejectedError struct {
+	Withdrawal Withdrawal
+}
+
+func (e *RejectedError) Error() string {
+	return "withdrawal rejected by automatic checks"
+}
+
+func envFloat(name string, def float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil && v >= 0 {
+		return v
+	}
+	return def
+}
+
+// ApprovalThreshold lê WITHDRAWAL_APPROVAL_THRESHOLD; 0 exige aprovação manual
+// de todos os saques.
+func ApprovalThreshold() float64 {
+	return envFloat("WITHDRAWAL_APPROVAL_THRESHOLD", DefaultApprovalThreshold)
+}
+
+// Rollover lê WITHDRAWAL_ROLLOVER; 0 desativa a exigência.
+func Rollover() float64 {
+	return envFloat("WITHDRAWAL_ROLLOVER", DefaultRollover)
+}
+
+// ProcessingTimeout lê WITHDRAWAL_PROCESSING_TIMEOUT (duração, ex.: "15m").
+func ProcessingTimeout() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("WITHDRAWAL_PROCESSING_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return DefaultProcessingTimeout
+}
+
+// runChecks executa as verificações automáticas do pedido.
+func runChecks(userID int64, amount float64) ([]Check, error) {
+	var checks []Check
+
+	kycCheck := Check{Name: "kyc", Result: CheckPass}
+	if err := kyc.CheckVerified(userID); err != nil {
+		var reqErr *kyc.RequiredError
+		if !errors.As(err, &reqErr) {
+			return nil, err
+		}
+		kycCheck = Check{Name: "kyc", Result: CheckFail, Detail: "identity not verified (" + reqErr.Status + ")"}
+	}
+	checks = append(checks, kycCheck)
+
+	deposited, wagered, err := rolloverUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	rollover := Check{Name: "rollover", Result: CheckPass}
+	if remaining := deposited*Rollover() - wagered; remaining > 0.005 {
+		rollover = Check{Name: "rollover", Result: CheckFail,
+			Detail: fmt.Sprintf("R$ %.2f of deposits still need to be wagered", remaining)}
+	}
+	checks = append(checks, rollover)
+
+	review, reason, err := risk.NeedsManualReview(userID)
+	if err != nil {
+		return nil, err
+	}
+	riskCheck := Check{Name: "risk", Result: CheckPass}
+	if review {
+		riskCheck = Check{Name: "risk", Result: CheckReview, Detail: reason}
+	}
+	checks = append(checks, riskCheck)
+
+	amountCheck := Check{Name: "amount", Result: CheckPass}
+	if amount >= ApprovalThreshold() {
+		amountCheck = Check{Name: "amount", Result: CheckReview,
+			Detail: fmt.Sprintf("amount at or above R$ %.2f requires manual approval", ApprovalThreshold())}
+	}
+	checks = append(checks, amountCheck)
+	return checks, nil
+}
+
+func checksResult(checks []Check) string {
+	result := CheckPass
+	for _, c := range checks {
+		if c.Result == CheckFail {
+			return CheckFail
+		}
+		if c.Result == CheckReview {
+			result = CheckReview
+		}
+	}
+	return result
+}
+
+// resolveDestination confere a chave PIX de destino; vazia usa o CPF.
+func resolveDestination(userID int64, destination string) (string, error) {
+	keys, err := destinationKeys(userID)
+	if err != nil {
+		return "", err
+	}
+	destination = strings.TrimSpace(destination)
+	if destination == "" {
+		return keys[0], nil
+	}
+	for _, k := range keys {
+		if strings.EqualFold(k, destination) {
+			return k, nil
+		}
+	}
+	return "", ErrInvalidDestination
+}
+
+// Request registra o pedido de saque. Se as verificações automáticas
+// recusarem, o pedido fica rejeitado sem mexer no saldo e *RejectedError é
+// devolvido. Caso contrário o valor fica retido e o pedido segue para
+// aprovação manual ou direto para pagamento.
+func Request(actor audit.Actor, amount float64, destination string, forfeitBonus bool) (Withdrawal, error) {
+	userID := actor.UserID
+	if amount <= 0 || math.Round(amount*100) != amount*100 {
+		return Withdrawal{}, ErrInvalidAmount
+	}
+	if !forfeitBonus {
+		active, err := bonus.HasActive(userID)
+		if err != nil {
+			return Withdrawal{}, err
+		}
+		if active {
+			return Withdrawal{}, ErrBonusActive
+		}
+	}
+	dest, err := resolveDestination(userID, destination)
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	checks, err := runChecks(userID, amount)
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	result := checksResult(checks)
+	status, reason := StatusApproved, ""
+	switch result {
+	case CheckFail:
+		status, reason = StatusRejected, "automatic_checks"
+	case CheckReview:
+		status = StatusPendingApproval
+	}
+	checksJSON, err := json.Marshal(checks)
+	if err != nil {
+		return Withdrawal{}, err
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`
+		INSERT INTO withdrawals (user_id, amount, status, destination, checks, rejection_reason, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), datetime('now'), datetime('now'))`,
+		userID, amount, status, dest, string(checksJSON), reason)
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	if status != StatusRejected {
+		if _, err := wallet.Debit(tx, userID, amount, "withdraw", fmt.Sprintf("Saque #%d", id)); err != nil {
+			return Withdrawal{}, err
+		}
+		// Sacar antes de cumprir o rollover cancela os bônus em andamento.
+		if _, err := bonus.ForfeitActiveTx(tx, actor, userID, "withdrawal"); err != nil {
+			return Withdrawal{}, err
+		}
+	}
+	action := "withdrawal.requested"
+	if status == StatusRejected {
+		action = "withdrawal.rejected"
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     action,
+		TargetType: "withdrawal",
+		TargetID:   id,
+		Details:    map[string]any{"amount": amount, "status": status, "checks": checks},
+	})
+	if err != nil {
+		return Withdrawal{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Withdrawal{}, err
+	}
+
+	if status == StatusApproved {
+		processPayout(id)
+	}
+	w, err := GetWithdrawal(id)
+	if err != nil {
+		return w, err
+	}
+	if status == StatusRejected {
+		return w, &RejectedError{Withdrawal: w}
+	}
+	return w, nil
+}
+
+// Approve aprova um saque que aguardava revisão e o envia para pagamento.
+func Approve(actor audit.Actor, id int64) (Withdrawal, error) {
+	w, err := GetWithdrawal(id)
+	if err != nil {
+		return w, err
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return w, err
+	}
+	defer tx.Rollback()
+	ok, err := transition(tx, id, StatusPendingApproval, StatusApproved,
+		`reviewed_by = ?, reviewed_at = datetime('now')`, actor.UserID)
+	if err != nil {
+		return w, err
+	}
+	if !ok {
+		return w, ErrInvalidState
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     "withdrawal.approved",
+		TargetType: "withdrawal",
+		TargetID:   id,
+		Details:    map[string]any{"user_id": w.UserID, "amount": w.Amount},
+	})
+	if err != nil {
+		return w, err
+	}
+	if err := tx.Commit(); err != nil {
+		return w, err
+	}
+	processPayout(id)
+	return GetWithdrawal(id)
+}
+
+// Reject recusa um saque que aguardava revisão e devolve o valor retido.
+func Reject(actor audit.Actor, id int64, reason string) (Withdrawal, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) < 5 {
+		return Withdrawal{}, ErrReasonRequired
+	}
+	w, err := GetWithdrawal(id)
+	if err != nil {
+		return w, err
+	}
+	if err := release(actor, w, StatusPendingApproval, StatusRejected, reason, actor.UserID); err != nil {
+		return w, err
+	}
+	notifications.Notify(w.UserID, "withdrawal", "Saque recusado",
+		fmt.Sprintf("Seu saque de R$ %.2f foi recusado: %s. O valor voltou para o seu saldo.", w.Amount, reason))
+	return GetWithdrawal(id)
+}
+
+// release finaliza o saque em to (rejected ou failed) e devolve o valor retido
+// ao saldo, na mesma transação.
+func release(actor audit.Actor, w Withdrawal, from, to, reason string, reviewer int64) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	set, args := `rejection_reason = ?`, []any{reason}
+	if reviewer != 0 {
+		set += `, reviewed_by = ?, reviewed_at = datetime('now')`
+		args = append(args, reviewer)
+	}
+	ok, err := transition(tx, w.ID, from, to, set, args...)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidState
+	}
+	if _, err := wallet.Credit(tx, w.UserID, w.Amount, "withdraw_refund", fmt.Sprintf("Estorno do saque #%d", w.ID)); err != nil {
+		return err
+	}
+	err = audit.RecordTx(tx, audit.Entry{
+		Actor:      actor,
+		Action:     "withdrawal." + to,
+		TargetType: "withdrawal",
+		TargetID:   w.ID,
+		Details:    map[string]any{"user_id": w.UserID, "amount": w.Amount, "reason": reason},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// processPayout envia um saque aprovado ao provedor. O saque passa para
+// processing antes da chamada, então só um processo o envia. Erros temporários
+// o devolvem para approved, para nova tentativa pelo worker.
+func processPayout(id int64) {
+	if payoutProvider == nil {
+		log.Printf("Saque %d aprovado, mas nenhum provedor de pagamento está configurado", id)
+		return
+	}
+	ok, err := transition(config.DB, id, StatusApproved, StatusProcessing, `provider = ?`, payoutProvider.Name())
+	if err != nil || !ok {
+		if err != nil {
+			log.Printf("Erro ao iniciar pagamento do saque %d: %v", id, err)
+		}
+		return
+	}
+	w, err := GetWithdrawal(id)
+	if err != nil {
+		log.Printf("Erro ao carregar saque %d: %v", id, err)
+		return
+	}
+
+	ref, err := payoutProvider.Payout(w)
+	switch {
+	case err == nil:
+		ok, err = transition(config.DB, id, StatusProcessing, StatusPaid, `provider_ref = ?, paid_at = datetime('now')`, ref)
+		if err != nil || !ok {
+			log.Printf("Saque %d pago (ref %s), mas o status não foi atualizado: %v", id, ref, err)
+			return
+		}
+		if err := audit.Record(audit.Entry{
+			Actor:      audit.System,
+			Action:     "withdrawal.paid",
+			TargetType: "withdrawal",
+			TargetID:   id,
+			Details:    map[string]any{"user_id": w.UserID, "amount": w.Amount, "provider_ref": ref},
+		}); err != nil {
+			log.Printf("Erro ao auditar pagamento do saque %d: %v", id, err)
+		}
+		notifications.Notify(w.UserID, "withdrawal", "Saque pago",
+			fmt.Sprintf("Seu saque de R$ %.2f foi enviado para %s.", w.Amount, w.Destination))
+	case errors.Is(err, ErrPayoutDeclined):
+		if err := release(audit.System, w, StatusProcessing, StatusFailed, err.Error(), 0); err != nil {
+			log.Printf("Erro ao devolver o valor do saque %d recusado: %v", id, err)
+			return
+		}
+		notifications.Notify(w.UserID, "withdrawal", "Saque não concluído",
+			fmt.Sprintf("Não conseguimos enviar seu saque de R$ %.2f. O valor voltou para o seu saldo.", w.Amount))
+	default:
+		log.Printf("Erro temporário no pagamento do saque %d: %v", id, err)
+		if _, err := transition(config.DB, id, StatusProcessing, StatusApproved, ""); err != nil {
+			log.Printf("Erro ao devolver o saque %d para a fila: %v", id, err)
+		}
+	}
+}
+
+// ProcessApproved tenta pagar os saques aprovados que ainda aguardam pagamento.
+func ProcessApproved() (int, error) {
+	ids, err := approvedIDs()
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		processPayout(id)
+	}
+	return len(ids), nil
+}
+
+// RecoverStuck devolve para approved os saques presos em processing há mais
+// de ProcessingTimeout, para nova tentativa. O provedor recebe o mesmo saque
+// (e o mesmo ID) de novo, então um pagamento já feito não é duplicado; uma
+// recusa definitiva libera o valor retido pelo caminho normal.
+func RecoverStuck() (int, error) {
+	ids, err := stuckProcessingIDs(ProcessingTimeout())
+	if err != nil {
+		return 0, err
+	}
+	recovered := 0
+	for _, id := range ids {
+		ok, err := transition(config.DB, id, StatusProcessing, StatusApproved, "")
+		if err != nil {
+			return recovered, err
+		}
+		if !ok {
+			continue
+		}
+		recovered++
+		log.Printf("Saque %d estava preso em processing; devolvido para a fila", id)
+		if err := audit.Record(audit.Entry{
+			Actor:      audit.System,
+			Action:     "withdrawal.requeued",
+			TargetType: "withdrawal",
+			TargetID:   id,
+			Details:    map[string]any{"reason": "processing_timeout"},
+		}); err != nil {
+			log.Printf("Erro ao auditar retomada do saque %d: %v", id, err)
+		}
+	}
+	return recovered, nil
+}
+
+// StartWorker tenta periodicamente os pagamentos pendentes, retomando antes
+// os que ficaram presos em processing.
+func StartWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := RecoverStuck(); err != nil {
+				log.Printf("Erro ao retomar saques presos em processamento: %v", err)
+			}
+			if _, err := ProcessApproved(); err != nil {
+				log.Printf("Erro ao processar saques aprovados: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,192 @@
+package withdrawals
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"berry_bet/internal/testutil"
+	"berry_bet/internal/wallet"
+	"errors"
+	"testing"
+)
+
+// setup cria um banco com um jogador verificado e saldo inicial. O rollover
+// fica desligado e o provedor de saques é o informado (nil = nenhum).
+func setup(t *testing.T, balance float64, provider PayoutProvider) int64 {
+	t.Helper()
+	testutil.DB(t)
+	t.Setenv("WITHDRAWAL_ROLLOVER", "0")
+	t.Setenv("WITHDRAWAL_APPROVAL_THRESHOLD", "1000")
+	previous := payoutProvider
+	payoutProvider = provider
+	t.Cleanup(func() { payoutProvider = previous })
+	return testutil.User(t, "ana", balance)
+}
+
+func ledgerTotal(t *testing.T, userID int64, ttype string) float64 {
+	t.Helper()
+	var sum float64
+	err := config.DB.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE user_id = ? AND type = ?`, userID, ttype).Scan(&sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sum
+}
+
+func TestRequestHoldsAmountAndPays(t *testing.T) {
+	user := setup(t, 100, &PixPayoutStub{})
+
+	w, err := Request(audit.Actor{UserID: user}, 40, "", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if w.Status != StatusPaid || w.ProviderRef == nil {
+		t.Fatalf("withdrawal = %+v, want paid with a provider reference", w)
+	}
+	if got := testutil.Balance(t, user); got != 60 {
+		t.Fatalf("balance = %v, want 60", got)
+	}
+	if got := ledgerTotal(t, user, "withdraw"); got != 40 {
+		t.Fatalf("withdraw entries = %v, want 40", got)
+	}
+}
+
+func TestRequestWithoutFundsLeavesNothing(t *testing.T) {
+	user := setup(t, 10, &PixPayoutStub{})
+
+	if _, err := Request(audit.Actor{UserID: user}, 40, "", false); !errors.Is(err, wallet.ErrInsufficientFunds) {
+		t.Fatalf("Request error = %v, want ErrInsufficientFunds", err)
+	}
+	var count int
+	if err := config.DB.QueryRow(`SELECT COUNT(*) FROM withdrawals`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 || testutil.Balance(t, user) != 10 {
+		t.Fatalf("%d withdrawals and balance %v after a failed request", count, testutil.Balance(t, user))
+	}
+}
+
+func TestRejectReleasesHold(t *testing.T) {
+	user := setup(t, 100, &PixPayoutStub{})
+	t.Setenv("WITHDRAWAL_APPROVAL_THRESHOLD", "0") // tudo passa por aprovação manual
+	admin := testutil.User(t, "admin", 0)
+
+	w, err := Request(audit.Actor{UserID: user}, 70, "", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if w.Status != StatusPendingApproval {
+		t.Fatalf("status = %q, want %q", w.Status, StatusPendingApproval)
+	}
+	if got := testutil.Balance(t, user); got != 30 {
+		t.Fatalf("balance while pending = %v, want 30", got)
+	}
+
+	if _, err := Reject(audit.Actor{UserID: admin}, w.ID, "no"); !errors.Is(err, ErrReasonRequired) {
+		t.Fatalf("Reject with short reason error = %v, want ErrReasonRequired", err)
+	}
+	w, err = Reject(audit.Actor{UserID: admin}, w.ID, "documento divergente")
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if w.Status != StatusRejected {
+		t.Fatalf("status = %q, want %q", w.Status, StatusRejected)
+	}
+	if got := testutil.Balance(t, user); got != 100 {
+		t.Fatalf("balance after reject = %v, want 100", got)
+	}
+	if got := ledgerTotal(t, user, "withdraw_refund"); got != 70 {
+		t.Fatalf("refund entries = %v, want 70", got)
+	}
+
+	// Um saque já finalizado não pode ser recusado de novo (nem estornado duas vezes)
+	if _, err := Reject(audit.Actor{UserID: admin}, w.ID, "documento divergente"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("second Reject error = %v, want ErrInvalidState", err)
+	}
+	if got := testutil.Balance(t, user); got != 100 {
+		t.Fatalf("balance after second reject = %v, want 100", got)
+	}
+}
+
+func TestDeclinedPayoutRefunds(t *testing.T) {
+	user := setup(t, 100, &PixPayoutStub{Decline: true})
+
+	w, err := Request(audit.Actor{UserID: user}, 25, "", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if w.Status != StatusFailed {
+		t.Fatalf("status = %q, want %q", w.Status, StatusFailed)
+	}
+	if got := testutil.Balance(t, user); got != 100 {
+		t.Fatalf("balance = %v, want 100", got)
+	}
+}
+
+func TestRecoverStuckRetriesIdempotently(t *testing.T) {
+	stub := &PixPayoutStub{}
+	user := setup(t, 100, stub)
+
+	w, err := Request(audit.Actor{UserID: user}, 10, "", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	firstRef := *w.ProviderRef
+
+	// Simula uma queda depois do envio ao provedor, antes de gravar o pagamento
+	_, err = config.DB.Exec(`UPDATE withdrawals SET status = 'processing', provider_ref = NULL, paid_at = NULL,
+		updated_at = datetime('now', '-1 hour') WHERE id = ?`, w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := RecoverStuck()
+	if err != nil || n != 1 {
+		t.Fatalf("RecoverStuck = %d, %v; want 1", n, err)
+	}
+	if _, err := ProcessApproved(); err != nil {
+		t.Fatal(err)
+	}
+	w, err = GetWithdrawal(w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != StatusPaid || *w.ProviderRef != firstRef {
+		t.Fatalf("withdrawal = %s ref %v, want paid again with ref %s", w.Status, *w.ProviderRef, firstRef)
+	}
+	if got := testutil.Balance(t, user); got != 90 {
+		t.Fatalf("balance = %v, want 90", got)
+	}
+}
+
+func TestRecoverStuckIgnoresRecentProcessing(t *testing.T) {
+	user := setup(t, 100, nil)
+
+	w, err := Request(audit.Actor{UserID: user}, 10, "", false)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if _, err := config.DB.Exec(`UPDATE withdrawals SET status = 'processing', updated_at = datetime('now') WHERE id = ?`, w.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := RecoverStuck(); err != nil || n != 0 {
+		t.Fatalf("RecoverStuck = %d, %v; want 0", n, err)
+	}
+}
+
+func TestConfigureRequiresProvider(t *testing.T) {
+	previous := payoutProvider
+	t.Cleanup(func() { payoutProvider = previous })
+
+	payoutProvider = nil
+	t.Setenv("PAYOUT_PROVIDER", "")
+	if err := Configure(); err == nil || payoutProvider != nil {
+		t.Fatalf("Configure without PAYOUT_PROVIDER: error = %v, provider = %v; want an error and no provider", err, payoutProvider)
+	}
+	t.Setenv("PAYOUT_PROVIDER", "unknown")
+	if err := Configure(); err == nil {
+		t.Fatal("Configure accepted an unknown provider")
+	}
+	t.Setenv("PAYOUT_PROVIDER", "pix_stub")
+	if err := Configure(); err != nil || payoutProvider == nil {
+		t.Fatalf("Configure(pix_stub): error = %v, provider = %v", err, payoutProvider)
+	}
+}
@@ -3,8 +3,24 @@ package main
 import (
 	"berry_bet/api"
 	"berry_bet/config"
+	"berry_bet/internal/account_tokens"
+	"berry_bet/internal/bonus"
+	"berry_bet/internal/mailer"
+	"berry_bet/internal/payments"
+	"berry_bet/internal/ratelimit"
+	"berry_bet/internal/referrals"
+	"berry_bet/internal/reports"
+	"berry_bet/internal/responsible_gaming"
+	"berry_bet/internal/risk"
+	"berry_bet/internal/roles"
+	"berry_bet/internal/token"
+	"berry_bet/internal/tournaments"
 	"berry_bet/internal/utils"
+	"berry_bet/internal/withdrawals"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -17,17 +33,55 @@ func main() {
 		log.Println("Aviso: .env não encontrado ou não pôde ser carregado")
 	}
 
+	if err := token.Configure(); err != nil {
+		log.Fatalf("Configuração de JWT inválida: %v", err)
+	}
+	if err := account_tokens.CheckKey(); err != nil {
+		log.Fatal(err)
+	}
+	if err := payments.Configure(); err != nil {
+		log.Fatalf("Configuração de pagamentos inválida: %v", err)
+	}
+	if err := withdrawals.Configure(); err != nil {
+		log.Fatalf("Configuração de saques inválida: %v", err)
+	}
 	config.SetupDatabase()
+	roles.BootstrapAdmin(os.Getenv("BOOTSTRAP_ADMIN"))
+	tournaments.StartWorker(time.Minute)
+	responsible_gaming.StartWorker(time.Minute)
+	risk.StartWorker(time.Hour)
+	payments.StartWorker(time.Minute)
+	withdrawals.StartWorker(time.Minute)
+	bonus.StartWorker(time.Hour)
+	referrals.StartWorker(10 * time.Minute)
+	reports.StartWorker(time.Hour)
+	ratelimit.Configure(os.Getenv("RATE_LIMIT_STORE"))
+	ratelimit.SetAPIKeys(strings.Split(os.Getenv("RATE_LIMIT_API_KEYS"), ","))
+	mailer.Configure(os.Getenv("MAILER"))
 
 	r := gin.Default()
+	// X-Forwarded-For/X-Real-IP só valem vindos dos proxies em TRUSTED_PROXIES
+	// (IPs ou CIDRs separados por vírgula). Sem a variável, o IP do cliente é o
+	// da conexão, e os limites por IP não podem ser burlados pelo header.
+	var trustedProxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES inválido: %v", err)
+	}
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000", "*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "X-Request-ID", "X-API-Key"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 		AllowCredentials: true,
 	}))
+	r.Use(utils.RequestIDMiddleware())
 	r.Use(utils.ErrorHandlingMiddleware())
+	r.Use(ratelimit.Middleware("default", ratelimit.ByAPIKey))
 	api.RegisterRoutes(r)
 
 	r.Static("/uploads", "./uploads")
@@ -0,0 +1,49 @@
+-- Torneios com janela de tempo, pontuação configurável e tabela de prêmios
+
+CREATE TABLE IF NOT EXISTS tournaments (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    eligible_games TEXT NOT NULL DEFAULT 'roleta', -- game_type separados por vírgula
+    scoring TEXT NOT NULL DEFAULT 'total_profit' CHECK (scoring IN ('total_profit', 'biggest_multiplier', 'wager_volume')),
+    entry_fee REAL NOT NULL DEFAULT 0.0,
+    status TEXT NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'running', 'finished', 'cancelled')),
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_status_end ON tournaments(status, end_time);
+
+CREATE TABLE IF NOT EXISTS tournament_prizes (
+    id INTEGER PRIMARY KEY,
+    tournament_id INTEGER NOT NULL,
+    rank_from INTEGER NOT NULL,
+    rank_to INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    UNIQUE(tournament_id, rank_from)
+);
+
+-- Classificação ao vivo: uma linha por jogador inscrito
+CREATE TABLE IF NOT EXISTS tournament_entries (
+    id INTEGER PRIMARY KEY,
+    tournament_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    score REAL NOT NULL DEFAULT 0.0,
+    bets_count INTEGER NOT NULL DEFAULT 0,
+    total_wagered REAL NOT NULL DEFAULT 0.0,
+    total_profit REAL NOT NULL DEFAULT 0.0,
+    best_multiplier REAL NOT NULL DEFAULT 0.0,
+    final_rank INTEGER,
+    prize_amount REAL NOT NULL DEFAULT 0.0,
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    UNIQUE(tournament_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_entries_standings ON tournament_entries(tournament_id, score DESC, updated_at ASC);
+CREATE INDEX IF NOT EXISTS idx_tournament_entries_user ON tournament_entries(user_id);
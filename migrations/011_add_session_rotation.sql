@@ -0,0 +1,14 @@
+-- Sessões de refresh token com rotação.
+-- A coluna token passa a guardar o hash SHA-256 do refresh token; todas as
+-- sessões originadas do mesmo login compartilham o mesmo family_id.
+ALTER TABLE sessions ADD COLUMN family_id TEXT;
+ALTER TABLE sessions ADD COLUMN user_agent TEXT;
+ALTER TABLE sessions ADD COLUMN ip_address TEXT;
+ALTER TABLE sessions ADD COLUMN last_used_at TIMESTAMP;
+ALTER TABLE sessions ADD COLUMN revoked_at TIMESTAMP;
+ALTER TABLE sessions ADD COLUMN revoked_reason TEXT;
+ALTER TABLE sessions ADD COLUMN replaced_by INTEGER REFERENCES sessions(id);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_token ON sessions(token);
+CREATE INDEX IF NOT EXISTS idx_sessions_family ON sessions(family_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id);
@@ -0,0 +1,68 @@
+-- Controle de acesso baseado em papéis (RBAC).
+CREATE TABLE IF NOT EXISTS roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL,
+    permission_id INTEGER NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INTEGER NOT NULL,
+    role_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles(role_id);
+
+INSERT OR IGNORE INTO roles (name, description) VALUES
+    ('admin', 'Acesso total à administração da plataforma'),
+    ('operator', 'Operação de jogos, resultados e torneios; leitura dos demais recursos'),
+    ('player', 'Jogador; acesso apenas aos próprios recursos');
+
+INSERT OR IGNORE INTO permissions (name, description) VALUES
+    ('users.read', 'Listar e consultar usuários'),
+    ('users.write', 'Criar, editar e remover usuários'),
+    ('bets.read', 'Listar e consultar apostas'),
+    ('bets.write', 'Criar, editar e remover apostas'),
+    ('transactions.read', 'Listar e consultar transações'),
+    ('transactions.write', 'Criar, editar e remover transações'),
+    ('user_stats.read', 'Listar e consultar estatísticas e saldos'),
+    ('user_stats.write', 'Editar estatísticas e saldos'),
+    ('games.read', 'Listar e consultar jogos'),
+    ('games.write', 'Criar, editar e remover jogos'),
+    ('outcomes.read', 'Listar e consultar resultados'),
+    ('outcomes.write', 'Criar, editar e remover resultados'),
+    ('sessions.read', 'Listar e consultar sessões'),
+    ('sessions.write', 'Criar, editar e revogar sessões'),
+    ('tournaments.manage', 'Criar, editar e cancelar torneios'),
+    ('roles.manage', 'Atribuir e remover papéis de usuários');
+
+-- admin recebe todas as permissões
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p WHERE r.name = 'admin';
+
+-- operator: leitura geral e gestão de jogos, resultados e torneios
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'operator'
+  AND (p.name LIKE '%.read' OR p.name IN ('games.write', 'outcomes.write', 'tournaments.manage'));
+
+-- usuários existentes passam a ser jogadores
+INSERT OR IGNORE INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u, roles r WHERE r.name = 'player';
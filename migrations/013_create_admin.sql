@@ -0,0 +1,21 @@
+-- Back-office administrativo: congelamento de contas e trilha de auditoria.
+ALTER TABLE users ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'frozen'));
+ALTER TABLE users ADD COLUMN frozen_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN frozen_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id INTEGER,                 -- usuário que executou a ação (NULL para o sistema)
+    action TEXT NOT NULL,             -- ex.: user.freeze, balance.adjust, bet_limits.update
+    target_type TEXT NOT NULL,        -- ex.: user, game, bet_limits
+    target_id INTEGER,
+    details TEXT,                     -- JSON com os dados da ação
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (actor_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
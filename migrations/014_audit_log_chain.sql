@@ -0,0 +1,28 @@
+-- Trilha de auditoria imutável com encadeamento de hashes.
+-- Cada entrada guarda o hash da anterior (prev_hash) e o próprio hash, calculado
+-- sobre prev_hash e o conteúdo da entrada; qualquer alteração quebra a cadeia.
+ALTER TABLE audit_log ADD COLUMN before_json TEXT;
+ALTER TABLE audit_log ADD COLUMN after_json TEXT;
+ALTER TABLE audit_log ADD COLUMN ip_address TEXT;
+ALTER TABLE audit_log ADD COLUMN user_agent TEXT;
+ALTER TABLE audit_log ADD COLUMN request_id TEXT;
+ALTER TABLE audit_log ADD COLUMN prev_hash TEXT;
+ALTER TABLE audit_log ADD COLUMN hash TEXT;
+
+-- Impede bifurcações da cadeia: cada hash só pode ser sucedido por uma entrada.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log_prev_hash ON audit_log(prev_hash) WHERE prev_hash IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request_id ON audit_log(request_id);
+
+-- A tabela é append-only.
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE ON audit_log
+    BEGIN
+        SELECT RAISE(ABORT, 'audit_log is append-only');
+    END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE ON audit_log
+    BEGIN
+        SELECT RAISE(ABORT, 'audit_log is append-only');
+    END;
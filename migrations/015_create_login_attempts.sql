@@ -0,0 +1,16 @@
+-- Proteção contra força bruta no login: falhas contadas por conta e por IP.
+-- scope = 'account' usa a chave "user:<id>" (ou "name:<identificador>" para
+-- logins que não correspondem a nenhum usuário); scope = 'ip' usa o endereço.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    scope TEXT NOT NULL CHECK (scope IN ('account', 'ip')),
+    key TEXT NOT NULL,
+    user_id INTEGER,
+    failures INTEGER NOT NULL DEFAULT 0,   -- falhas desde o último sucesso ou bloqueio
+    lockouts INTEGER NOT NULL DEFAULT 0,   -- bloqueios recentes, usados para escalonar a duração
+    last_failure_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts(user_id);
@@ -0,0 +1,9 @@
+-- Baldes do rate limiter compartilhados entre processos (RATE_LIMIT_STORE=sqlite).
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,           -- "<política>:<identificador>"
+    tokens REAL NOT NULL,           -- fichas disponíveis no instante updated_at
+    updated_at REAL NOT NULL,       -- instante da última atualização (segundos Unix)
+    allowed INTEGER NOT NULL DEFAULT 1 -- se a última requisição consumiu uma ficha
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
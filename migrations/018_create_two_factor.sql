@@ -0,0 +1,32 @@
+-- Autenticação em dois fatores (TOTP, RFC 6238).
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INTEGER PRIMARY KEY,
+    secret TEXT NOT NULL,                       -- segredo base32 compartilhado com o app autenticador
+    enabled_at TIMESTAMP,                       -- NULL enquanto o cadastro não foi confirmado
+    last_used_step INTEGER NOT NULL DEFAULT 0,  -- último intervalo aceito, impede reuso do mesmo código
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Códigos de recuperação de uso único, guardados apenas como hash SHA-256.
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+-- Desafios emitidos pelo login quando a conta tem 2FA; trocados pela sessão em /login/2fa.
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    token_hash TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,18 @@
+-- Verificação de e-mail e redefinição de senha.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Tokens de uso único enviados por e-mail. Guarda-se apenas o hash do
+-- identificador; a assinatura HMAC do token é conferida antes da consulta.
+CREATE TABLE IF NOT EXISTS account_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    purpose TEXT NOT NULL CHECK (purpose IN ('email_verification', 'password_reset')),
+    token_hash TEXT NOT NULL UNIQUE,
+    email TEXT NOT NULL,              -- e-mail de destino; o token deixa de valer se o e-mail mudar
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user_purpose ON account_tokens(user_id, purpose);
@@ -0,0 +1,15 @@
+-- Limites de jogo responsável definidos pelo próprio jogador.
+-- Reduções valem na hora; aumentos (e remoções) ficam pendentes até pending_effective_at.
+CREATE TABLE IF NOT EXISTS player_limits (
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('deposit', 'loss', 'wager')),
+    period TEXT NOT NULL CHECK (period IN ('daily', 'weekly', 'monthly')),
+    amount REAL,                      -- limite vigente; NULL enquanto só existe um limite pendente
+    pending_amount REAL,              -- novo valor após o período de espera; NULL com pending_effective_at remove o limite
+    pending_effective_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, kind, period),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_type_created ON transactions(user_id, type, created_at);
@@ -0,0 +1,21 @@
+-- Pausas (time-outs) e autoexclusões escolhidas pelo jogador. Não podem ser
+-- encerradas antes do fim; só é possível prolongá-las com um novo registro.
+CREATE TABLE IF NOT EXISTS self_exclusions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('timeout', 'exclusion')),
+    duration TEXT NOT NULL,        -- opção escolhida: 24h, 7d, 30d, 6m, 1y, 5y ou permanent
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,             -- NULL para autoexclusão permanente
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_self_exclusions_user_id ON self_exclusions(user_id);
+CREATE INDEX IF NOT EXISTS idx_self_exclusions_ends_at ON self_exclusions(ends_at);
+
+CREATE TRIGGER IF NOT EXISTS self_exclusions_no_update
+BEFORE UPDATE ON self_exclusions
+BEGIN
+    SELECT RAISE(ABORT, 'self_exclusions is append-only');
+END;
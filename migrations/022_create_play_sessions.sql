@@ -0,0 +1,26 @@
+-- Sessões de jogo: começam na primeira aposta e terminam após um período sem apostas.
+CREATE TABLE IF NOT EXISTS play_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    last_activity_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,                              -- NULL enquanto a sessão está aberta
+    bets_count INTEGER NOT NULL DEFAULT 0,
+    wagered REAL NOT NULL DEFAULT 0,
+    net_result REAL NOT NULL DEFAULT 0,              -- ganhos menos apostas
+    reality_checks INTEGER NOT NULL DEFAULT 0,       -- lembretes exibidos na sessão
+    last_reality_check_at TIMESTAMP,
+    reality_check_pending INTEGER NOT NULL DEFAULT 0, -- 1 até o jogador confirmar o último lembrete
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_play_sessions_open ON play_sessions(user_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_play_sessions_user_started ON play_sessions(user_id, started_at);
+
+-- Intervalo do lembrete de tempo de jogo escolhido pelo jogador.
+CREATE TABLE IF NOT EXISTS reality_check_settings (
+    user_id INTEGER PRIMARY KEY,
+    interval_minutes INTEGER NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,29 @@
+-- Histórico da pontuação de risco de jogo problemático de cada jogador.
+CREATE TABLE IF NOT EXISTS risk_scores (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    score REAL NOT NULL,                 -- 0 a 100
+    tier TEXT NOT NULL CHECK (tier IN ('low', 'medium', 'high', 'severe')),
+    indicators TEXT NOT NULL,            -- JSON com os indicadores usados no cálculo
+    interventions TEXT NOT NULL DEFAULT '[]', -- JSON com as intervenções disparadas
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_scores_user_computed ON risk_scores(user_id, computed_at);
+
+-- Contas encaminhadas para análise manual.
+CREATE TABLE IF NOT EXISTS risk_reviews (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    score_id INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    resolution_notes TEXT,
+    resolved_by INTEGER,
+    opened_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (score_id) REFERENCES risk_scores(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_risk_reviews_open ON risk_reviews(user_id) WHERE status = 'open';
@@ -0,0 +1,36 @@
+-- Verificação de identidade (KYC): unverified -> pending -> verified/rejected.
+ALTER TABLE users ADD COLUMN kyc_status TEXT NOT NULL DEFAULT 'unverified'
+    CHECK (kyc_status IN ('unverified', 'pending', 'verified', 'rejected'));
+
+-- Cada envio para análise; o mais recente define o status do usuário.
+CREATE TABLE IF NOT EXISTS kyc_submissions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'rejected')),
+    submitted_at TIMESTAMP NOT NULL,
+    reviewed_by INTEGER,
+    reviewed_at TIMESTAMP,
+    rejection_reason TEXT,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_submissions_status ON kyc_submissions(status, submitted_at);
+CREATE INDEX IF NOT EXISTS idx_kyc_submissions_user_id ON kyc_submissions(user_id);
+
+-- Documentos enviados. Ficam fora de uploads/ (que é público) e só são
+-- servidos aos revisores. submission_id é NULL até o envio para análise.
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    submission_id INTEGER,
+    doc_type TEXT NOT NULL CHECK (doc_type IN ('id_front', 'id_back', 'selfie', 'proof_of_address')),
+    file_path TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (submission_id) REFERENCES kyc_submissions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_user_id ON kyc_documents(user_id);
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_submission_id ON kyc_documents(submission_id);
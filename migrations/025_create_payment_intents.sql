@@ -0,0 +1,36 @@
+-- Intenções de pagamento (depósitos). Só intenções confirmadas creditam a
+-- carteira, e a transição pending -> confirmed acontece uma única vez.
+CREATE TABLE IF NOT EXISTS payment_intents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    provider TEXT NOT NULL,
+    method TEXT NOT NULL,
+    amount REAL NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'failed', 'expired')),
+    provider_ref TEXT,
+    qr_code TEXT,
+    failure_reason TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_intents_provider_ref ON payment_intents(provider, provider_ref);
+CREATE INDEX IF NOT EXISTS idx_payment_intents_user_id ON payment_intents(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_payment_intents_pending ON payment_intents(status, expires_at);
+
+-- Notificações recebidas dos provedores. O par (provider, event_id) é único
+-- para que reenvios do mesmo evento não sejam processados de novo.
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    intent_id INTEGER,
+    status TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, event_id),
+    FOREIGN KEY (intent_id) REFERENCES payment_intents(id)
+);
@@ -0,0 +1,22 @@
+-- Pedidos de saque. O valor sai do saldo disponível (retido) no pedido e só
+-- volta em caso de rejeição ou falha no pagamento.
+CREATE TABLE IF NOT EXISTS withdrawals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    amount REAL NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL CHECK (status IN ('pending_approval', 'approved', 'processing', 'paid', 'rejected', 'failed')),
+    destination TEXT NOT NULL,
+    checks TEXT NOT NULL DEFAULT '[]',
+    provider TEXT,
+    provider_ref TEXT,
+    rejection_reason TEXT,
+    reviewed_by INTEGER,
+    reviewed_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user_id ON withdrawals(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_withdrawals_status ON withdrawals(status, created_at);
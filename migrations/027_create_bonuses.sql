@@ -0,0 +1,76 @@
+-- Campanhas de bônus: depósito em dobro (deposit_match), giros grátis na
+-- roleta (free_spins) e cashback semanal sobre perdas líquidas (cashback).
+CREATE TABLE IF NOT EXISTS bonus_campaigns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('deposit_match', 'free_spins', 'cashback')),
+    active INTEGER NOT NULL DEFAULT 1,
+    match_percent REAL NOT NULL DEFAULT 0,
+    max_bonus REAL NOT NULL DEFAULT 0,
+    min_deposit REAL NOT NULL DEFAULT 0,
+    free_spins INTEGER NOT NULL DEFAULT 0,
+    spin_value REAL NOT NULL DEFAULT 0,
+    cashback_percent REAL NOT NULL DEFAULT 0,
+    wagering_multiplier REAL NOT NULL DEFAULT 1 CHECK (wagering_multiplier >= 0),
+    valid_days INTEGER NOT NULL DEFAULT 30 CHECK (valid_days > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bônus concedidos. O saldo de bônus fica bloqueado até o rollover
+-- (wagered >= wagering_required) e então vira saldo real. reference evita
+-- conceder duas vezes a mesma campanha no mesmo período (ex.: semana do cashback).
+CREATE TABLE IF NOT EXISTS user_bonuses (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    campaign_id INTEGER NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'forfeited', 'expired')),
+    granted_amount REAL NOT NULL DEFAULT 0,
+    balance REAL NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    wagering_required REAL NOT NULL DEFAULT 0,
+    wagered REAL NOT NULL DEFAULT 0,
+    free_spins_remaining INTEGER NOT NULL DEFAULT 0 CHECK (free_spins_remaining >= 0),
+    spin_value REAL NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    UNIQUE (user_id, campaign_id, reference),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (campaign_id) REFERENCES bonus_campaigns(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_bonuses_active ON user_bonuses(user_id, status);
+
+-- Contribuição de cada aposta com dinheiro real para o rollover dos bônus.
+CREATE TABLE IF NOT EXISTS bonus_wagers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_bonus_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    game TEXT NOT NULL,
+    stake REAL NOT NULL,
+    weight REAL NOT NULL,
+    contribution REAL NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_bonus_id) REFERENCES user_bonuses(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_bonus_wagers_user_bonus_id ON bonus_wagers(user_bonus_id);
+
+-- Peso de cada jogo no rollover (1 = a aposta conta integralmente).
+CREATE TABLE IF NOT EXISTS bonus_game_weights (
+    game TEXT PRIMARY KEY,
+    weight REAL NOT NULL CHECK (weight >= 0 AND weight <= 1)
+);
+
+INSERT OR IGNORE INTO bonus_game_weights (game, weight) VALUES
+    ('roleta', 1.0),
+    ('sports', 0.5);
+
+INSERT OR IGNORE INTO bonus_campaigns (code, name, kind, match_percent, max_bonus, min_deposit, wagering_multiplier, valid_days) VALUES
+    ('BOASVINDAS', 'Primeiro depósito em dobro', 'deposit_match', 100, 500, 20, 20, 30);
+INSERT OR IGNORE INTO bonus_campaigns (code, name, kind, free_spins, spin_value, wagering_multiplier, valid_days) VALUES
+    ('GIROS10', '10 giros grátis na roleta', 'free_spins', 10, 1, 10, 7);
+INSERT OR IGNORE INTO bonus_campaigns (code, name, kind, cashback_percent, wagering_multiplier, valid_days) VALUES
+    ('CASHBACK10', 'Cashback semanal de 10%', 'cashback', 10, 1, 7);
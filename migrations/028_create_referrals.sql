@@ -0,0 +1,65 @@
+-- Programa de indicação. Cada jogador tem um código de convite; o convidado
+-- informa o código no cadastro e o padrinho ganha um bônus quando o convidado
+-- cumpre a regra da campanha de indicação (depósito mínimo e N apostas).
+ALTER TABLE users ADD COLUMN referral_code TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_referral_code ON users(referral_code);
+
+-- Identificador do aparelho enviado pelo app (header X-Device-ID), usado na
+-- detecção de auto-indicação junto com o IP.
+ALTER TABLE sessions ADD COLUMN device_id TEXT;
+
+CREATE TABLE IF NOT EXISTS referrals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    referrer_id INTEGER NOT NULL,
+    referee_id INTEGER NOT NULL UNIQUE,
+    code TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'qualified', 'rejected')),
+    rejection_reason TEXT,
+    signup_ip TEXT,
+    signup_device_id TEXT,
+    user_bonus_id INTEGER,
+    reward_amount REAL NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    qualified_at TIMESTAMP,
+    FOREIGN KEY (referrer_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (referee_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_bonus_id) REFERENCES user_bonuses(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals(referrer_id);
+CREATE INDEX IF NOT EXISTS idx_referrals_status ON referrals(status);
+
+-- bonus_campaigns ganha o tipo referral e a coluna min_bets. O SQLite não
+-- altera CHECK, então a tabela é recriada.
+CREATE TABLE bonus_campaigns_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('deposit_match', 'free_spins', 'cashback', 'referral')),
+    active INTEGER NOT NULL DEFAULT 1,
+    match_percent REAL NOT NULL DEFAULT 0,
+    max_bonus REAL NOT NULL DEFAULT 0,
+    min_deposit REAL NOT NULL DEFAULT 0,
+    min_bets INTEGER NOT NULL DEFAULT 0,
+    free_spins INTEGER NOT NULL DEFAULT 0,
+    spin_value REAL NOT NULL DEFAULT 0,
+    cashback_percent REAL NOT NULL DEFAULT 0,
+    wagering_multiplier REAL NOT NULL DEFAULT 1 CHECK (wagering_multiplier >= 0),
+    valid_days INTEGER NOT NULL DEFAULT 30 CHECK (valid_days > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO bonus_campaigns_new (id, code, name, kind, active, match_percent, max_bonus, min_deposit, free_spins,
+    spin_value, cashback_percent, wagering_multiplier, valid_days, created_at, updated_at)
+SELECT id, code, name, kind, active, match_percent, max_bonus, min_deposit, free_spins,
+    spin_value, cashback_percent, wagering_multiplier, valid_days, created_at, updated_at
+FROM bonus_campaigns;
+
+DROP TABLE bonus_campaigns;
+ALTER TABLE bonus_campaigns_new RENAME TO bonus_campaigns;
+
+-- Indicação: R$ 25 de bônus (max_bonus) quando o convidado deposita ao menos
+-- R$ 20 e faz 5 apostas.
+INSERT OR IGNORE INTO bonus_campaigns (code, name, kind, max_bonus, min_deposit, min_bets, wagering_multiplier, valid_days) VALUES
+    ('INDIQUE', 'Indique um amigo', 'referral', 25, 20, 5, 5, 30);
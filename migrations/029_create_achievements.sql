@@ -0,0 +1,73 @@
+-- Conquistas (badges). Cada regra é declarativa: rule diz o que medir,
+-- threshold o mínimo a atingir, game_type restringe a um jogo (NULL = todos)
+-- e card, para a regra "card", a cartinha que precisa sair.
+CREATE TABLE IF NOT EXISTS achievements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    rule TEXT NOT NULL CHECK (rule IN ('win_streak', 'consecutive_days', 'card', 'total_bets', 'total_wins', 'biggest_win')),
+    game_type TEXT,
+    threshold REAL NOT NULL DEFAULT 1,
+    card TEXT,
+    reward_campaign_id INTEGER,
+    active INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (reward_campaign_id) REFERENCES bonus_campaigns(id)
+);
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    achievement_id INTEGER NOT NULL,
+    user_bonus_id INTEGER,
+    unlocked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, achievement_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (achievement_id) REFERENCES achievements(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_bonus_id) REFERENCES user_bonuses(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_achievements_user ON user_achievements(user_id);
+
+-- bonus_campaigns deixa de limitar kind com CHECK; os tipos passam a ser
+-- validados pela aplicação (bonus.ValidateCampaign), para que novos tipos
+-- como achievement não exijam recriar a tabela de novo.
+CREATE TABLE bonus_campaigns_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    active INTEGER NOT NULL DEFAULT 1,
+    match_percent REAL NOT NULL DEFAULT 0,
+    max_bonus REAL NOT NULL DEFAULT 0,
+    min_deposit REAL NOT NULL DEFAULT 0,
+    min_bets INTEGER NOT NULL DEFAULT 0,
+    free_spins INTEGER NOT NULL DEFAULT 0,
+    spin_value REAL NOT NULL DEFAULT 0,
+    cashback_percent REAL NOT NULL DEFAULT 0,
+    wagering_multiplier REAL NOT NULL DEFAULT 1 CHECK (wagering_multiplier >= 0),
+    valid_days INTEGER NOT NULL DEFAULT 30 CHECK (valid_days > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO bonus_campaigns_new SELECT * FROM bonus_campaigns;
+DROP TABLE bonus_campaigns;
+ALTER TABLE bonus_campaigns_new RENAME TO bonus_campaigns;
+
+-- Prêmio fixo (max_bonus) concedido por conquistas. Inativa: não aparece
+-- na lista de campanhas do jogador, mas continua valendo como prêmio.
+INSERT OR IGNORE INTO bonus_campaigns (code, name, kind, active, max_bonus, wagering_multiplier, valid_days) VALUES
+    ('CONQUISTA10', 'Prêmio de conquista', 'achievement', 0, 10, 5, 30);
+
+INSERT OR IGNORE INTO achievements (code, name, description, rule, game_type, threshold, card, reward_campaign_id) VALUES
+    ('FIRST_BET', 'Primeira aposta', 'Faça sua primeira aposta.', 'total_bets', NULL, 1, NULL, NULL),
+    ('CENTURION', 'Centurião', 'Faça 100 apostas.', 'total_bets', NULL, 100, NULL, NULL),
+    ('STREAK_10', 'Imparável', 'Vença 10 apostas seguidas no mesmo jogo.', 'win_streak', NULL, 10, NULL,
+        (SELECT id FROM bonus_campaigns WHERE code = 'CONQUISTA10')),
+    ('WEEK_STREAK', 'Frequentador', 'Jogue 7 dias seguidos.', 'consecutive_days', NULL, 7, NULL, NULL),
+    ('MASTER_CARD', 'Carta mestra', 'Tire a cartinha master na roleta.', 'card', 'roleta', 1, 'master',
+        (SELECT id FROM bonus_campaigns WHERE code = 'GIROS10')),
+    ('BIG_WIN', 'Bolada', 'Lucre R$ 500 ou mais em uma única aposta.', 'biggest_win', NULL, 500, NULL, NULL);
@@ -0,0 +1,3 @@
+-- Fuso horário do jogador, usado para agrupar as análises do dashboard por
+-- dia/semana/mês no horário local dele (as datas no banco ficam em UTC).
+ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT 'America/Sao_Paulo';
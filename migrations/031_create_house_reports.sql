@@ -0,0 +1,43 @@
+-- Relatórios da casa. Um job diário consolida o ledger (transactions) e as
+-- apostas esportivas nestas tabelas, por dia no fuso dos relatórios, para que
+-- as consultas de GGR/NGR/RTP não precisem varrer o histórico inteiro.
+
+-- Apostas por dia e jogo: volume apostado, pagamentos e jogadores distintos.
+CREATE TABLE IF NOT EXISTS house_daily_games (
+    date TEXT NOT NULL,
+    game TEXT NOT NULL,
+    bets_count INTEGER NOT NULL DEFAULT 0,
+    stakes REAL NOT NULL DEFAULT 0,
+    payouts REAL NOT NULL DEFAULT 0,
+    players INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (date, game)
+);
+
+-- Jogadores ativos por dia e jogo, para contar jogadores distintos em
+-- qualquer intervalo sem voltar às transações.
+CREATE TABLE IF NOT EXISTS house_daily_players (
+    date TEXT NOT NULL,
+    game TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    PRIMARY KEY (date, game, user_id)
+);
+
+-- Movimentação de caixa e custo de bônus por dia (valem para a casa toda).
+CREATE TABLE IF NOT EXISTS house_daily_cashflow (
+    date TEXT PRIMARY KEY,
+    deposits REAL NOT NULL DEFAULT 0,
+    deposits_count INTEGER NOT NULL DEFAULT 0,
+    withdrawals REAL NOT NULL DEFAULT 0,
+    withdrawals_count INTEGER NOT NULL DEFAULT 0,
+    withdrawal_refunds REAL NOT NULL DEFAULT 0,
+    bonus_cost REAL NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- RTP teórico de cada jogo em porcentagem (ex.: 95.5), informado pelos administradores.
+CREATE TABLE IF NOT EXISTS game_theoretical_rtp (
+    game TEXT PRIMARY KEY,
+    rtp REAL NOT NULL CHECK (rtp > 0 AND rtp <= 200),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
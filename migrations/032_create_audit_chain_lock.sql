@@ -0,0 +1,10 @@
+-- Linha única gravada no início de cada escrita da trilha de auditoria. A
+-- gravação toma o lock de escrita do SQLite, mantido até o commit da transação,
+-- então o último hash lido para o encadeamento não muda até a entrada nova ser
+-- confirmada, mesmo com vários processos.
+CREATE TABLE IF NOT EXISTS audit_chain_lock (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO audit_chain_lock (id) VALUES (1);
@@ -0,0 +1,5 @@
+-- Códigos 2FA inválidos seguidos nas ações sensíveis (header X-TOTP-Code,
+-- desativar o 2FA, novos códigos de recuperação). Ao atingir o limite, os
+-- códigos do usuário ficam recusados até code_locked_until.
+ALTER TABLE user_totp ADD COLUMN failed_code_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_totp ADD COLUMN code_locked_until TIMESTAMP;
@@ -0,0 +1,7 @@
+-- Pagamentos confirmados que não são creditados (recusados pelas regras de
+-- jogo responsável ou recebidos depois de a intenção expirar) são devolvidos
+-- pelo provedor. refund_status fica 'pending' até a devolução dar certo.
+ALTER TABLE payment_intents ADD COLUMN refund_status TEXT CHECK (refund_status IN ('pending', 'refunded'));
+ALTER TABLE payment_intents ADD COLUMN refunded_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_payment_intents_refund ON payment_intents(refund_status);
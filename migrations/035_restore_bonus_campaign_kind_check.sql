@@ -0,0 +1,30 @@
+-- A 029 recriou bonus_campaigns sem o CHECK em kind. O CHECK volta, agora com
+-- achievement, como a 028 fez para referral. O SQLite não altera CHECK, então
+-- a tabela é recriada, copiando as colunas pelo nome.
+CREATE TABLE bonus_campaigns_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('deposit_match', 'free_spins', 'cashback', 'referral', 'achievement')),
+    active INTEGER NOT NULL DEFAULT 1,
+    match_percent REAL NOT NULL DEFAULT 0,
+    max_bonus REAL NOT NULL DEFAULT 0,
+    min_deposit REAL NOT NULL DEFAULT 0,
+    min_bets INTEGER NOT NULL DEFAULT 0,
+    free_spins INTEGER NOT NULL DEFAULT 0,
+    spin_value REAL NOT NULL DEFAULT 0,
+    cashback_percent REAL NOT NULL DEFAULT 0,
+    wagering_multiplier REAL NOT NULL DEFAULT 1 CHECK (wagering_multiplier >= 0),
+    valid_days INTEGER NOT NULL DEFAULT 30 CHECK (valid_days > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO bonus_campaigns_new (id, code, name, kind, active, match_percent, max_bonus, min_deposit, min_bets,
+    free_spins, spin_value, cashback_percent, wagering_multiplier, valid_days, created_at, updated_at)
+SELECT id, code, name, kind, active, match_percent, max_bonus, min_deposit, min_bets,
+    free_spins, spin_value, cashback_percent, wagering_multiplier, valid_days, created_at, updated_at
+FROM bonus_campaigns;
+
+DROP TABLE bonus_campaigns;
+ALTER TABLE bonus_campaigns_new RENAME TO bonus_campaigns;
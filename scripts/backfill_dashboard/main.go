@@ -0,0 +1,20 @@
+package main
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/dashboard"
+	"log"
+)
+
+// Reconstrói bet_history, game_stats e daily_metrics a partir das transações
+// da roleta e das apostas esportivas liquidadas.
+// Uso (na raiz do projeto): go run ./scripts/backfill_dashboard
+func main() {
+	config.SetupDatabase()
+
+	res, err := dashboard.NewService(config.DB).Backfill()
+	if err != nil {
+		log.Fatalf("Erro ao reconstruir o dashboard: %v", err)
+	}
+	log.Printf("Dashboard reconstruído: %d rodadas de roleta e %d apostas esportivas", res.RoletaBets, res.SportsBets)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"berry_bet/config"
+	"berry_bet/internal/audit"
+	"log"
+	"os"
+)
+
+// Verifica a cadeia de hashes da trilha de auditoria.
+// Uso (na raiz do projeto): go run ./scripts/verify_audit_log
+func main() {
+	config.SetupDatabase()
+
+	res, err := audit.Verify()
+	if err != nil {
+		log.Fatalf("Erro ao verificar a trilha de auditoria: %v", err)
+	}
+	if !res.Valid {
+		log.Printf("Cadeia de auditoria INVÁLIDA na entrada %d: %s (%d entradas verificadas)", res.BrokenAtID, res.Reason, res.Checked)
+		os.Exit(1)
+	}
+	log.Printf("Cadeia de auditoria íntegra: %d entradas verificadas, %d entradas legadas sem hash", res.Checked, res.LegacyEntries)
+}